@@ -0,0 +1,51 @@
+// Package cache provides pluggable key/value backends with TTL and size
+// limits for caching generated responses.
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the key is missing or expired
+var ErrNotFound = errors.New("cache: not found")
+
+// Backend stores opaque values under string keys. Implementations must be
+// safe for concurrent use and must treat expired entries as missing.
+type Backend interface {
+	Get(key string) (*Entry, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// Entry is a cached value with its bookkeeping timestamps
+type Entry struct {
+	Value     []byte    `json:"value"`
+	StoredAt  time.Time `json:"storedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// expired reports whether the entry has a TTL that has passed at now
+func (e *Entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Limits bounds the size of a backend. Zero values mean unlimited.
+type Limits struct {
+	MaxEntries int
+	MaxBytes   int64
+}
+
+// exceeded reports whether entries/bytes are over the limits
+func (l Limits) exceeded(entries int, bytes int64) bool {
+	return (l.MaxEntries > 0 && entries > l.MaxEntries) || (l.MaxBytes > 0 && bytes > l.MaxBytes)
+}
+
+// newEntry creates an entry stored at now that expires after ttl (never when ttl <= 0)
+func newEntry(value []byte, ttl time.Duration, now time.Time) *Entry {
+	entry := &Entry{Value: value, StoredAt: now}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+	return entry
+}
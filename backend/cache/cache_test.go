@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a controllable time source for TTL tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBackends(t *testing.T, limits Limits) map[string]Backend {
+	t.Helper()
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	memory := NewMemoryBackend(limits)
+	memory.now = clock.Now
+
+	file, err := NewFileBackend(t.TempDir(), limits)
+	require.NoError(t, err)
+	file.now = clock.Now
+
+	return map[string]Backend{"memory": memory, "file": file}
+}
+
+func TestBackend_GetSetDelete(t *testing.T) {
+	for name, backend := range newTestBackends(t, Limits{}) {
+		t.Run(name, func(t *testing.T) {
+			_, err := backend.Get("missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, backend.Set("key", []byte("value"), time.Minute))
+			entry, err := backend.Get("key")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("value"), entry.Value)
+			assert.False(t, entry.StoredAt.IsZero())
+
+			require.NoError(t, backend.Delete("key"))
+			_, err = backend.Get("key")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestBackend_TTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	memory := NewMemoryBackend(Limits{})
+	memory.now = clock.Now
+	file, err := NewFileBackend(t.TempDir(), Limits{})
+	require.NoError(t, err)
+	file.now = clock.Now
+
+	for name, backend := range map[string]Backend{"memory": memory, "file": file} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, backend.Set("short", []byte("a"), time.Minute))
+			require.NoError(t, backend.Set("forever", []byte("b"), 0))
+
+			clock.Advance(2 * time.Minute)
+			defer clock.Advance(-2 * time.Minute)
+
+			_, err := backend.Get("short")
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = backend.Get("forever")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemoryBackend_LRUEviction(t *testing.T) {
+	backend := NewMemoryBackend(Limits{MaxEntries: 2})
+
+	require.NoError(t, backend.Set("a", []byte("1"), 0))
+	require.NoError(t, backend.Set("b", []byte("2"), 0))
+
+	// Touch "a" so "b" becomes the least recently used entry
+	_, err := backend.Get("a")
+	require.NoError(t, err)
+	require.NoError(t, backend.Set("c", []byte("3"), 0))
+
+	assert.Equal(t, 2, backend.Len())
+	_, err = backend.Get("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = backend.Get("a")
+	assert.NoError(t, err)
+	_, err = backend.Get("c")
+	assert.NoError(t, err)
+}
+
+func TestMemoryBackend_ByteLimit(t *testing.T) {
+	backend := NewMemoryBackend(Limits{MaxBytes: 10})
+
+	require.NoError(t, backend.Set("a", []byte("12345"), 0))
+	require.NoError(t, backend.Set("b", []byte("12345"), 0))
+	require.NoError(t, backend.Set("c", []byte("12345"), 0))
+
+	assert.Equal(t, 2, backend.Len())
+	_, err := backend.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// A value that can never fit is not stored and evicts nothing
+	require.NoError(t, backend.Set("huge", []byte("12345678901"), 0))
+	assert.Equal(t, 2, backend.Len())
+}
+
+func TestFileBackend_LRUEviction(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	backend, err := NewFileBackend(t.TempDir(), Limits{MaxEntries: 2})
+	require.NoError(t, err)
+	backend.now = clock.Now
+
+	require.NoError(t, backend.Set("a", []byte("1"), 0))
+	clock.Advance(time.Second)
+	require.NoError(t, backend.Set("b", []byte("2"), 0))
+	clock.Advance(time.Second)
+	_, err = backend.Get("a")
+	require.NoError(t, err)
+	clock.Advance(time.Second)
+	require.NoError(t, backend.Set("c", []byte("3"), 0))
+
+	_, err = backend.Get("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = backend.Get("a")
+	assert.NoError(t, err)
+	_, err = backend.Get("c")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileBackend stores each entry as a JSON file in a directory so the cache
+// survives restarts. Recency is tracked through file modification times,
+// which are refreshed on every hit, and used for LRU eviction.
+type FileBackend struct {
+	dir    string
+	limits Limits
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+// fileEntry is the on-disk representation of an entry
+type fileEntry struct {
+	Key string `json:"key"`
+	Entry
+}
+
+// NewFileBackend creates a file backend rooted at dir, creating it if needed
+func NewFileBackend(dir string, limits Limits) (*FileBackend, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileBackend{dir: dir, limits: limits, now: time.Now}, nil
+}
+
+// Get reads the entry for key and refreshes its recency
+func (b *FileBackend) Get(key string) (*Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := b.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var stored fileEntry
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Key != key {
+		_ = os.Remove(path)
+		return nil, ErrNotFound
+	}
+
+	now := b.now()
+	if stored.expired(now) {
+		_ = os.Remove(path)
+		return nil, ErrNotFound
+	}
+
+	_ = os.Chtimes(path, now, now)
+	return &stored.Entry, nil
+}
+
+// Set writes value under key and evicts the least recently used files when
+// the directory exceeds its limits
+func (b *FileBackend) Set(key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	data, err := json.Marshal(fileEntry{Key: key, Entry: *newEntry(value, ttl, now)})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	path := b.path(key)
+	tmp, err := os.CreateTemp(b.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	_ = os.Chtimes(path, now, now)
+
+	return b.evict()
+}
+
+// Delete removes the file for key if present
+func (b *FileBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.Remove(b.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// evict removes the oldest files until the directory is within its limits
+func (b *FileBackend) evict() error {
+	if b.limits.MaxEntries <= 0 && b.limits.MaxBytes <= 0 {
+		return nil
+	}
+
+	dirEntries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list cache directory: %w", err)
+	}
+
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cachedFile
+	var total int64
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cachedFile{
+			path:    filepath.Join(b.dir, dirEntry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for len(files) > 0 && b.limits.exceeded(len(files), total) {
+		if err := os.Remove(files[0].path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+		total -= files[0].size
+		files = files[1:]
+	}
+	return nil
+}
+
+// path maps a key to a file name that is safe on every filesystem
+func (b *FileBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(b.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+var _ Backend = (*FileBackend)(nil)
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-process LRU cache. The least recently used entries
+// are evicted once the entry or byte limit is exceeded.
+type MemoryBackend struct {
+	limits Limits
+	now    func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	bytes int64
+}
+
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryBackend creates an in-memory LRU backend with the given limits
+func NewMemoryBackend(limits Limits) *MemoryBackend {
+	return &MemoryBackend{
+		limits: limits,
+		now:    time.Now,
+		order:  list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for key and marks it as recently used
+func (b *MemoryBackend) Get(key string) (*Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	element, ok := b.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	item := element.Value.(*memoryItem)
+	if item.entry.expired(b.now()) {
+		b.remove(element)
+		return nil, ErrNotFound
+	}
+
+	b.order.MoveToFront(element)
+	return item.entry, nil
+}
+
+// Set stores value under key, evicting least recently used entries as needed.
+// A value larger than MaxBytes on its own is not stored.
+func (b *MemoryBackend) Set(key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if element, ok := b.items[key]; ok {
+		b.remove(element)
+	}
+	if b.limits.MaxBytes > 0 && int64(len(value)) > b.limits.MaxBytes {
+		return nil
+	}
+
+	item := &memoryItem{key: key, entry: newEntry(value, ttl, b.now())}
+	b.items[key] = b.order.PushFront(item)
+	b.bytes += int64(len(value))
+
+	for b.limits.exceeded(len(b.items), b.bytes) {
+		b.remove(b.order.Back())
+	}
+	return nil
+}
+
+// Delete removes key if present
+func (b *MemoryBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if element, ok := b.items[key]; ok {
+		b.remove(element)
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet evicted
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+func (b *MemoryBackend) remove(element *list.Element) {
+	item := element.Value.(*memoryItem)
+	b.order.Remove(element)
+	delete(b.items, item.key)
+	b.bytes -= int64(len(item.entry.Value))
+}
+
+var _ Backend = (*MemoryBackend)(nil)
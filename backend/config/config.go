@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,88 @@ type Config struct {
 	OpenAIAPIKey string
 	Port         string
 	Environment  string
+
+	// Course generator selection: "openai", "local" or "fake"
+	LLMProvider string
+	LLMBaseURL  string
+	LLMModel    string
+
+	// Record/replay of OpenAI calls: "off", "record" or "replay"
+	OpenAICassetteMode string
+	OpenAICassetteDir  string
+
+	// Number of retries for rate limited, timed out or 5xx OpenAI calls
+	OpenAIMaxRetries int
+
+	// Response cache: "memory", "file" or "off"
+	CacheBackend          string
+	CacheDir              string
+	CacheTTL              time.Duration
+	CacheMaxEntries       int
+	CacheMaxBytes         int
+	CacheGeohashPrecision int
+
+	// Store of generated course details for exports: "memory", "file" or "off"
+	CourseStore           string
+	CourseStoreDir        string
+	CourseStoreTTL        time.Duration
+	CourseStoreMaxEntries int
+
+	// Prompt templates directory (embedded templates when empty) and
+	// whether to reload it on changes
+	PromptsDir       string
+	PromptsHotReload bool
+
+	// Usage accounting: per-model prices ("model=input/output" USD per
+	// million tokens), daily budget in USD (0 for none) and the admin token
+	// protecting /api/v1/admin (admin endpoints are disabled without it)
+	LLMPrices      string
+	DailyBudgetUSD float64
+	AdminToken     string
+
+	// Start point validation of suggestions: "drop", "reprompt" or "off"
+	StartPointValidation  string
+	StartPointMaxRadiusKm float64
+	// GeoJSON land polygons (coarse embedded outlines of Japan when empty)
+	LandGeoJSON     string
+	LandToleranceKm float64
+	// GeoJSON prefecture boundaries, e.g. N03 administrative areas (coarse
+	// embedded boundaries when empty), and the property naming the prefecture
+	PrefecturesGeoJSON      string
+	PrefecturesNameProperty string
+	// GeoJSON municipal and neighbourhood boundaries naming the city and
+	// neighbourhood in prompts, and the properties read from them, e.g.
+	// "city=N03_004,ward=N03_005" (N03 and e-Stat properties when empty)
+	MunicipalitiesGeoJSON string
+	MunicipalitiesFields  string
+	NeighbourhoodsGeoJSON string
+	NeighbourhoodsFields  string
+	// POI files (CSV, GeoJSON, Overpass JSON or OSM XML) whose places near a
+	// location are listed in prompts, how far to look and how many to list
+	POIFiles    []string
+	POIRadiusKm float64
+	POILimit    int
+	// Snap course waypoints to the POIs within SnapMaxDistanceKm whose name
+	// matches with at least SnapMinConfidence
+	WaypointSnapping  bool
+	SnapMaxDistanceKm float64
+	SnapMinConfidence float64
+
+	// Routing engine for course geometry: "osrm", "valhalla" or "off"
+	RoutingEngine  string
+	RoutingURL     string
+	RoutingTimeout time.Duration
+
+	// Elevation profiles from local DEM data: "hgt" (SRTM), "gsi" or "off"
+	ElevationSource     string
+	ElevationDir        string
+	ElevationGSIZoom    int
+	ElevationIntervalKm float64
+
+	// Pace model overrides: "type=speed/climb" (km/h and minutes per 100m
+	// of climb) and "difficulty=factor" pairs
+	PaceParams     string
+	PaceDifficulty string
 }
 
 func Load() *Config {
@@ -29,11 +114,136 @@ func Load() *Config {
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
 		Port:         getEnv("PORT", "8080"),
 		Environment:  getEnv("NODE_ENV", "development"),
+		LLMProvider:  getEnv("LLM_PROVIDER", "openai"),
+		LLMBaseURL:   getEnv("LLM_BASE_URL", ""),
+		LLMModel:     getEnv("LLM_MODEL", ""),
+
+		OpenAICassetteMode: getEnv("OPENAI_CASSETTE_MODE", "off"),
+		OpenAICassetteDir:  getEnv("OPENAI_CASSETTE_DIR", "testdata/cassettes"),
+		OpenAIMaxRetries:   getEnvInt("OPENAI_MAX_RETRIES", 2),
+
+		CacheBackend:          getEnv("CACHE_BACKEND", "memory"),
+		CacheDir:              getEnv("CACHE_DIR", "data/cache"),
+		CacheTTL:              getEnvDuration("CACHE_TTL", 24*time.Hour),
+		CacheMaxEntries:       getEnvInt("CACHE_MAX_ENTRIES", 1000),
+		CacheMaxBytes:         getEnvInt("CACHE_MAX_BYTES", 64<<20),
+		CacheGeohashPrecision: getEnvInt("CACHE_GEOHASH_PRECISION", 6),
+
+		CourseStore:           getEnv("COURSE_STORE", "memory"),
+		CourseStoreDir:        getEnv("COURSE_STORE_DIR", "data/courses"),
+		CourseStoreTTL:        getEnvDuration("COURSE_STORE_TTL", 7*24*time.Hour),
+		CourseStoreMaxEntries: getEnvInt("COURSE_STORE_MAX_ENTRIES", 10000),
+
+		PromptsDir: getEnv("PROMPTS_DIR", ""),
+
+		LLMPrices:      getEnv("LLM_PRICES", ""),
+		DailyBudgetUSD: getEnvFloat("DAILY_BUDGET_USD", 0),
+		AdminToken:     getEnv("ADMIN_TOKEN", ""),
+
+		StartPointValidation:  getEnv("START_POINT_VALIDATION", "drop"),
+		StartPointMaxRadiusKm: getEnvFloat("START_POINT_MAX_RADIUS_KM", 5),
+		LandGeoJSON:           getEnv("LAND_GEOJSON", ""),
+		LandToleranceKm:       getEnvFloat("LAND_TOLERANCE_KM", 3),
+
+		PrefecturesGeoJSON:      getEnv("PREFECTURES_GEOJSON", ""),
+		PrefecturesNameProperty: getEnv("PREFECTURES_NAME_PROPERTY", "N03_001"),
+		MunicipalitiesGeoJSON:   getEnv("MUNICIPALITIES_GEOJSON", ""),
+		MunicipalitiesFields:    getEnv("MUNICIPALITIES_FIELDS", ""),
+		NeighbourhoodsGeoJSON:   getEnv("NEIGHBOURHOODS_GEOJSON", ""),
+		NeighbourhoodsFields:    getEnv("NEIGHBOURHOODS_FIELDS", ""),
+
+		POIFiles:    getEnvList("POI_FILES"),
+		POIRadiusKm: getEnvFloat("POI_RADIUS_KM", 3),
+		POILimit:    getEnvInt("POI_LIMIT", 20),
+
+		WaypointSnapping:  getEnvBool("WAYPOINT_SNAPPING", true),
+		SnapMaxDistanceKm: getEnvFloat("SNAP_MAX_DISTANCE_M", 1000) / 1000,
+		SnapMinConfidence: getEnvFloat("SNAP_MIN_CONFIDENCE", 0.6),
+
+		RoutingEngine:  getEnv("ROUTING_ENGINE", "off"),
+		RoutingURL:     getEnv("ROUTING_URL", ""),
+		RoutingTimeout: getEnvDuration("ROUTING_TIMEOUT", 10*time.Second),
+
+		ElevationSource:     getEnv("ELEVATION_SOURCE", "off"),
+		ElevationDir:        getEnv("ELEVATION_DIR", "data/dem"),
+		ElevationGSIZoom:    getEnvInt("ELEVATION_GSI_ZOOM", 14),
+		ElevationIntervalKm: getEnvFloat("ELEVATION_INTERVAL_M", 100) / 1000,
+
+		PaceParams:     getEnv("PACE_PARAMS", ""),
+		PaceDifficulty: getEnv("PACE_DIFFICULTY", ""),
 	}
+	config.PromptsHotReload = getEnvBool("PROMPTS_HOT_RELOAD", config.Environment == "development")
 
 	// Validate required environment variables
-	if config.OpenAIAPIKey == "" {
-		log.Fatal("OPENAI_API_KEY environment variable is required")
+	switch config.LLMProvider {
+	case "openai":
+		// Replaying recorded responses does not need a real key
+		if config.OpenAIAPIKey == "" && config.OpenAICassetteMode != "replay" {
+			log.Fatal("OPENAI_API_KEY environment variable is required")
+		}
+	case "local":
+		if config.LLMBaseURL == "" || config.LLMModel == "" {
+			log.Fatal("LLM_BASE_URL and LLM_MODEL environment variables are required for the local provider")
+		}
+	case "fake":
+	default:
+		log.Fatalf("Unknown LLM_PROVIDER: %s (expected openai, local or fake)", config.LLMProvider)
+	}
+
+	switch config.OpenAICassetteMode {
+	case "off", "record", "replay":
+	default:
+		log.Fatalf("Unknown OPENAI_CASSETTE_MODE: %s (expected off, record or replay)", config.OpenAICassetteMode)
+	}
+
+	switch config.CacheBackend {
+	case "memory", "file", "off":
+	default:
+		log.Fatalf("Unknown CACHE_BACKEND: %s (expected memory, file or off)", config.CacheBackend)
+	}
+	switch config.CourseStore {
+	case "memory", "file", "off":
+	default:
+		log.Fatalf("Unknown COURSE_STORE: %s (expected memory, file or off)", config.CourseStore)
+	}
+	switch config.StartPointValidation {
+	case "drop", "reprompt", "off":
+	default:
+		log.Fatalf("Unknown START_POINT_VALIDATION: %s (expected drop, reprompt or off)", config.StartPointValidation)
+	}
+
+	switch config.RoutingEngine {
+	case "osrm", "valhalla":
+		if config.RoutingURL == "" {
+			log.Fatal("ROUTING_URL environment variable is required when ROUTING_ENGINE is set")
+		}
+	case "off":
+	default:
+		log.Fatalf("Unknown ROUTING_ENGINE: %s (expected osrm, valhalla or off)", config.RoutingEngine)
+	}
+
+	switch config.ElevationSource {
+	case "hgt", "gsi", "off":
+	default:
+		log.Fatalf("Unknown ELEVATION_SOURCE: %s (expected hgt, gsi or off)", config.ElevationSource)
+	}
+	if config.ElevationIntervalKm <= 0 {
+		log.Fatalf("ELEVATION_INTERVAL_M must be positive: %v", config.ElevationIntervalKm*1000)
+	}
+
+	if len(config.POIFiles) > 0 && (config.POIRadiusKm <= 0 || config.POILimit <= 0) {
+		log.Fatalf("POI_RADIUS_KM and POI_LIMIT must be positive: %v, %d", config.POIRadiusKm, config.POILimit)
+	}
+
+	if config.SnapMaxDistanceKm <= 0 {
+		log.Fatalf("SNAP_MAX_DISTANCE_M must be positive: %v", config.SnapMaxDistanceKm*1000)
+	}
+	if config.SnapMinConfidence <= 0 || config.SnapMinConfidence > 1 {
+		log.Fatalf("SNAP_MIN_CONFIDENCE must be between 0 and 1: %v", config.SnapMinConfidence)
+	}
+
+	if config.CacheGeohashPrecision < 1 || config.CacheGeohashPrecision > 12 {
+		log.Fatalf("CACHE_GEOHASH_PRECISION must be between 1 and 12: %d", config.CacheGeohashPrecision)
 	}
 
 	return config
@@ -45,3 +255,62 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("%s must be an integer: %v", key, err)
+	}
+	return parsed
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("%s must be a number: %v", key, err)
+	}
+	return parsed
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("%s must be true or false: %v", key, err)
+	}
+	return parsed
+}
+
+// getEnvList splits a comma separated value, skipping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("%s must be a duration such as 30m or 24h: %v", key, err)
+	}
+	return parsed
+}
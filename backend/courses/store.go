@@ -0,0 +1,70 @@
+// Package courses keeps the course details returned to clients so that they
+// can be fetched again by ID, e.g. to export them to GPS devices.
+package courses
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"potarin-backend/cache"
+	shared "potarin-shared"
+)
+
+// ErrNotFound is returned by Get when no course is stored under the ID
+var ErrNotFound = errors.New("courses: not found")
+
+// keyPrefix separates courses from other values when the backend is shared
+const keyPrefix = "course:"
+
+// NewID returns a random ID for a course to be stored. Courses are only
+// stored under IDs made here, never under IDs chosen by clients, so that no
+// client can replace the course of another.
+func NewID() string {
+	b := make([]byte, 9)
+	_, _ = rand.Read(b) // never fails
+	return "course-" + hex.EncodeToString(b)
+}
+
+// Store saves course details in a cache backend
+type Store struct {
+	backend cache.Backend
+	ttl     time.Duration
+}
+
+// NewStore creates a store that keeps courses for ttl (forever when ttl <= 0)
+func NewStore(backend cache.Backend, ttl time.Duration) *Store {
+	return &Store{backend: backend, ttl: ttl}
+}
+
+// Save stores the course under its ID, replacing an earlier course with the
+// same ID
+func (s *Store) Save(course shared.CourseDetails) error {
+	if course.ID == "" {
+		return errors.New("courses: course has no ID")
+	}
+	data, err := json.Marshal(course)
+	if err != nil {
+		return fmt.Errorf("courses: encoding course %s: %w", course.ID, err)
+	}
+	return s.backend.Set(keyPrefix+course.ID, data, s.ttl)
+}
+
+// Get returns the course stored under id
+func (s *Store) Get(id string) (*shared.CourseDetails, error) {
+	entry, err := s.backend.Get(keyPrefix + id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var course shared.CourseDetails
+	if err := json.Unmarshal(entry.Value, &course); err != nil {
+		return nil, fmt.Errorf("courses: decoding course %s: %w", id, err)
+	}
+	return &course, nil
+}
@@ -0,0 +1,37 @@
+package courses
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+	shared "potarin-shared"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(cache.NewMemoryBackend(cache.Limits{}), 0)
+
+	_, err := store.Get("course-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	course := shared.CourseDetails{
+		ID:         "course-1",
+		Title:      "皇居ラン",
+		CourseType: "jogging",
+		Waypoints:  []shared.Waypoint{{ID: "wp-1", Title: "桜田門", Type: "start"}},
+	}
+	require.NoError(t, store.Save(course))
+
+	stored, err := store.Get("course-1")
+	require.NoError(t, err)
+	assert.Equal(t, course, *stored)
+
+	assert.Error(t, store.Save(shared.CourseDetails{Title: "IDなし"}))
+}
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+	assert.Regexp(t, `^course-[0-9a-f]{18}$`, id)
+	assert.NotEqual(t, id, NewID())
+}
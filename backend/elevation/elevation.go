@@ -0,0 +1,99 @@
+// Package elevation reads terrain heights from local DEM data and builds
+// elevation profiles along course geometry.
+package elevation
+
+import (
+	"errors"
+	"fmt"
+
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// ErrNoData is returned when the DEM has no height for a location, e.g.
+// because its tile is missing or the sample is void
+var ErrNoData = errors.New("elevation: no data")
+
+// DefaultGainThresholdM is the minimum rise counted as climbing. It
+// suppresses DEM noise that would otherwise inflate the gain.
+const DefaultGainThresholdM = 2.0
+
+// Source returns the terrain height in meters at a coordinate.
+// Implementations must be safe for concurrent use.
+type Source interface {
+	ElevationAt(lat, lng float64) (float64, error)
+}
+
+// Sample is the height at a distance along a line
+type Sample struct {
+	DistanceKm float64
+	ElevationM float64
+}
+
+// Profile is the elevation profile of a line
+type Profile struct {
+	GainM   float64
+	LossM   float64
+	Samples []Sample
+}
+
+// BuildProfile samples source every intervalKm along the line through
+// points. Locations without data are skipped; ErrNoData is returned when
+// fewer than two samples remain.
+func BuildProfile(source Source, points []shared.Position, intervalKm float64) (*Profile, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("elevation: at least 2 points are required, got %d", len(points))
+	}
+
+	resampled := polyline.Resample(points, intervalKm)
+	distances := polyline.CumulativeKm(resampled)
+
+	profile := &Profile{}
+	for i, point := range resampled {
+		height, err := source.ElevationAt(point.Latitude, point.Longitude)
+		if errors.Is(err, ErrNoData) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		profile.Samples = append(profile.Samples, Sample{DistanceKm: distances[i], ElevationM: height})
+	}
+	if len(profile.Samples) < 2 {
+		return nil, ErrNoData
+	}
+
+	heights := make([]float64, len(profile.Samples))
+	for i, sample := range profile.Samples {
+		heights[i] = sample.ElevationM
+	}
+	profile.GainM, profile.LossM = climb(heights, DefaultGainThresholdM)
+	return profile, nil
+}
+
+// climb returns the total ascent and descent of heights, counting a change
+// only once it exceeds threshold from the last turning point
+func climb(heights []float64, threshold float64) (gain, loss float64) {
+	if len(heights) == 0 {
+		return 0, 0
+	}
+	reference := heights[0]
+	for _, height := range heights[1:] {
+		switch delta := height - reference; {
+		case delta >= threshold:
+			gain += delta
+			reference = height
+		case -delta >= threshold:
+			loss -= delta
+			reference = height
+		}
+	}
+	return gain, loss
+}
+
+// bilinear interpolates the four corner values of a cell at fractions fx, fy
+func bilinear(topLeft, topRight, bottomLeft, bottomRight, fx, fy float64) float64 {
+	top := topLeft + (topRight-topLeft)*fx
+	bottom := bottomLeft + (bottomRight-bottomLeft)*fx
+	return top + (bottom-top)*fy
+}
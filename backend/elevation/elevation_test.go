@@ -0,0 +1,258 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "potarin-shared"
+)
+
+// sourceFunc adapts a function to Source
+type sourceFunc func(lat, lng float64) (float64, error)
+
+func (f sourceFunc) ElevationAt(lat, lng float64) (float64, error) { return f(lat, lng) }
+
+func TestBuildProfile(t *testing.T) {
+	// A hill rising 100m to the middle of a 2km line and falling again
+	hill := sourceFunc(func(lat, lng float64) (float64, error) {
+		return 100 - math.Abs(lat-35.01)*10000, nil
+	})
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.02, Longitude: 139.0},
+	}
+
+	profile, err := BuildProfile(hill, points, 0.1)
+	require.NoError(t, err)
+
+	require.Len(t, profile.Samples, 24)
+	assert.Equal(t, 0.0, profile.Samples[0].DistanceKm)
+	assert.InDelta(t, 0.0, profile.Samples[0].ElevationM, 1e-6)
+	assert.InDelta(t, 2.224, profile.Samples[23].DistanceKm, 0.001)
+	assert.InDelta(t, 100, profile.GainM, 10)
+	assert.InDelta(t, 100, profile.LossM, 10)
+}
+
+func TestBuildProfile_NoData(t *testing.T) {
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.02, Longitude: 139.0},
+	}
+
+	partial := sourceFunc(func(lat, lng float64) (float64, error) {
+		if lat > 35.01 {
+			return 0, ErrNoData
+		}
+		return 10, nil
+	})
+	profile, err := BuildProfile(partial, points, 0.5)
+	require.NoError(t, err)
+	assert.Len(t, profile.Samples, 3)
+
+	empty := sourceFunc(func(lat, lng float64) (float64, error) { return 0, ErrNoData })
+	_, err = BuildProfile(empty, points, 0.5)
+	assert.ErrorIs(t, err, ErrNoData)
+
+	failing := sourceFunc(func(lat, lng float64) (float64, error) { return 0, fmt.Errorf("disk error") })
+	_, err = BuildProfile(failing, points, 0.5)
+	assert.EqualError(t, err, "disk error")
+}
+
+func TestClimb(t *testing.T) {
+	tests := []struct {
+		name    string
+		heights []float64
+		gain    float64
+		loss    float64
+	}{
+		{"flat", []float64{10, 10, 10}, 0, 0},
+		{"noise below threshold", []float64{10, 11, 10, 11.5, 10}, 0, 0},
+		{"climb and descent", []float64{10, 20, 30, 25, 15}, 20, 15},
+		{"slow climb", []float64{10, 11, 12, 13, 14}, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gain, loss := climb(tt.heights, DefaultGainThresholdM)
+			assert.Equal(t, tt.gain, gain)
+			assert.Equal(t, tt.loss, loss)
+		})
+	}
+}
+
+func TestHGTName(t *testing.T) {
+	assert.Equal(t, "N35E139.hgt", HGTName(35.681236, 139.767125))
+	assert.Equal(t, "S01W001.hgt", HGTName(-0.5, -0.5))
+	assert.Equal(t, "N00E000.hgt", HGTName(0, 0))
+}
+
+// writeHGT writes a 3 arc-second tile whose height is the column index
+func writeHGT(t *testing.T, dir, name string, void ...int) {
+	t.Helper()
+
+	const size = 1201
+	data := make([]byte, size*size*2)
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			binary.BigEndian.PutUint16(data[(row*size+col)*2:], uint16(col))
+		}
+	}
+	for _, index := range void {
+		binary.BigEndian.PutUint16(data[index*2:], uint16(0x8000))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+}
+
+func TestHGTSource(t *testing.T) {
+	dir := t.TempDir()
+	// The sample at row 0 (north edge), column 600 is void
+	writeHGT(t, dir, "N35E139.hgt", 600)
+
+	source, err := NewHGTSource(dir)
+	require.NoError(t, err)
+
+	height, err := source.ElevationAt(35.5, 139.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 600, height, 1e-9)
+
+	// Halfway between two columns
+	height, err = source.ElevationAt(35.5, 139+600.5/1200)
+	require.NoError(t, err)
+	assert.InDelta(t, 600.5, height, 1e-9)
+
+	// The east edge is the last column
+	height, err = source.ElevationAt(35.5, 139.9999999)
+	require.NoError(t, err)
+	assert.InDelta(t, 1200, height, 0.001)
+
+	_, err = source.ElevationAt(35.9999, 139.5)
+	assert.ErrorIs(t, err, ErrNoData)
+
+	_, err = source.ElevationAt(34.5, 139.5)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestHGTSource_InvalidTile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "N35E139.hgt"), []byte("short"), 0o644))
+
+	source, err := NewHGTSource(dir)
+	require.NoError(t, err)
+	_, err = source.ElevationAt(35.5, 139.5)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoData)
+
+	_, err = NewHGTSource(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+// gsiPixelCenter returns the coordinate at the center of a tile pixel
+func gsiPixelCenter(zoom, tileX, tileY, px, py int) (lat, lng float64) {
+	scale := math.Exp2(float64(zoom)) * gsiTileSize
+	worldX := float64(tileX*gsiTileSize+px) + 0.5
+	worldY := float64(tileY*gsiTileSize+py) + 0.5
+	lng = worldX/scale*360 - 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*worldY/scale))) * 180 / math.Pi
+	return lat, lng
+}
+
+// Tokyo Station lies in this zoom 14 tile
+const gsiTestZoom, gsiTestX, gsiTestY = 14, 14552, 6451
+
+func gsiTilePath(t *testing.T, dir, ext string) string {
+	t.Helper()
+	tileDir := filepath.Join(dir, "14", "14552")
+	require.NoError(t, os.MkdirAll(tileDir, 0o755))
+	return filepath.Join(tileDir, "6451"+ext)
+}
+
+func TestGSISource_Text(t *testing.T) {
+	dir := t.TempDir()
+
+	// Heights are the row index, except the first value of every row
+	var b strings.Builder
+	for row := 0; row < gsiTileSize; row++ {
+		values := make([]string, gsiTileSize)
+		values[0] = "e"
+		for col := 1; col < gsiTileSize; col++ {
+			values[col] = fmt.Sprintf("%d.5", row)
+		}
+		b.WriteString(strings.Join(values, ",") + "\n")
+	}
+	require.NoError(t, os.WriteFile(gsiTilePath(t, dir, ".txt"), []byte(b.String()), 0o644))
+
+	source, err := NewGSISource(dir, gsiTestZoom)
+	require.NoError(t, err)
+
+	lat, lng := gsiPixelCenter(gsiTestZoom, gsiTestX, gsiTestY, 10, 42)
+	height, err := source.ElevationAt(lat, lng)
+	require.NoError(t, err)
+	assert.Equal(t, 42.5, height)
+
+	lat, lng = gsiPixelCenter(gsiTestZoom, gsiTestX, gsiTestY, 0, 42)
+	_, err = source.ElevationAt(lat, lng)
+	assert.ErrorIs(t, err, ErrNoData)
+
+	// Neighbouring tiles are missing
+	lat, lng = gsiPixelCenter(gsiTestZoom, gsiTestX+1, gsiTestY, 10, 10)
+	_, err = source.ElevationAt(lat, lng)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestGSISource_PNG(t *testing.T) {
+	dir := t.TempDir()
+
+	encode := func(meters float64) color.RGBA {
+		value := int(math.Round(meters * 100))
+		if value < 0 {
+			value += 1 << 24
+		}
+		return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, gsiTileSize, gsiTileSize))
+	for y := 0; y < gsiTileSize; y++ {
+		for x := 0; x < gsiTileSize; x++ {
+			img.Set(x, y, encode(3776.24))
+		}
+	}
+	img.Set(1, 1, encode(-4.5))
+	img.Set(2, 2, color.RGBA{R: 128, A: 255})
+
+	file, err := os.Create(gsiTilePath(t, dir, ".png"))
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(file, img))
+	require.NoError(t, file.Close())
+
+	source, err := NewGSISource(dir, gsiTestZoom)
+	require.NoError(t, err)
+
+	tests := []struct {
+		px, py int
+		height float64
+		noData bool
+	}{
+		{100, 100, 3776.24, false},
+		{1, 1, -4.5, false},
+		{2, 2, 0, true},
+	}
+	for _, tt := range tests {
+		lat, lng := gsiPixelCenter(gsiTestZoom, gsiTestX, gsiTestY, tt.px, tt.py)
+		height, err := source.ElevationAt(lat, lng)
+		if tt.noData {
+			assert.ErrorIs(t, err, ErrNoData)
+			continue
+		}
+		require.NoError(t, err)
+		assert.InDelta(t, tt.height, height, 1e-9)
+	}
+}
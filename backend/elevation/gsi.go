@@ -0,0 +1,194 @@
+package elevation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gsiTileSize is the width and height of GSI elevation tiles in pixels
+const gsiTileSize = 256
+
+// maxCachedGSITiles bounds the memory used by decoded tiles of 512KB each
+const maxCachedGSITiles = 128
+
+// DefaultGSIZoom is the zoom level of the GSI "dem" tiles (about 10m mesh)
+const DefaultGSIZoom = 14
+
+// GSISource reads GSI (国土地理院) elevation tiles from a directory laid out
+// as {z}/{x}/{y}.txt or {z}/{x}/{y}.png, as downloaded from
+// https://cyberjapandata.gsi.go.jp/xyz/dem/ or dem_png/. Heights are taken
+// from the nearest pixel.
+type GSISource struct {
+	dir  string
+	zoom int
+
+	mu    sync.Mutex
+	tiles map[string]*gsiTile
+}
+
+// gsiTile holds gsiTileSize x gsiTileSize heights, NaN where there is no
+// data; a nil tile records that the file is missing
+type gsiTile struct {
+	heights []float64
+}
+
+// NewGSISource creates a source reading tiles of the given zoom level from dir
+func NewGSISource(dir string, zoom int) (*GSISource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("elevation: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("elevation: %s is not a directory", dir)
+	}
+	if zoom < 1 || zoom > 18 {
+		return nil, fmt.Errorf("elevation: invalid GSI zoom level %d", zoom)
+	}
+	return &GSISource{dir: dir, zoom: zoom, tiles: make(map[string]*gsiTile)}, nil
+}
+
+// ElevationAt implements Source
+func (s *GSISource) ElevationAt(lat, lng float64) (float64, error) {
+	if math.Abs(lat) > 85 || math.Abs(lng) > 180 {
+		return 0, ErrNoData
+	}
+
+	// Web Mercator pixel coordinates at the source's zoom level
+	scale := math.Exp2(float64(s.zoom)) * gsiTileSize
+	latRad := lat * math.Pi / 180
+	worldX := (lng + 180) / 360 * scale
+	worldY := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * scale
+
+	tileX, tileY := int(worldX/gsiTileSize), int(worldY/gsiTileSize)
+	tile, err := s.tile(tileX, tileY)
+	if err != nil {
+		return 0, err
+	}
+	if tile == nil {
+		return 0, ErrNoData
+	}
+
+	px := min(int(worldX)-tileX*gsiTileSize, gsiTileSize-1)
+	py := min(int(worldY)-tileY*gsiTileSize, gsiTileSize-1)
+	height := tile.heights[py*gsiTileSize+px]
+	if math.IsNaN(height) {
+		return 0, ErrNoData
+	}
+	return height, nil
+}
+
+func (s *GSISource) tile(x, y int) (*gsiTile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fmt.Sprintf("%d/%d/%d", s.zoom, x, y)
+	if tile, ok := s.tiles[key]; ok {
+		return tile, nil
+	}
+
+	base := filepath.Join(s.dir, strconv.Itoa(s.zoom), strconv.Itoa(x), strconv.Itoa(y))
+	tile, err := readGSITile(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.tiles) >= maxCachedGSITiles {
+		clear(s.tiles)
+	}
+	s.tiles[key] = tile
+	return tile, nil
+}
+
+// readGSITile reads base.txt or base.png; missing files return nil
+func readGSITile(base string) (*gsiTile, error) {
+	if data, err := os.ReadFile(base + ".txt"); err == nil {
+		return parseGSIText(data)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("elevation: %w", err)
+	}
+
+	data, err := os.ReadFile(base + ".png")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("elevation: %w", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("elevation: %s.png: %w", base, err)
+	}
+	return parseGSIPNG(img)
+}
+
+// parseGSIText parses 256 lines of 256 comma-separated heights, "e" for no data
+func parseGSIText(data []byte) (*gsiTile, error) {
+	heights := make([]float64, 0, gsiTileSize*gsiTileSize)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		values := strings.Split(line, ",")
+		if len(values) != gsiTileSize {
+			return nil, fmt.Errorf("elevation: GSI tile row has %d values, expected %d", len(values), gsiTileSize)
+		}
+		for _, value := range values {
+			if value == "e" {
+				heights = append(heights, math.NaN())
+				continue
+			}
+			height, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("elevation: invalid GSI height %q", value)
+			}
+			heights = append(heights, height)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(heights) != gsiTileSize*gsiTileSize {
+		return nil, fmt.Errorf("elevation: GSI tile has %d values, expected %d", len(heights), gsiTileSize*gsiTileSize)
+	}
+	return &gsiTile{heights: heights}, nil
+}
+
+// parseGSIPNG decodes heights from RGB pixels: x = R*2^16 + G*2^8 + B is
+// x*0.01m below 2^23, (x-2^24)*0.01m above it, and no data at exactly 2^23
+func parseGSIPNG(img image.Image) (*gsiTile, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() != gsiTileSize || bounds.Dy() != gsiTileSize {
+		return nil, fmt.Errorf("elevation: GSI tile is %dx%d, expected %dx%d", bounds.Dx(), bounds.Dy(), gsiTileSize, gsiTileSize)
+	}
+
+	heights := make([]float64, gsiTileSize*gsiTileSize)
+	for y := 0; y < gsiTileSize; y++ {
+		for x := 0; x < gsiTileSize; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			value := int(r>>8)<<16 | int(g>>8)<<8 | int(b>>8)
+			switch {
+			case value == 1<<23:
+				heights[y*gsiTileSize+x] = math.NaN()
+			case value > 1<<23:
+				heights[y*gsiTileSize+x] = float64(value-1<<24) * 0.01
+			default:
+				heights[y*gsiTileSize+x] = float64(value) * 0.01
+			}
+		}
+	}
+	return &gsiTile{heights: heights}, nil
+}
+
+var _ Source = (*GSISource)(nil)
@@ -0,0 +1,134 @@
+package elevation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// hgtVoid marks missing samples in SRTM data
+const hgtVoid = -32768
+
+// maxCachedTiles bounds the memory used by loaded tiles. A 1 arc-second
+// tile takes 26MB, a 3 arc-second tile 2.9MB.
+const maxCachedTiles = 16
+
+// HGTSource reads SRTM .hgt tiles (1 or 3 arc-second) from a directory.
+// Tiles are named after their south-west corner, e.g. N35E139.hgt, and are
+// loaded on first use.
+type HGTSource struct {
+	dir string
+
+	mu    sync.Mutex
+	tiles map[string]*hgtTile
+}
+
+// hgtTile is a decoded tile; a nil tile records that the file is missing
+type hgtTile struct {
+	size    int
+	heights []int16
+}
+
+// NewHGTSource creates a source reading tiles from dir
+func NewHGTSource(dir string) (*HGTSource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("elevation: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("elevation: %s is not a directory", dir)
+	}
+	return &HGTSource{dir: dir, tiles: make(map[string]*hgtTile)}, nil
+}
+
+// HGTName returns the name of the tile covering a coordinate
+func HGTName(lat, lng float64) string {
+	south, west := math.Floor(lat), math.Floor(lng)
+	ns, ew := 'N', 'E'
+	if south < 0 {
+		ns = 'S'
+	}
+	if west < 0 {
+		ew = 'W'
+	}
+	return fmt.Sprintf("%c%02d%c%03d.hgt", ns, int(math.Abs(south)), ew, int(math.Abs(west)))
+}
+
+// ElevationAt implements Source with bilinear interpolation between samples
+func (s *HGTSource) ElevationAt(lat, lng float64) (float64, error) {
+	tile, err := s.tile(HGTName(lat, lng))
+	if err != nil {
+		return 0, err
+	}
+	if tile == nil {
+		return 0, ErrNoData
+	}
+
+	// Rows run from north to south, columns from west to east
+	last := float64(tile.size - 1)
+	x := (lng - math.Floor(lng)) * last
+	y := (math.Floor(lat) + 1 - lat) * last
+	col, row := int(math.Min(math.Floor(x), last-1)), int(math.Min(math.Floor(y), last-1))
+
+	var corners [4]float64
+	for i, index := range [4]int{row*tile.size + col, row*tile.size + col + 1, (row+1)*tile.size + col, (row+1)*tile.size + col + 1} {
+		if tile.heights[index] == hgtVoid {
+			return 0, ErrNoData
+		}
+		corners[i] = float64(tile.heights[index])
+	}
+	return bilinear(corners[0], corners[1], corners[2], corners[3], x-float64(col), y-float64(row)), nil
+}
+
+func (s *HGTSource) tile(name string) (*hgtTile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tile, ok := s.tiles[name]; ok {
+		return tile, nil
+	}
+
+	tile, err := readHGT(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(s.tiles) >= maxCachedTiles {
+		clear(s.tiles)
+	}
+	s.tiles[name] = tile
+	return tile, nil
+}
+
+// readHGT decodes a tile of big-endian int16 samples; a missing file returns nil
+func readHGT(path string) (*hgtTile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("elevation: %w", err)
+	}
+
+	var size int
+	switch len(data) {
+	case 3601 * 3601 * 2:
+		size = 3601
+	case 1201 * 1201 * 2:
+		size = 1201
+	default:
+		return nil, fmt.Errorf("elevation: %s has unexpected size %d", path, len(data))
+	}
+
+	heights := make([]int16, size*size)
+	for i := range heights {
+		heights[i] = int16(binary.BigEndian.Uint16(data[i*2:]))
+	}
+	return &hgtTile{size: size, heights: heights}, nil
+}
+
+var _ Source = (*HGTSource)(nil)
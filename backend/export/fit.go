@@ -0,0 +1,386 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// FITContentType is the media type of FIT files
+const FITContentType = "application/vnd.ant.fit"
+
+// FIT protocol and profile versions written in the file header
+const (
+	fitProtocolVersion = 0x10 // 1.0, read by every device
+	fitProfileVersion  = 2140 // 21.40, which has the newer course point types
+)
+
+// fitEpoch is the start of FIT timestamps
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// Global message numbers
+const (
+	fitMesgFileID      = 0
+	fitMesgLap         = 19
+	fitMesgRecord      = 20
+	fitMesgEvent       = 21
+	fitMesgCourse      = 31
+	fitMesgCoursePoint = 32
+)
+
+// Base types of fields
+const (
+	fitEnum    = 0x00
+	fitUint8   = 0x02
+	fitString  = 0x07
+	fitUint16  = 0x84
+	fitSint32  = 0x85
+	fitUint32  = 0x86
+	fitUint32z = 0x8C
+)
+
+// Values of enum fields
+const (
+	fitFileCourse              = 6
+	fitManufacturerDevelop     = 255
+	fitEventTimer              = 0
+	fitEventLap                = 9
+	fitEventTypeStart          = 0
+	fitEventTypeStop           = 1
+	fitEventTypeStopDisableAll = 9
+	fitCoursePointGeneric      = 0
+	fitCoursePointCheckpoint   = 35
+	fitCoursePointOverlook     = 38
+)
+
+// fitInvalidUint16 marks a uint16 field without a value
+const fitInvalidUint16 = 0xFFFF
+
+// Sizes in bytes of the name fields, including the terminating null
+const (
+	fitCourseNameSize      = 64
+	fitCoursePointNameSize = 32
+)
+
+// fitSports maps course types to FIT sports
+var fitSports = map[string]uint8{
+	"cycling": 2,
+	"jogging": 1,
+	"walking": 11,
+}
+
+// fitCoursePointTypes maps waypoint types to course point types. Devices
+// older than the types show them as generic points.
+var fitCoursePointTypes = map[string]uint8{
+	"start":      fitCoursePointGeneric,
+	"checkpoint": fitCoursePointCheckpoint,
+	"landmark":   fitCoursePointOverlook,
+	"end":        fitCoursePointGeneric,
+}
+
+// fitDefaultSpeedKmh is the speed of the virtual partner when the course has
+// no estimated time
+var fitDefaultSpeedKmh = map[string]float64{
+	"cycling": 18,
+	"jogging": 9,
+	"walking": 4.5,
+}
+
+// FIT renders the course as a FIT course file for bike computers and
+// watches: file_id, course and lap messages, a record per point of the route
+// geometry (or of the waypoints without it) and a course_point per waypoint,
+// between timer start and stop events. Timestamps start now and advance at
+// the pace of the estimated time, which devices use for the virtual partner.
+func FIT(course shared.CourseDetails) ([]byte, error) {
+	return encodeFIT(course, time.Now())
+}
+
+func encodeFIT(course shared.CourseDetails, start time.Time) ([]byte, error) {
+	route, err := routePositions(course)
+	if err != nil {
+		return nil, err
+	}
+	if len(route) < 2 {
+		return nil, errors.New("a FIT course needs at least 2 positions")
+	}
+
+	cumulative := polyline.CumulativeKm(route)
+	totalKm := cumulative[len(cumulative)-1]
+	secondsPerKm := 3600 / fitDefaultSpeedKmh["walking"]
+	if speed, ok := fitDefaultSpeedKmh[course.CourseType]; ok {
+		secondsPerKm = 3600 / speed
+	}
+	if course.EstimatedTime > 0 && totalKm > 0 {
+		secondsPerKm = float64(course.EstimatedTime) * 60 / totalKm
+	}
+	startTime := fitTimestamp(start)
+	timestampAt := func(distanceKm float64) uint32 {
+		return startTime + uint32(math.Round(distanceKm*secondsPerKm))
+	}
+	endTime := timestampAt(totalKm)
+
+	w := &fitWriter{}
+	w.define(0, fitMesgFileID,
+		fitField{0, 1, fitEnum},    // type
+		fitField{1, 2, fitUint16},  // manufacturer
+		fitField{2, 2, fitUint16},  // product
+		fitField{3, 4, fitUint32z}, // serial_number
+		fitField{4, 4, fitUint32},  // time_created
+	)
+	w.data(0, uint8(fitFileCourse), uint16(fitManufacturerDevelop), uint16(0), fitSerialNumber(course.ID), startTime)
+
+	w.define(1, fitMesgCourse,
+		fitField{4, 1, fitEnum},                   // sport
+		fitField{5, fitCourseNameSize, fitString}, // name
+	)
+	w.data(1, fitSport(course.CourseType), course.Title)
+
+	ascent := uint16(fitInvalidUint16)
+	if course.Elevation != nil {
+		ascent = uint16(math.Min(math.Round(course.Elevation.Gain), fitInvalidUint16-1))
+	}
+	first, last := route[0], route[len(route)-1]
+	w.define(2, fitMesgLap,
+		fitField{253, 4, fitUint32}, // timestamp
+		fitField{0, 1, fitEnum},     // event
+		fitField{1, 1, fitEnum},     // event_type
+		fitField{2, 4, fitUint32},   // start_time
+		fitField{3, 4, fitSint32},   // start_position_lat
+		fitField{4, 4, fitSint32},   // start_position_long
+		fitField{5, 4, fitSint32},   // end_position_lat
+		fitField{6, 4, fitSint32},   // end_position_long
+		fitField{7, 4, fitUint32},   // total_elapsed_time, ms
+		fitField{8, 4, fitUint32},   // total_timer_time, ms
+		fitField{9, 4, fitUint32},   // total_distance, cm
+		fitField{21, 2, fitUint16},  // total_ascent, m
+	)
+	elapsed := (endTime - startTime) * 1000
+	w.data(2, endTime, uint8(fitEventLap), uint8(fitEventTypeStop), startTime,
+		fitSemicircles(first.Latitude), fitSemicircles(first.Longitude),
+		fitSemicircles(last.Latitude), fitSemicircles(last.Longitude),
+		elapsed, elapsed, fitCentimeters(totalKm), ascent)
+
+	w.define(3, fitMesgEvent,
+		fitField{253, 4, fitUint32}, // timestamp
+		fitField{0, 1, fitEnum},     // event
+		fitField{1, 1, fitEnum},     // event_type
+		fitField{4, 1, fitUint8},    // event_group
+	)
+	w.data(3, startTime, uint8(fitEventTimer), uint8(fitEventTypeStart), uint8(0))
+
+	w.define(4, fitMesgRecord,
+		fitField{253, 4, fitUint32}, // timestamp
+		fitField{0, 4, fitSint32},   // position_lat
+		fitField{1, 4, fitSint32},   // position_long
+		fitField{2, 2, fitUint16},   // altitude, 5 * (m + 500)
+		fitField{5, 4, fitUint32},   // distance, cm
+	)
+	for i, position := range route {
+		w.data(4, timestampAt(cumulative[i]),
+			fitSemicircles(position.Latitude), fitSemicircles(position.Longitude),
+			fitAltitude(course.Elevation, cumulative[i]), fitCentimeters(cumulative[i]))
+	}
+
+	w.define(5, fitMesgCoursePoint,
+		fitField{254, 2, fitUint16},                    // message_index
+		fitField{1, 4, fitUint32},                      // timestamp
+		fitField{2, 4, fitSint32},                      // position_lat
+		fitField{3, 4, fitSint32},                      // position_long
+		fitField{4, 4, fitUint32},                      // distance, cm
+		fitField{5, 1, fitEnum},                        // type
+		fitField{6, fitCoursePointNameSize, fitString}, // name
+	)
+	for i, point := range fitCoursePoints(course, route, totalKm) {
+		w.data(5, uint16(i), timestampAt(point.alongKm),
+			fitSemicircles(point.waypoint.Position.Latitude), fitSemicircles(point.waypoint.Position.Longitude),
+			fitCentimeters(point.alongKm), fitCoursePointTypes[point.waypoint.Type], point.waypoint.Title)
+	}
+
+	w.data(3, endTime, uint8(fitEventTimer), uint8(fitEventTypeStopDisableAll), uint8(0))
+
+	if w.err != nil {
+		return nil, fmt.Errorf("encoding FIT: %w", w.err)
+	}
+	return w.file(), nil
+}
+
+type fitCoursePoint struct {
+	waypoint shared.Waypoint
+	alongKm  float64
+}
+
+// fitCoursePoints places the waypoints along the route in the order they
+// are passed. The start and end waypoints are at the ends of the route, so
+// that those of a loop are not both placed at its start.
+func fitCoursePoints(course shared.CourseDetails, route []shared.Position, totalKm float64) []fitCoursePoint {
+	points := make([]fitCoursePoint, 0, len(course.Waypoints))
+	for _, waypoint := range course.Waypoints {
+		point := fitCoursePoint{waypoint: waypoint}
+		switch waypoint.Type {
+		case "start":
+		case "end":
+			point.alongKm = totalKm
+		default:
+			_, _, point.alongKm, _ = polyline.Nearest(route, waypoint.Position)
+		}
+		points = append(points, point)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].alongKm < points[j].alongKm
+	})
+	return points
+}
+
+func fitSport(courseType string) uint8 {
+	sport, ok := fitSports[courseType]
+	if !ok {
+		return 0 // generic
+	}
+	return sport
+}
+
+func fitTimestamp(t time.Time) uint32 {
+	return uint32(t.Sub(fitEpoch) / time.Second)
+}
+
+// fitSemicircles converts degrees to the semicircles of FIT positions
+func fitSemicircles(degrees float64) int32 {
+	return int32(math.Round(degrees * (1 << 31) / 180))
+}
+
+func fitCentimeters(km float64) uint32 {
+	return uint32(math.Round(km * 100000))
+}
+
+// fitAltitude interpolates the elevation profile at distanceKm, or returns
+// the invalid value without one
+func fitAltitude(elevation *shared.Elevation, distanceKm float64) uint16 {
+	if elevation == nil || len(elevation.Profile) == 0 {
+		return fitInvalidUint16
+	}
+	profile := elevation.Profile
+	meters := profile[len(profile)-1].Elevation
+	for i, sample := range profile {
+		if distanceKm > sample.Distance {
+			continue
+		}
+		meters = sample.Elevation
+		if i > 0 && sample.Distance > profile[i-1].Distance {
+			previous := profile[i-1]
+			t := (distanceKm - previous.Distance) / (sample.Distance - previous.Distance)
+			meters = previous.Elevation + (sample.Elevation-previous.Elevation)*t
+		}
+		break
+	}
+	return uint16(math.Max(0, math.Min(math.Round((meters+500)*5), fitInvalidUint16-1)))
+}
+
+// fitSerialNumber derives a stable serial number from the course ID so that
+// devices tell courses apart. Zero is the invalid value of the field.
+func fitSerialNumber(id string) uint32 {
+	if serial := crc32.ChecksumIEEE([]byte(id)); serial != 0 {
+		return serial
+	}
+	return 1
+}
+
+// fitField defines a field of a message: its number, size in bytes and base type
+type fitField struct {
+	number   byte
+	size     byte
+	baseType byte
+}
+
+// fitWriter writes the records of a FIT file. Data messages are written
+// with the values of the fields of their local definition, in order.
+type fitWriter struct {
+	records     bytes.Buffer
+	definitions [16][]fitField
+	err         error
+}
+
+func (w *fitWriter) define(local byte, global uint16, fields ...fitField) {
+	w.definitions[local] = fields
+	w.records.WriteByte(0x40 | local)
+	w.records.WriteByte(0) // reserved
+	w.records.WriteByte(0) // little endian
+	_ = binary.Write(&w.records, binary.LittleEndian, global)
+	w.records.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		w.records.Write([]byte{field.number, field.size, field.baseType})
+	}
+}
+
+func (w *fitWriter) data(local byte, values ...any) {
+	fields := w.definitions[local]
+	if len(values) != len(fields) {
+		w.err = fmt.Errorf("message %d has %d fields, got %d values", local, len(fields), len(values))
+		return
+	}
+	w.records.WriteByte(local)
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			w.records.Write(fitStringValue(s, int(fields[i].size)))
+			continue
+		}
+		if err := binary.Write(&w.records, binary.LittleEndian, value); err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+}
+
+// fitStringValue null terminates s, cut at a character boundary to fit size
+// bytes, and pads it to size
+func fitStringValue(s string, size int) []byte {
+	value := make([]byte, size)
+	end := 0
+	for i, r := range s {
+		if i+utf8.RuneLen(r) > size-1 {
+			break
+		}
+		end = i + utf8.RuneLen(r)
+	}
+	copy(value, s[:end])
+	return value
+}
+
+// file returns the header, records and CRC of the FIT file
+func (w *fitWriter) file() []byte {
+	var file bytes.Buffer
+	file.WriteByte(14) // header size
+	file.WriteByte(fitProtocolVersion)
+	_ = binary.Write(&file, binary.LittleEndian, uint16(fitProfileVersion))
+	_ = binary.Write(&file, binary.LittleEndian, uint32(w.records.Len()))
+	file.WriteString(".FIT")
+	_ = binary.Write(&file, binary.LittleEndian, fitCRC(0, file.Bytes()))
+	file.Write(w.records.Bytes())
+	_ = binary.Write(&file, binary.LittleEndian, fitCRC(0, file.Bytes()))
+	return file.Bytes()
+}
+
+var fitCRCTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// fitCRC continues the CRC-16 of FIT files over data
+func fitCRC(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		tmp := fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[b&0xF]
+		tmp = fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[(b>>4)&0xF]
+	}
+	return crc
+}
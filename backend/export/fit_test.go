@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// fitMessage is a data message read back in tests, with the raw bytes of
+// its fields by field number
+type fitMessage struct {
+	global uint16
+	fields map[byte][]byte
+}
+
+func (m fitMessage) uint16(number byte) uint16 {
+	return binary.LittleEndian.Uint16(m.fields[number])
+}
+
+func (m fitMessage) uint32(number byte) uint32 {
+	return binary.LittleEndian.Uint32(m.fields[number])
+}
+
+func (m fitMessage) sint32(number byte) int32 {
+	return int32(m.uint32(number))
+}
+
+func (m fitMessage) string(number byte) string {
+	value := m.fields[number]
+	if end := bytes.IndexByte(value, 0); end >= 0 {
+		value = value[:end]
+	}
+	return string(value)
+}
+
+// parseFIT checks the header and CRCs of a FIT file and returns its data
+// messages. It reads only the little endian messages the encoder writes.
+func parseFIT(t *testing.T, data []byte) []fitMessage {
+	t.Helper()
+	require.Greater(t, len(data), 16)
+	require.Equal(t, byte(14), data[0])
+	assert.Equal(t, ".FIT", string(data[8:12]))
+	assert.Equal(t, fitCRC(0, data[:12]), binary.LittleEndian.Uint16(data[12:14]))
+	size := int(binary.LittleEndian.Uint32(data[4:8]))
+	require.Equal(t, len(data), 14+size+2)
+	assert.Zero(t, fitCRC(0, data), "the file CRC covers everything before it")
+
+	var definitions [16]struct {
+		global uint16
+		fields []fitField
+	}
+	var messages []fitMessage
+	records := bytes.NewReader(data[14 : 14+size])
+	for records.Len() > 0 {
+		header, err := records.ReadByte()
+		require.NoError(t, err)
+		local := header & 0x0F
+		if header&0x40 != 0 {
+			fixed := make([]byte, 5)
+			_, err := records.Read(fixed)
+			require.NoError(t, err)
+			require.Zero(t, fixed[1], "little endian")
+			definitions[local].global = binary.LittleEndian.Uint16(fixed[2:4])
+			definitions[local].fields = make([]fitField, fixed[4])
+			for i := range definitions[local].fields {
+				field := make([]byte, 3)
+				_, err := records.Read(field)
+				require.NoError(t, err)
+				definitions[local].fields[i] = fitField{field[0], field[1], field[2]}
+			}
+			continue
+		}
+
+		require.NotNil(t, definitions[local].fields, "data message before its definition")
+		message := fitMessage{global: definitions[local].global, fields: map[byte][]byte{}}
+		for _, field := range definitions[local].fields {
+			value := make([]byte, field.size)
+			_, err := records.Read(value)
+			require.NoError(t, err)
+			message.fields[field.number] = value
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func TestFIT(t *testing.T) {
+	course := testCourse()
+	course.EstimatedTime = 30
+	line := []shared.Position{{Latitude: 35.6778, Longitude: 139.7528}, {Latitude: 35.6800, Longitude: 139.7540}, {Latitude: 35.6790, Longitude: 139.7560}, {Latitude: 35.6778, Longitude: 139.7528}}
+	encoded, err := polyline.Encode(line, polyline.DefaultPrecision)
+	require.NoError(t, err)
+	course.Polyline = &encoded
+	course.Elevation = &shared.Elevation{Gain: 12.4, Profile: []shared.ElevationProfile{{Distance: 0, Elevation: 10}, {Distance: 2, Elevation: 30}}}
+
+	start := time.Date(2025, 7, 1, 6, 0, 0, 0, time.UTC)
+	data, err := encodeFIT(course, start)
+	require.NoError(t, err)
+	messages := parseFIT(t, data)
+
+	globals := make([]uint16, len(messages))
+	for i, message := range messages {
+		globals[i] = message.global
+	}
+	assert.Equal(t, []uint16{
+		fitMesgFileID, fitMesgCourse, fitMesgLap, fitMesgEvent,
+		fitMesgRecord, fitMesgRecord, fitMesgRecord, fitMesgRecord,
+		fitMesgCoursePoint, fitMesgCoursePoint, fitMesgCoursePoint,
+		fitMesgEvent,
+	}, globals)
+
+	fileID := messages[0]
+	assert.Equal(t, []byte{fitFileCourse}, fileID.fields[0])
+	assert.Equal(t, uint32(1120284000), fileID.uint32(4), "seconds since 1989-12-31")
+	assert.NotZero(t, fileID.uint32(3))
+
+	assert.Equal(t, []byte{1}, messages[1].fields[4], "running")
+	assert.Equal(t, course.Title, messages[1].string(5))
+
+	totalKm := polyline.LengthKm(line)
+	lap := messages[2]
+	assert.Equal(t, uint32(30*60*1000), lap.uint32(7))
+	assert.Equal(t, fitCentimeters(totalKm), lap.uint32(9))
+	assert.Equal(t, uint16(12), lap.uint16(21))
+
+	first := messages[4]
+	assert.Equal(t, int32(425652734), first.sint32(0))
+	assert.Equal(t, fitSemicircles(line[0].Longitude), first.sint32(1))
+	assert.Equal(t, uint16((10+500)*5), first.uint16(2))
+	assert.Zero(t, first.uint32(5))
+	last := messages[7]
+	assert.Equal(t, lap.uint32(253), last.uint32(253), "the last record is at the end of the estimated time")
+	assert.Equal(t, fitCentimeters(totalKm), last.uint32(5))
+
+	names := []string{}
+	types := []byte{}
+	for _, point := range messages[8:11] {
+		names = append(names, point.string(6))
+		types = append(types, point.fields[5][0])
+	}
+	assert.Equal(t, []string{"桜田門", "二重橋", "桜田門"}, names)
+	assert.Equal(t, []byte{fitCoursePointGeneric, fitCoursePointOverlook, fitCoursePointGeneric}, types)
+	assert.Equal(t, fitCentimeters(totalKm), messages[10].uint32(4), "the end of a loop is at its end, not its start")
+
+	assert.Equal(t, []byte{fitEventTypeStopDisableAll}, messages[11].fields[1])
+}
+
+func TestFIT_WithoutPolyline(t *testing.T) {
+	data, err := encodeFIT(testCourse(), time.Now())
+	require.NoError(t, err)
+	records := 0
+	for _, message := range parseFIT(t, data) {
+		if message.global == fitMesgRecord {
+			records++
+		}
+	}
+	assert.Equal(t, 3, records, "a record per waypoint")
+
+	course := testCourse()
+	course.Waypoints = course.Waypoints[:1]
+	_, err = encodeFIT(course, time.Now())
+	assert.Error(t, err)
+}
+
+func TestFitStringValue(t *testing.T) {
+	assert.Equal(t, []byte{'a', 'b', 0, 0}, fitStringValue("ab", 4))
+	// Each of the characters takes 3 bytes, only 2 fit before the null
+	assert.Equal(t, append([]byte("桜田"), 0, 0, 0), fitStringValue("桜田門", 9))
+}
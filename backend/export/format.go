@@ -0,0 +1,59 @@
+package export
+
+import (
+	"slices"
+	"strings"
+
+	shared "potarin-shared"
+)
+
+// Format is a file format courses can be exported to
+type Format struct {
+	// Name is the format parameter and file extension, e.g. "gpx"
+	Name        string
+	ContentType string
+	Render      func(course shared.CourseDetails) ([]byte, error)
+	// CourseTypes the format is offered for; all when empty
+	CourseTypes []string
+}
+
+// Supports reports whether courses of courseType can be exported to the format
+func (f Format) Supports(courseType string) bool {
+	return len(f.CourseTypes) == 0 || slices.Contains(f.CourseTypes, courseType)
+}
+
+// formats lists the export formats, the default first
+var formats = []Format{
+	{Name: "gpx", ContentType: GPXContentType, Render: GPX},
+	{Name: "kml", ContentType: KMLContentType, Render: KML},
+	{Name: "kmz", ContentType: KMZContentType, Render: KMZ},
+	{Name: "geojson", ContentType: GeoJSONContentType, Render: GeoJSON},
+	// Course files are made for bike computers and running watches
+	{Name: "fit", ContentType: FITContentType, Render: FIT, CourseTypes: []string{"cycling", "jogging"}},
+}
+
+// Formats returns the export formats, the default first
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// FormatByName returns the format with the given name or file extension
+func FormatByName(name string) (Format, bool) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	for _, format := range formats {
+		if format.Name == name {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// FormatByContentType returns the format with the given media type
+func FormatByContentType(contentType string) (Format, bool) {
+	for _, format := range formats {
+		if format.ContentType == contentType {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
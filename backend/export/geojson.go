@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// GeoJSONContentType is the media type of GeoJSON documents (RFC 7946)
+const GeoJSONContentType = "application/geo+json"
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	BBox     []float64        `json:"bbox,omitempty"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	ID         string          `json:"id,omitempty"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// GeoJSON renders the course as a FeatureCollection: the route as a
+// LineString followed by a Point per waypoint. Properties mirror the JSON of
+// CourseDetails and Waypoint without the fields that became geometry, and
+// waypoints carry the courseId of their course.
+func GeoJSON(course shared.CourseDetails) ([]byte, error) {
+	var features []geoJSONFeature
+	positions := make([]shared.Position, 0, len(course.Waypoints))
+
+	route, err := routePositions(course)
+	if err != nil {
+		return nil, err
+	}
+	if len(route) > 1 {
+		properties, err := geoJSONProperties(course, "waypoints", "polyline")
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, geoJSONFeature{
+			Type:       "Feature",
+			ID:         course.ID,
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: geoJSONLine(route)},
+			Properties: properties,
+		})
+		positions = append(positions, route...)
+	}
+
+	for _, waypoint := range course.Waypoints {
+		properties, err := geoJSONProperties(waypoint, "position")
+		if err != nil {
+			return nil, err
+		}
+		properties["courseId"] = course.ID
+		features = append(features, geoJSONPoint(waypoint.ID, waypoint.Position, properties))
+		positions = append(positions, waypoint.Position)
+	}
+
+	return encodeGeoJSON(features, positions)
+}
+
+// SuggestionsGeoJSON renders suggestions as a FeatureCollection of Points at
+// their start points, with the other fields of CourseSuggestion as properties
+func SuggestionsGeoJSON(suggestions []shared.CourseSuggestion) ([]byte, error) {
+	features := make([]geoJSONFeature, 0, len(suggestions))
+	positions := make([]shared.Position, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		properties, err := geoJSONProperties(suggestion, "startPoint")
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, geoJSONPoint(suggestion.ID, suggestion.StartPoint, properties))
+		positions = append(positions, suggestion.StartPoint)
+	}
+	return encodeGeoJSON(features, positions)
+}
+
+func encodeGeoJSON(features []geoJSONFeature, positions []shared.Position) ([]byte, error) {
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: features}
+	if collection.Features == nil {
+		collection.Features = []geoJSONFeature{}
+	}
+	if bounds, ok := polyline.BoundsOf(positions); ok {
+		collection.BBox = []float64{bounds.MinLongitude, bounds.MinLatitude, bounds.MaxLongitude, bounds.MaxLatitude}
+	}
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return nil, fmt.Errorf("encoding GeoJSON: %w", err)
+	}
+	return data, nil
+}
+
+func geoJSONPoint(id string, position shared.Position, properties map[string]any) geoJSONFeature {
+	return geoJSONFeature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   geoJSONGeometry{Type: "Point", Coordinates: geoJSONPosition(position)},
+		Properties: properties,
+	}
+}
+
+// geoJSONPosition returns the longitude, latitude pair of position
+func geoJSONPosition(position shared.Position) []float64 {
+	return []float64{position.Longitude, position.Latitude}
+}
+
+func geoJSONLine(positions []shared.Position) [][]float64 {
+	line := make([][]float64, len(positions))
+	for i, position := range positions {
+		line[i] = geoJSONPosition(position)
+	}
+	return line
+}
+
+// geoJSONProperties returns the JSON object of v without the omitted fields
+func geoJSONProperties(v any, omit ...string) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding GeoJSON properties: %w", err)
+	}
+	var properties map[string]any
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("encoding GeoJSON properties: %w", err)
+	}
+	for _, field := range omit {
+		delete(properties, field)
+	}
+	return properties, nil
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "potarin-shared"
+)
+
+// parsedGeoJSON is a FeatureCollection read back in tests
+type parsedGeoJSON struct {
+	Type     string    `json:"type"`
+	BBox     []float64 `json:"bbox"`
+	Features []struct {
+		Type     string `json:"type"`
+		ID       string `json:"id"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+func parseGeoJSON(t *testing.T, data []byte) parsedGeoJSON {
+	t.Helper()
+	var collection parsedGeoJSON
+	require.NoError(t, json.Unmarshal(data, &collection))
+	return collection
+}
+
+func TestGeoJSON(t *testing.T) {
+	course := testCourse()
+	course.Distance = 5
+	course.Difficulty = "easy"
+	course.RouteShape = "loop"
+	course.Waypoints[1].Verified = true
+
+	data, err := GeoJSON(course)
+	require.NoError(t, err)
+	collection := parseGeoJSON(t, data)
+
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Equal(t, []float64{139.7528, 35.6778, 139.754, 35.68}, collection.BBox)
+	require.Len(t, collection.Features, 4)
+
+	route := collection.Features[0]
+	assert.Equal(t, "LineString", route.Geometry.Type)
+	assert.Equal(t, "course-1", route.ID)
+	assert.JSONEq(t, `[[139.7528,35.6778],[139.754,35.68],[139.7528,35.6778]]`, string(route.Geometry.Coordinates))
+	assert.Equal(t, course.Title, route.Properties["title"])
+	assert.Equal(t, "loop", route.Properties["routeShape"])
+	assert.Equal(t, 5.0, route.Properties["distance"])
+	assert.NotContains(t, route.Properties, "waypoints")
+	assert.NotContains(t, route.Properties, "polyline")
+
+	landmark := collection.Features[2]
+	assert.Equal(t, "Point", landmark.Geometry.Type)
+	assert.Equal(t, "wp-2", landmark.ID)
+	assert.JSONEq(t, `[139.754,35.68]`, string(landmark.Geometry.Coordinates))
+	assert.Equal(t, map[string]any{
+		"id":          "wp-2",
+		"title":       "二重橋",
+		"description": "",
+		"type":        "landmark",
+		"verified":    true,
+		"courseId":    "course-1",
+	}, landmark.Properties)
+}
+
+func TestSuggestionsGeoJSON(t *testing.T) {
+	data, err := SuggestionsGeoJSON(nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, string(data))
+
+	data, err = SuggestionsGeoJSON([]shared.CourseSuggestion{
+		{ID: "course-a", Title: "皇居ラン", CourseType: "jogging", StartPoint: shared.Position{Latitude: 35.6852, Longitude: 139.7528}, Highlights: []string{"桜田門"}},
+		{ID: "course-b", Title: "隅田川", CourseType: "walking", StartPoint: shared.Position{Latitude: 35.71, Longitude: 139.8}},
+	})
+	require.NoError(t, err)
+	collection := parseGeoJSON(t, data)
+
+	assert.Equal(t, []float64{139.7528, 35.6852, 139.8, 35.71}, collection.BBox)
+	require.Len(t, collection.Features, 2)
+	assert.Equal(t, "course-a", collection.Features[0].ID)
+	assert.JSONEq(t, `[139.7528,35.6852]`, string(collection.Features[0].Geometry.Coordinates))
+	assert.Equal(t, []any{"桜田門"}, collection.Features[0].Properties["highlights"])
+	assert.NotContains(t, collection.Features[0].Properties, "startPoint")
+}
@@ -0,0 +1,144 @@
+// Package export renders course details in the file formats read by GPS
+// devices and route planners.
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// GPXContentType is the media type of GPX documents
+const GPXContentType = "application/gpx+xml"
+
+// gpxCreator identifies the application in the creator attribute
+const gpxCreator = "Potarin"
+
+// gpxSymbols maps waypoint types to the symbol names of Garmin devices,
+// which Komoot and most other apps also understand
+var gpxSymbols = map[string]string{
+	"start":      "Flag, Green",
+	"checkpoint": "Waypoint",
+	"landmark":   "Scenic Area",
+	"end":        "Flag, Red",
+}
+
+type gpxDocument struct {
+	XMLName        xml.Name    `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version        string      `xml:"version,attr"`
+	Creator        string      `xml:"creator,attr"`
+	XSI            string      `xml:"xmlns:xsi,attr"`
+	SchemaLocation string      `xml:"xsi:schemaLocation,attr"`
+	Metadata       gpxMetadata `xml:"metadata"`
+	Waypoints      []gpxPoint  `xml:"wpt"`
+	Routes         []gpxRoute  `xml:"rte"`
+	Tracks         []gpxTrack  `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name        string `xml:"name"`
+	Description string `xml:"desc,omitempty"`
+	Keywords    string `xml:"keywords,omitempty"`
+}
+
+type gpxPoint struct {
+	Latitude    string `xml:"lat,attr"`
+	Longitude   string `xml:"lon,attr"`
+	Name        string `xml:"name,omitempty"`
+	Description string `xml:"desc,omitempty"`
+	Symbol      string `xml:"sym,omitempty"`
+	Type        string `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Type   string     `xml:"type,omitempty"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name     string            `xml:"name"`
+	Type     string            `xml:"type,omitempty"`
+	Segments []gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// GPX renders the course as a GPX 1.1 document. Each waypoint becomes a
+// <wpt>, and the decoded polyline a <trk>. Without a polyline the waypoints
+// are also listed as a <rte> so that devices can still navigate the course.
+func GPX(course shared.CourseDetails) ([]byte, error) {
+	doc := gpxDocument{
+		Version:        "1.1",
+		Creator:        gpxCreator,
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd",
+		Metadata: gpxMetadata{
+			Name:        course.Title,
+			Description: course.Description,
+			Keywords:    course.CourseType,
+		},
+	}
+
+	for _, waypoint := range course.Waypoints {
+		point := gpxPosition(waypoint.Position)
+		point.Name = waypoint.Title
+		point.Description = waypoint.Description
+		point.Symbol = gpxSymbols[waypoint.Type]
+		point.Type = waypoint.Type
+		doc.Waypoints = append(doc.Waypoints, point)
+	}
+
+	if course.Polyline != nil && *course.Polyline != "" {
+		positions, err := polyline.Decode(*course.Polyline, polyline.DefaultPrecision)
+		if err != nil {
+			return nil, fmt.Errorf("decoding polyline of course %s: %w", course.ID, err)
+		}
+		segment := gpxTrackSegment{Points: make([]gpxPoint, len(positions))}
+		for i, position := range positions {
+			segment.Points[i] = gpxPosition(position)
+		}
+		doc.Tracks = append(doc.Tracks, gpxTrack{
+			Name:     course.Title,
+			Type:     course.CourseType,
+			Segments: []gpxTrackSegment{segment},
+		})
+	} else if len(course.Waypoints) > 0 {
+		route := gpxRoute{Name: course.Title, Type: course.CourseType}
+		for _, waypoint := range course.Waypoints {
+			point := gpxPosition(waypoint.Position)
+			point.Name = waypoint.Title
+			route.Points = append(route.Points, point)
+		}
+		doc.Routes = append(doc.Routes, route)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encoding GPX: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func gpxPosition(position shared.Position) gpxPoint {
+	return gpxPoint{
+		Latitude:  formatCoordinate(position.Latitude),
+		Longitude: formatCoordinate(position.Longitude),
+	}
+}
+
+// formatCoordinate writes degrees without an exponent, which GPX's
+// xsd:decimal does not allow
+func formatCoordinate(degrees float64) string {
+	return strconv.FormatFloat(degrees, 'f', -1, 64)
+}
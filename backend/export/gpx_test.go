@@ -0,0 +1,128 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// parsedGPX is the subset of GPX read back in tests
+type parsedGPX struct {
+	Version  string `xml:"version,attr"`
+	Metadata struct {
+		Name        string `xml:"name"`
+		Description string `xml:"desc"`
+	} `xml:"metadata"`
+	Waypoints []struct {
+		Latitude  float64 `xml:"lat,attr"`
+		Longitude float64 `xml:"lon,attr"`
+		Name      string  `xml:"name"`
+		Symbol    string  `xml:"sym"`
+		Type      string  `xml:"type"`
+	} `xml:"wpt"`
+	Routes []struct {
+		Points []struct {
+			Name string `xml:"name"`
+		} `xml:"rtept"`
+	} `xml:"rte"`
+	Tracks []struct {
+		Name   string `xml:"name"`
+		Type   string `xml:"type"`
+		Points []struct {
+			Latitude  float64 `xml:"lat,attr"`
+			Longitude float64 `xml:"lon,attr"`
+		} `xml:"trkseg>trkpt"`
+	} `xml:"trk"`
+}
+
+func testCourse() shared.CourseDetails {
+	return shared.CourseDetails{
+		ID:          "course-1",
+		Title:       "皇居ラン & 日比谷",
+		Description: "桜田門から<外周>を一周します。",
+		CourseType:  "jogging",
+		Waypoints: []shared.Waypoint{
+			{ID: "wp-1", Title: "桜田門", Position: shared.Position{Latitude: 35.6778, Longitude: 139.7528}, Type: "start"},
+			{ID: "wp-2", Title: "二重橋", Position: shared.Position{Latitude: 35.6800, Longitude: 139.7540}, Type: "landmark"},
+			{ID: "wp-3", Title: "桜田門", Position: shared.Position{Latitude: 35.6778, Longitude: 139.7528}, Type: "end"},
+		},
+	}
+}
+
+func parseGPX(t *testing.T, data []byte) parsedGPX {
+	t.Helper()
+	var doc parsedGPX
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	return doc
+}
+
+func TestGPX_Track(t *testing.T) {
+	course := testCourse()
+	line := []shared.Position{{Latitude: 35.6778, Longitude: 139.7528}, {Latitude: 35.6800, Longitude: 139.7540}, {Latitude: 35.6778, Longitude: 139.7528}}
+	encoded, err := polyline.Encode(line, polyline.DefaultPrecision)
+	require.NoError(t, err)
+	course.Polyline = &encoded
+
+	data, err := GPX(course)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), xml.Header))
+	assert.Contains(t, string(data), `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="Potarin"`)
+
+	doc := parseGPX(t, data)
+	assert.Equal(t, "1.1", doc.Version)
+	assert.Equal(t, course.Title, doc.Metadata.Name)
+	assert.Equal(t, course.Description, doc.Metadata.Description)
+
+	require.Len(t, doc.Waypoints, 3)
+	assert.Equal(t, "桜田門", doc.Waypoints[0].Name)
+	assert.Equal(t, "Flag, Green", doc.Waypoints[0].Symbol)
+	assert.Equal(t, "start", doc.Waypoints[0].Type)
+	assert.Equal(t, "Scenic Area", doc.Waypoints[1].Symbol)
+	assert.Equal(t, "Flag, Red", doc.Waypoints[2].Symbol)
+	assert.Equal(t, 35.68, doc.Waypoints[1].Latitude)
+	assert.Equal(t, 139.754, doc.Waypoints[1].Longitude)
+
+	assert.Empty(t, doc.Routes)
+	require.Len(t, doc.Tracks, 1)
+	assert.Equal(t, "jogging", doc.Tracks[0].Type)
+	require.Len(t, doc.Tracks[0].Points, len(line))
+	for i, point := range doc.Tracks[0].Points {
+		assert.InDelta(t, line[i].Latitude, point.Latitude, 1e-6)
+		assert.InDelta(t, line[i].Longitude, point.Longitude, 1e-6)
+	}
+}
+
+func TestGPX_RouteWithoutPolyline(t *testing.T) {
+	doc := parseGPX(t, mustGPX(t, testCourse()))
+
+	assert.Empty(t, doc.Tracks)
+	require.Len(t, doc.Routes, 1)
+	require.Len(t, doc.Routes[0].Points, 3)
+	assert.Equal(t, "二重橋", doc.Routes[0].Points[1].Name)
+}
+
+func TestGPX_InvalidPolyline(t *testing.T) {
+	course := testCourse()
+	broken := "_p~iF~ps|U_"
+	course.Polyline = &broken
+
+	_, err := GPX(course)
+	assert.ErrorIs(t, err, polyline.ErrInvalid)
+}
+
+func TestFormatCoordinate(t *testing.T) {
+	assert.Equal(t, "0.00001", formatCoordinate(0.00001))
+	assert.Equal(t, "-139.7528", formatCoordinate(-139.7528))
+}
+
+func mustGPX(t *testing.T, course shared.CourseDetails) []byte {
+	t.Helper()
+	data, err := GPX(course)
+	require.NoError(t, err)
+	return data
+}
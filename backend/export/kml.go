@@ -0,0 +1,319 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// Media types of KML documents and zipped KMZ archives
+const (
+	KMLContentType = "application/vnd.google-earth.kml+xml"
+	KMZContentType = "application/vnd.google-earth.kmz"
+)
+
+// kmlIconHref is the white Google Earth icon tinted with the waypoint color
+// in plain KML documents
+const kmlIconHref = "https://maps.google.com/mapfiles/kml/shapes/placemark_circle.png"
+
+// kmzIconSize is the width and height of the icons embedded in KMZ archives
+const kmzIconSize = 32
+
+// waypointStyle is the label and marker color of a waypoint type, matching
+// the course map of the frontend
+type waypointStyle struct {
+	Label string
+	Color color.RGBA
+}
+
+var waypointStyles = map[string]waypointStyle{
+	"start":      {Label: "スタート", Color: color.RGBA{R: 0x22, G: 0xc5, B: 0x5e, A: 0xff}},
+	"checkpoint": {Label: "チェックポイント", Color: color.RGBA{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff}},
+	"landmark":   {Label: "ランドマーク", Color: color.RGBA{R: 0xf5, G: 0x9e, B: 0x0b, A: 0xff}},
+	"end":        {Label: "ゴール", Color: color.RGBA{R: 0xef, G: 0x44, B: 0x44, A: 0xff}},
+}
+
+// waypointTypes orders the waypoint styles in documents and archives
+var waypointTypes = []string{"start", "checkpoint", "landmark", "end"}
+
+// routeColor is the color of the route line
+var routeColor = color.RGBA{R: 0x25, G: 0x63, B: 0xeb, A: 0xff}
+
+// Japanese labels of course values in descriptions
+var (
+	courseTypeLabels = map[string]string{"walking": "散歩", "jogging": "ジョギング", "cycling": "サイクリング"}
+	difficultyLabels = map[string]string{"easy": "初級", "moderate": "中級", "hard": "上級"}
+	routeShapeLabels = map[string]string{"loop": "周回", "one_way": "片道", "out_and_back": "往復"}
+)
+
+type kmlRoot struct {
+	XMLName  xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name        string         `xml:"name"`
+	Description kmlCDATA       `xml:"description"`
+	Styles      []kmlStyle     `xml:"Style"`
+	Placemarks  []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	IconStyle *kmlIconStyle `xml:"IconStyle,omitempty"`
+	LineStyle *kmlLineStyle `xml:"LineStyle,omitempty"`
+}
+
+type kmlIconStyle struct {
+	Color string `xml:"color,omitempty"`
+	Href  string `xml:"Icon>href"`
+}
+
+type kmlLineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description kmlCDATA       `xml:"description"`
+	StyleURL    string         `xml:"styleUrl"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// KML renders the course as a KML 2.2 document with a placemark per
+// waypoint, styled by waypoint type, and the route as a LineString
+func KML(course shared.CourseDetails) ([]byte, error) {
+	return renderKML(course, func(waypointType string) *kmlIconStyle {
+		return &kmlIconStyle{Color: kmlColor(waypointStyles[waypointType].Color), Href: kmlIconHref}
+	})
+}
+
+// KMZ renders the course as a KMZ archive: the KML document with icons for
+// the waypoint types embedded, so that it displays the same offline
+func KMZ(course shared.CourseDetails) ([]byte, error) {
+	doc, err := renderKML(course, func(waypointType string) *kmlIconStyle {
+		return &kmlIconStyle{Href: kmzIconPath(waypointType)}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	// Readers take the first .kml file in the archive as the document
+	if err := writeZipFile(archive, "doc.kml", doc); err != nil {
+		return nil, err
+	}
+	for _, waypointType := range waypointTypes {
+		icon, err := waypointIcon(waypointStyles[waypointType].Color)
+		if err != nil {
+			return nil, fmt.Errorf("drawing %s icon: %w", waypointType, err)
+		}
+		if err := writeZipFile(archive, kmzIconPath(waypointType), icon); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("writing KMZ: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderKML(course shared.CourseDetails, iconStyle func(waypointType string) *kmlIconStyle) ([]byte, error) {
+	doc := kmlDocument{
+		Name:        course.Title,
+		Description: kmlCDATA{Text: courseDescription(course)},
+	}
+	for _, waypointType := range waypointTypes {
+		doc.Styles = append(doc.Styles, kmlStyle{ID: waypointType, IconStyle: iconStyle(waypointType)})
+	}
+	doc.Styles = append(doc.Styles, kmlStyle{ID: "route", LineStyle: &kmlLineStyle{Color: kmlColor(routeColor), Width: 4}})
+
+	route, err := routePositions(course)
+	if err != nil {
+		return nil, err
+	}
+	if len(route) > 1 {
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{
+			Name:        course.Title,
+			Description: kmlCDATA{Text: kmlDescription(course.Description)},
+			StyleURL:    "#route",
+			LineString:  &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(route...)},
+		})
+	}
+
+	for _, waypoint := range course.Waypoints {
+		placemark := kmlPlacemark{
+			Name:        waypoint.Title,
+			Description: kmlCDATA{Text: waypointDescription(waypoint)},
+			Point:       &kmlPoint{Coordinates: kmlCoordinates(waypoint.Position)},
+		}
+		if _, ok := waypointStyles[waypoint.Type]; ok {
+			placemark.StyleURL = "#" + waypoint.Type
+		}
+		doc.Placemarks = append(doc.Placemarks, placemark)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(kmlRoot{Document: doc}); err != nil {
+		return nil, fmt.Errorf("encoding KML: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// routePositions returns the decoded polyline, or the waypoints in order
+// when the course has no route geometry
+func routePositions(course shared.CourseDetails) ([]shared.Position, error) {
+	if course.Polyline != nil && *course.Polyline != "" {
+		positions, err := polyline.Decode(*course.Polyline, polyline.DefaultPrecision)
+		if err != nil {
+			return nil, fmt.Errorf("decoding polyline of course %s: %w", course.ID, err)
+		}
+		return positions, nil
+	}
+	positions := make([]shared.Position, len(course.Waypoints))
+	for i, waypoint := range course.Waypoints {
+		positions[i] = waypoint.Position
+	}
+	return positions, nil
+}
+
+// courseDescription lists the course values in Japanese below its description
+func courseDescription(course shared.CourseDetails) string {
+	var lines []string
+	if label := labelOf(courseTypeLabels, course.CourseType); label != "" {
+		lines = append(lines, "コースタイプ: "+label)
+	}
+	if course.Distance > 0 {
+		lines = append(lines, fmt.Sprintf("距離: %.1fkm", course.Distance))
+	}
+	if course.EstimatedTime > 0 {
+		lines = append(lines, fmt.Sprintf("所要時間: 約%d分", course.EstimatedTime))
+	}
+	if label := labelOf(difficultyLabels, course.Difficulty); label != "" {
+		lines = append(lines, "難易度: "+label)
+	}
+	if label := labelOf(routeShapeLabels, course.RouteShape); label != "" {
+		lines = append(lines, "コース形状: "+label)
+	}
+	if course.Elevation != nil && course.Elevation.Gain > 0 {
+		lines = append(lines, fmt.Sprintf("獲得標高: %.0fm", course.Elevation.Gain))
+	}
+	return kmlDescription(course.Description, lines...)
+}
+
+func waypointDescription(waypoint shared.Waypoint) string {
+	var lines []string
+	if style, ok := waypointStyles[waypoint.Type]; ok {
+		lines = append(lines, "種類: "+style.Label)
+	}
+	if waypoint.Verified {
+		lines = append(lines, "実在するスポットの位置です")
+	}
+	return kmlDescription(waypoint.Description, lines...)
+}
+
+func labelOf(labels map[string]string, value string) string {
+	if label, ok := labels[value]; ok {
+		return label
+	}
+	return value
+}
+
+// kmlDescription formats text followed by lines of details as the HTML that
+// Google Earth and My Maps display in balloons
+func kmlDescription(text string, details ...string) string {
+	var paragraphs []string
+	if text != "" {
+		paragraphs = append(paragraphs, html.EscapeString(text))
+	}
+	if len(details) > 0 {
+		escaped := make([]string, len(details))
+		for i, line := range details {
+			escaped[i] = html.EscapeString(line)
+		}
+		paragraphs = append(paragraphs, strings.Join(escaped, "<br>"))
+	}
+	return strings.Join(paragraphs, "<br><br>")
+}
+
+// kmlCoordinates formats positions as KML longitude,latitude tuples
+func kmlCoordinates(positions ...shared.Position) string {
+	tuples := make([]string, len(positions))
+	for i, position := range positions {
+		tuples[i] = formatCoordinate(position.Longitude) + "," + formatCoordinate(position.Latitude)
+	}
+	return strings.Join(tuples, " ")
+}
+
+// kmlColor formats c in the aabbggrr order of KML
+func kmlColor(c color.RGBA) string {
+	return fmt.Sprintf("%02x%02x%02x%02x", c.A, c.B, c.G, c.R)
+}
+
+func kmzIconPath(waypointType string) string {
+	return "files/" + waypointType + ".png"
+}
+
+// waypointIcon draws a filled circle of color c with a white outline
+func waypointIcon(c color.RGBA) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, kmzIconSize, kmzIconSize))
+	center := float64(kmzIconSize-1) / 2
+	outer, inner := center, center-3
+	for y := 0; y < kmzIconSize; y++ {
+		for x := 0; x < kmzIconSize; x++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			switch d := dx*dx + dy*dy; {
+			case d <= inner*inner:
+				img.SetRGBA(x, y, c)
+			case d <= outer*outer:
+				img.SetRGBA(x, y, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("writing %s to KMZ: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing %s to KMZ: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// parsedKML is the subset of KML read back in tests
+type parsedKML struct {
+	Document struct {
+		Name        string `xml:"name"`
+		Description string `xml:"description"`
+		Styles      []struct {
+			ID        string `xml:"id,attr"`
+			IconColor string `xml:"IconStyle>color"`
+			IconHref  string `xml:"IconStyle>Icon>href"`
+			LineColor string `xml:"LineStyle>color"`
+		} `xml:"Style"`
+		Placemarks []struct {
+			Name        string `xml:"name"`
+			Description string `xml:"description"`
+			StyleURL    string `xml:"styleUrl"`
+			Point       string `xml:"Point>coordinates"`
+			LineString  string `xml:"LineString>coordinates"`
+		} `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+func parseKML(t *testing.T, data []byte) parsedKML {
+	t.Helper()
+	var doc parsedKML
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	return doc
+}
+
+func TestKML(t *testing.T) {
+	course := testCourse()
+	course.Distance = 5
+	course.EstimatedTime = 30
+	course.Difficulty = "easy"
+	course.RouteShape = "loop"
+	course.Waypoints[1].Description = "皇居正門の石橋"
+	course.Waypoints[1].Verified = true
+
+	data, err := KML(course)
+	require.NoError(t, err)
+	doc := parseKML(t, data).Document
+
+	assert.Equal(t, course.Title, doc.Name)
+	assert.Equal(t, "桜田門から&lt;外周&gt;を一周します。<br><br>コースタイプ: ジョギング<br>距離: 5.0km<br>所要時間: 約30分<br>難易度: 初級<br>コース形状: 周回", doc.Description)
+
+	styles := map[string]string{}
+	for _, style := range doc.Styles {
+		styles[style.ID] = style.IconColor + style.LineColor
+		if style.IconHref != "" {
+			assert.Equal(t, kmlIconHref, style.IconHref)
+		}
+	}
+	assert.Equal(t, map[string]string{"start": "ff5ec522", "checkpoint": "fff6823b", "landmark": "ff0b9ef5", "end": "ff4444ef", "route": "ffeb6325"}, styles)
+
+	// The route first, without geometry through the waypoints
+	require.Len(t, doc.Placemarks, 4)
+	assert.Equal(t, "#route", doc.Placemarks[0].StyleURL)
+	assert.Equal(t, "139.7528,35.6778 139.754,35.68 139.7528,35.6778", doc.Placemarks[0].LineString)
+
+	landmark := doc.Placemarks[2]
+	assert.Equal(t, "二重橋", landmark.Name)
+	assert.Equal(t, "#landmark", landmark.StyleURL)
+	assert.Equal(t, "139.754,35.68", landmark.Point)
+	assert.Equal(t, "皇居正門の石橋<br><br>種類: ランドマーク<br>実在するスポットの位置です", landmark.Description)
+}
+
+func TestKML_Polyline(t *testing.T) {
+	course := testCourse()
+	line := []shared.Position{{Latitude: 35.6778, Longitude: 139.7528}, {Latitude: 35.679, Longitude: 139.751}, {Latitude: 35.68, Longitude: 139.754}}
+	encoded, err := polyline.Encode(line, polyline.DefaultPrecision)
+	require.NoError(t, err)
+	course.Polyline = &encoded
+
+	data, err := KML(course)
+	require.NoError(t, err)
+	assert.Equal(t, "139.7528,35.6778 139.751,35.679 139.754,35.68", parseKML(t, data).Document.Placemarks[0].LineString)
+}
+
+func TestKMZ(t *testing.T) {
+	data, err := KMZ(testCourse())
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 1+len(waypointTypes))
+	assert.Equal(t, "doc.kml", archive.File[0].Name)
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+	}
+
+	// Every icon referenced by the document is in the archive
+	for _, style := range parseKML(t, files["doc.kml"]).Document.Styles {
+		if style.IconHref == "" {
+			continue
+		}
+		assert.Empty(t, style.IconColor, "embedded icons are not tinted")
+		icon, ok := files[style.IconHref]
+		require.True(t, ok, style.IconHref)
+		img, err := png.Decode(bytes.NewReader(icon))
+		require.NoError(t, err)
+		assert.Equal(t, kmzIconSize, img.Bounds().Dx())
+	}
+}
@@ -0,0 +1,39 @@
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean radius of the Earth
+const EarthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance between two coordinates in km
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// project maps a coordinate to km on a plane tangent at refLat. It is
+// accurate enough for distances of a few tens of km.
+func project(lat, lng, refLat float64) (x, y float64) {
+	kmPerDegree := EarthRadiusKm * math.Pi / 180
+	return lng * kmPerDegree * math.Cos(refLat*math.Pi/180), lat * kmPerDegree
+}
+
+// segmentDistanceKm returns the distance in km from a point to the segment a-b
+func segmentDistanceKm(lat, lng, aLat, aLng, bLat, bLng float64) float64 {
+	px, py := project(lat, lng, lat)
+	ax, ay := project(aLat, aLng, lat)
+	bx, by := project(bLat, bLng, lat)
+
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSq))
+	}
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
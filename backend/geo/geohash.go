@@ -0,0 +1,86 @@
+package geo
+
+import "strings"
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash encodes a coordinate as a geohash of the given precision
+// (number of characters). Precision 6 is a cell of roughly 1.2km x 0.6km.
+func EncodeGeohash(latitude, longitude float64, precision int) string {
+	if precision <= 0 {
+		return ""
+	}
+
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	hash.Grow(precision)
+
+	bit, ch := 0, 0
+	evenBit := true
+	for hash.Len() < precision {
+		if evenBit {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if longitude >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch <<= 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if latitude >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch <<= 1
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// DecodeGeohash returns the center of the geohash cell
+func DecodeGeohash(hash string) (latitude, longitude float64) {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	evenBit := true
+	for i := 0; i < len(hash); i++ {
+		index := strings.IndexByte(geohashBase32, hash[i])
+		if index < 0 {
+			break
+		}
+		for shift := 4; shift >= 0; shift-- {
+			bitSet := index>>shift&1 == 1
+			if evenBit {
+				mid := (lngRange[0] + lngRange[1]) / 2
+				if bitSet {
+					lngRange[0] = mid
+				} else {
+					lngRange[1] = mid
+				}
+			} else {
+				mid := (latRange[0] + latRange[1]) / 2
+				if bitSet {
+					latRange[0] = mid
+				} else {
+					latRange[1] = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+
+	return (latRange[0] + latRange[1]) / 2, (lngRange[0] + lngRange[1]) / 2
+}
@@ -0,0 +1,52 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		precision int
+		expected  string
+	}{
+		{
+			name:      "reference vector",
+			latitude:  57.64911,
+			longitude: 10.40744,
+			precision: 11,
+			expected:  "u4pruydqqvj",
+		},
+		{
+			name:      "Tokyo Station",
+			latitude:  35.681236,
+			longitude: 139.767125,
+			precision: 6,
+			expected:  "xn76ur",
+		},
+		{
+			name:      "zero precision",
+			latitude:  35.0,
+			longitude: 139.0,
+			precision: 0,
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EncodeGeohash(tt.latitude, tt.longitude, tt.precision))
+		})
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	lat, lng := DecodeGeohash(EncodeGeohash(35.681236, 139.767125, 9))
+
+	assert.InDelta(t, 35.681236, lat, 0.0001)
+	assert.InDelta(t, 139.767125, lng, 0.0001)
+}
@@ -0,0 +1,80 @@
+package geo
+
+import "math"
+
+// gridCellDegrees is the size of the cells of a polygonGrid
+const gridCellDegrees = 0.25
+
+// polygonGrid buckets polygons by their bounding boxes in a regular grid so
+// that a point lookup only tests the few polygons near the point
+type polygonGrid struct {
+	entries []gridEntry
+	cells   map[[2]int][]int
+}
+
+// gridEntry is a polygon with its bounding box and the id of its owner
+type gridEntry struct {
+	owner   int
+	polygon Polygon
+	minLat  float64
+	minLng  float64
+	maxLat  float64
+	maxLng  float64
+}
+
+// add indexes a polygon for owner; polygons without an outer ring are ignored
+func (g *polygonGrid) add(owner int, polygon Polygon) {
+	if len(polygon) == 0 || len(polygon[0]) < 3 {
+		return
+	}
+	if g.cells == nil {
+		g.cells = make(map[[2]int][]int)
+	}
+
+	entry := gridEntry{
+		owner:   owner,
+		polygon: polygon,
+		minLat:  math.Inf(1),
+		minLng:  math.Inf(1),
+		maxLat:  math.Inf(-1),
+		maxLng:  math.Inf(-1),
+	}
+	for _, point := range polygon[0] {
+		entry.minLng = math.Min(entry.minLng, point[0])
+		entry.maxLng = math.Max(entry.maxLng, point[0])
+		entry.minLat = math.Min(entry.minLat, point[1])
+		entry.maxLat = math.Max(entry.maxLat, point[1])
+	}
+
+	id := len(g.entries)
+	g.entries = append(g.entries, entry)
+	minCell, maxCell := gridCell(entry.minLat, entry.minLng), gridCell(entry.maxLat, entry.maxLng)
+	for row := minCell[0]; row <= maxCell[0]; row++ {
+		for col := minCell[1]; col <= maxCell[1]; col++ {
+			key := [2]int{row, col}
+			g.cells[key] = append(g.cells[key], id)
+		}
+	}
+}
+
+// find returns the owner of the first polygon containing the coordinate
+func (g *polygonGrid) find(latitude, longitude float64) (int, bool) {
+	for _, id := range g.cells[gridCell(latitude, longitude)] {
+		entry := &g.entries[id]
+		if latitude < entry.minLat || latitude > entry.maxLat || longitude < entry.minLng || longitude > entry.maxLng {
+			continue
+		}
+		if entry.polygon.contains(latitude, longitude) {
+			return entry.owner, true
+		}
+	}
+	return 0, false
+}
+
+func (g *polygonGrid) empty() bool {
+	return len(g.entries) == 0
+}
+
+func gridCell(latitude, longitude float64) [2]int {
+	return [2]int{int(math.Floor(latitude / gridCellDegrees)), int(math.Floor(longitude / gridCellDegrees))}
+}
@@ -0,0 +1,245 @@
+// Command prefgen generates the prefecture boundaries embedded in the geo
+// package. Given the administrative area data (N03) of the National Land
+// Numerical Information as GeoJSON, it simplifies the municipal polygons and
+// groups them by prefecture:
+//
+//	go run ./internal/prefgen -n03 N03-20240101.geojson -o data/japan_prefectures.geojson
+//
+// Without it, each prefecture is the union of the Voronoi cells of its seed
+// points, computed on a Mercator plane and cut off at a fixed distance from
+// each seed so that the cells also cover the coast and small islands without
+// reaching neighbouring countries.
+//
+//	go run ./internal/prefgen -o data/japan_prefectures.geojson
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+
+	"potarin-backend/geo"
+)
+
+// kmPerDegree is the length of a degree of latitude, and of longitude at the
+// equator
+const kmPerDegree = geo.EarthRadiusKm * math.Pi / 180
+
+// circleSegments is the number of edges of the polygon approximating the
+// cut-off circle around a seed
+const circleSegments = 24
+
+type point struct{ x, y float64 }
+
+type site struct {
+	code int
+	seed seed
+	at   point
+}
+
+func main() {
+	output := flag.String("o", "data/japan_prefectures.geojson", "output GeoJSON file")
+	n03 := flag.String("n03", "", "administrative area GeoJSON (N03) to convert instead of the seeds")
+	tolerance := flag.Float64("tolerance", 0.001, "simplification tolerance in degrees for -n03")
+	radiusKm := flag.Float64("radius", 45, "maximum distance in km from a seed to its cell boundary")
+	flag.Parse()
+
+	var polygons map[int][][][][2]float64
+	if *n03 != "" {
+		var err error
+		if polygons, err = convertN03(*n03, *tolerance); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		polygons = seedCells(*radiusKm)
+	}
+
+	if err := write(*output, polygons); err != nil {
+		log.Fatal(err)
+	}
+	count := 0
+	for _, p := range polygons {
+		count += len(p)
+	}
+	log.Printf("wrote %d polygons of %d prefectures to %s", count, len(polygons), *output)
+}
+
+// seedCells returns the Voronoi cells of the seeds by prefecture code
+func seedCells(radiusKm float64) map[int][][][][2]float64 {
+	sites := collectSites()
+	cells := make(map[int][][][][2]float64)
+	for i := range sites {
+		cell := voronoiCell(sites, i, radiusKm)
+		if len(cell) < 3 {
+			continue
+		}
+		ring := make([][2]float64, 0, len(cell)+1)
+		for _, p := range cell {
+			ring = append(ring, toLngLat(p))
+		}
+		ring = append(ring, ring[0])
+		cells[sites[i].code] = append(cells[sites[i].code], [][][2]float64{ring})
+	}
+	return cells
+}
+
+// collectSites projects the seeds in a deterministic order and rejects
+// duplicates, which would leave a cell empty
+func collectSites() []site {
+	codes := make([]int, 0, len(seeds))
+	for code := range seeds {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	var sites []site
+	seen := make(map[point]string)
+	for _, code := range codes {
+		if _, ok := geo.PrefectureByCode(code); !ok {
+			log.Fatalf("unknown prefecture code %d", code)
+		}
+		for _, s := range seeds[code] {
+			at := mercator(s.latitude, s.longitude)
+			if other, ok := seen[at]; ok {
+				log.Fatalf("seed %s duplicates %s", s.name, other)
+			}
+			seen[at] = s.name
+			sites = append(sites, site{code: code, seed: s, at: at})
+		}
+	}
+	return sites
+}
+
+// voronoiCell clips a circle around site i by the bisectors with every site
+// close enough to cut it. Mercator is conformal, so distances are compared
+// correctly wherever two sites are close to each other.
+func voronoiCell(sites []site, i int, radiusKm float64) []point {
+	center := sites[i].at
+	radius := radiusKm / (kmPerDegree * math.Cos(sites[i].seed.latitude*math.Pi/180))
+
+	cell := make([]point, circleSegments)
+	for k := range cell {
+		angle := 2 * math.Pi * float64(k) / circleSegments
+		// Circumscribe the circle so that the polygon covers it
+		r := radius / math.Cos(math.Pi/circleSegments)
+		cell[k] = point{center.x + r*math.Cos(angle), center.y + r*math.Sin(angle)}
+	}
+
+	neighbours := make([]int, 0, 64)
+	for j := range sites {
+		if j != i && distance(center, sites[j].at) < 2.2*radius {
+			neighbours = append(neighbours, j)
+		}
+	}
+	sort.Slice(neighbours, func(a, b int) bool {
+		return distance(center, sites[neighbours[a]].at) < distance(center, sites[neighbours[b]].at)
+	})
+
+	for _, j := range neighbours {
+		other := sites[j].at
+		normal := point{other.x - center.x, other.y - center.y}
+		middle := point{(other.x + center.x) / 2, (other.y + center.y) / 2}
+		cell = clip(cell, normal, middle)
+		if len(cell) < 3 {
+			log.Fatalf("seed %s has an empty cell", sites[i].seed.name)
+		}
+	}
+	return cell
+}
+
+// clip keeps the part of a convex polygon on the side of the line through
+// middle opposite to normal
+func clip(polygon []point, normal, middle point) []point {
+	side := func(p point) float64 {
+		return (p.x-middle.x)*normal.x + (p.y-middle.y)*normal.y
+	}
+
+	var clipped []point
+	for k, current := range polygon {
+		previous := polygon[(k+len(polygon)-1)%len(polygon)]
+		currentSide, previousSide := side(current), side(previous)
+		if (currentSide <= 0) != (previousSide <= 0) {
+			t := previousSide / (previousSide - currentSide)
+			clipped = append(clipped, point{
+				previous.x + t*(current.x-previous.x),
+				previous.y + t*(current.y-previous.y),
+			})
+		}
+		if currentSide <= 0 {
+			clipped = append(clipped, current)
+		}
+	}
+	return clipped
+}
+
+func distance(a, b point) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+// mercator maps a coordinate to a plane where x is the longitude and y the
+// Mercator ordinate, both in degrees
+func mercator(latitude, longitude float64) point {
+	phi := latitude * math.Pi / 180
+	return point{longitude, math.Log(math.Tan(math.Pi/4+phi/2)) * 180 / math.Pi}
+}
+
+// toLngLat inverts mercator, rounding to about 10 m to keep the file small
+func toLngLat(p point) [2]float64 {
+	latitude := (2*math.Atan(math.Exp(p.y*math.Pi/180)) - math.Pi/2) * 180 / math.Pi
+	return [2]float64{round(p.x), round(latitude)}
+}
+
+func round(value float64) float64 {
+	return math.Round(value*1e4) / 1e4
+}
+
+type feature struct {
+	Type       string         `json:"type"`
+	Properties map[string]any `json:"properties"`
+	Geometry   multiPolygon   `json:"geometry"`
+}
+
+type multiPolygon struct {
+	Type        string           `json:"type"`
+	Coordinates [][][][2]float64 `json:"coordinates"`
+}
+
+// write stores one MultiPolygon feature per prefecture, one line each
+func write(path string, polygons map[int][][][][2]float64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := fmt.Fprintln(file, `{"type":"FeatureCollection","features":[`); err != nil {
+		return err
+	}
+	for _, prefecture := range geo.Prefectures() {
+		coordinates, ok := polygons[prefecture.Code]
+		if !ok {
+			return fmt.Errorf("no polygons for %s", prefecture.Name)
+		}
+		data, err := json.Marshal(feature{
+			Type:       "Feature",
+			Properties: map[string]any{"code": prefecture.Code, "name": prefecture.Name},
+			Geometry:   multiPolygon{Type: "MultiPolygon", Coordinates: coordinates},
+		})
+		if err != nil {
+			return err
+		}
+		separator := ","
+		if prefecture.Code == 47 {
+			separator = ""
+		}
+		if _, err := fmt.Fprintf(file, "%s%s\n", data, separator); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(file, "]}")
+	return err
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"potarin-backend/geo"
+)
+
+// n03NameProperty holds the prefecture name of each municipal feature
+const n03NameProperty = "N03_001"
+
+// convertN03 reads administrative area data and returns its polygons,
+// simplified with the given tolerance in degrees, by prefecture code.
+// Polygons too small to keep a ring of three vertices are dropped.
+func convertN03(path string, tolerance float64) (map[int][][][][2]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := geo.ParseGeoJSONRegions(data, n03NameProperty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	polygons := make(map[int][][][][2]float64)
+	for _, region := range regions {
+		prefecture, ok := geo.PrefectureByName(region.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown prefecture %q in %s", region.Name, path)
+		}
+		for _, polygon := range region.Polygons {
+			var rings [][][2]float64
+			for i, ring := range polygon {
+				simplified := simplify(ring, tolerance)
+				if simplified == nil {
+					if i == 0 {
+						break
+					}
+					continue
+				}
+				rings = append(rings, simplified)
+			}
+			if rings != nil {
+				polygons[prefecture.Code] = append(polygons[prefecture.Code], rings)
+			}
+		}
+	}
+	return polygons, nil
+}
+
+// simplify applies the Douglas-Peucker algorithm to a closed ring and rounds
+// its vertices like toLngLat. It returns nil when fewer than three distinct
+// vertices remain.
+func simplify(ring geo.Ring, tolerance float64) [][2]float64 {
+	last := len(ring) - 1
+	if last < 3 {
+		return nil
+	}
+	// The first and last vertices are the same, so the ring is split at the
+	// vertex farthest from them and each half is simplified on its own
+	far := 0
+	for i := range ring {
+		if math.Hypot(ring[i][0]-ring[0][0], ring[i][1]-ring[0][1]) > math.Hypot(ring[far][0]-ring[0][0], ring[far][1]-ring[0][1]) {
+			far = i
+		}
+	}
+	keep := make([]bool, len(ring))
+	keep[0], keep[far], keep[last] = true, true, true
+	douglasPeucker(ring, 0, far, tolerance, keep)
+	douglasPeucker(ring, far, last, tolerance, keep)
+
+	var simplified [][2]float64
+	for i, vertex := range ring {
+		if !keep[i] {
+			continue
+		}
+		vertex = [2]float64{round(vertex[0]), round(vertex[1])}
+		if n := len(simplified); n > 0 && simplified[n-1] == vertex {
+			continue
+		}
+		simplified = append(simplified, vertex)
+	}
+	if len(simplified) < 4 {
+		return nil
+	}
+	return simplified
+}
+
+// douglasPeucker marks the vertices between first and last to keep
+func douglasPeucker(ring geo.Ring, first, last int, tolerance float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+	farthest, maxDistance := 0, 0.0
+	for i := first + 1; i < last; i++ {
+		if d := segmentDistance(ring[i], ring[first], ring[last]); d > maxDistance {
+			farthest, maxDistance = i, d
+		}
+	}
+	if maxDistance <= tolerance {
+		return
+	}
+	keep[farthest] = true
+	douglasPeucker(ring, first, farthest, tolerance, keep)
+	douglasPeucker(ring, farthest, last, tolerance, keep)
+}
+
+// segmentDistance returns the distance in degrees from p to the segment ab
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
@@ -0,0 +1,708 @@
+package main
+
+// seed is a point known to lie in a prefecture, usually a municipal office
+type seed struct {
+	name      string
+	latitude  float64
+	longitude float64
+}
+
+// seeds lists points of every prefecture by JIS X 0401 code. Municipalities
+// near prefectural borders and in sparsely populated areas are included so
+// that the Voronoi cells follow the borders and cover the land.
+var seeds = map[int][]seed{
+	1: { // 北海道
+		{"Sapporo", 43.062, 141.354}, {"Hakodate", 41.769, 140.729}, {"Asahikawa", 43.771, 142.365},
+		{"Kushiro", 42.985, 144.381}, {"Obihiro", 42.924, 143.196}, {"Kitami", 43.804, 143.894},
+		{"Wakkanai", 45.415, 141.673}, {"Nemuro", 43.330, 145.583}, {"Muroran", 42.315, 140.974},
+		{"Tomakomai", 42.634, 141.605}, {"Otaru", 43.190, 140.994}, {"Abashiri", 44.021, 144.273},
+		{"Rumoi", 43.941, 141.637}, {"Nayoro", 44.356, 142.463}, {"Esashi (Hiyama)", 41.869, 140.127},
+		{"Matsumae", 41.430, 140.110}, {"Urakawa", 42.168, 142.768}, {"Shari", 43.911, 144.667},
+		{"Rausu", 44.020, 145.190}, {"Monbetsu", 44.357, 143.354}, {"Furano", 43.342, 142.383},
+		{"Takikawa", 43.558, 141.906}, {"Iwamizawa", 43.196, 141.776}, {"Kutchan", 42.902, 140.759},
+		{"Esashi (Soya)", 44.940, 142.580}, {"Erimo", 42.020, 143.150}, {"Yakumo", 42.254, 140.270},
+		{"Setana", 42.450, 139.850}, {"Rishiri", 45.180, 141.240}, {"Okushiri", 42.170, 139.510},
+		{"Haboro", 44.360, 141.700}, {"Kamikawa", 43.850, 142.770}, {"Hidaka", 42.880, 142.440},
+		{"Shimukappu", 43.060, 142.630}, {"Ashoro", 43.240, 143.550}, {"Teshikaga", 43.490, 144.460},
+		{"Nakashibetsu", 43.550, 144.970}, {"Shiranuka", 42.960, 144.080}, {"Hiroo", 42.290, 143.320},
+		{"Biei", 43.590, 142.470}, {"Horokanai", 44.010, 142.160}, {"Nakatonbetsu", 44.970, 142.290},
+		{"Hamatonbetsu", 45.120, 142.360}, {"Teshio", 44.890, 141.750}, {"Toyotomi", 45.100, 141.780},
+		{"Engaru", 44.050, 143.530}, {"Oketo", 43.680, 143.590}, {"Rikubetsu", 43.470, 143.740},
+		{"Tsurui", 43.230, 144.320}, {"Shibecha", 43.300, 144.600}, {"Akkeshi", 43.040, 144.850},
+		{"Chitose", 42.821, 141.651}, {"Mukawa", 42.570, 141.930}, {"Date", 42.470, 140.860},
+		{"Rankoshi", 42.810, 140.530}, {"Shakotan", 43.300, 140.600}, {"Mashike", 43.850, 141.520},
+		{"Shintotsukawa", 43.550, 141.900}, {"Ishikari", 43.240, 141.320}, {"Hamamasu", 43.590, 141.390},
+		{"Oshamambe", 42.510, 140.380}, {"Mori", 42.110, 140.570}, {"Esan", 41.810, 141.110},
+		{"Otofuke", 43.000, 143.200}, {"Shikaoi", 43.100, 142.990}, {"Taiki", 42.500, 143.280},
+		{"Samani", 42.130, 142.930}, {"Niikappu", 42.370, 142.310}, {"Kamishihoro", 43.230, 143.300},
+		{"Nishiokoppe", 44.330, 142.950}, {"Bifuka", 44.490, 142.340}, {"Otoineppu", 44.720, 142.270},
+		{"Horonobe", 45.010, 141.850}, {"Shosanbetsu", 44.530, 141.770}, {"Tomamae", 44.310, 141.660},
+		{"Yubari", 43.060, 141.970}, {"Rebun", 45.300, 141.040}, {"Yagishiri", 44.430, 141.420},
+		{"Saroma", 44.020, 143.780}, {"Shiretoko", 44.200, 145.200}, {"Notsuke", 43.600, 145.280},
+		{"Hamanaka", 43.080, 145.130}, {"Kiritappu", 43.060, 145.480},
+		{"Okoppe", 44.470, 143.120}, {"Omu", 44.580, 142.960}, {"Toyokoro", 42.800, 143.530},
+		{"Urahoro", 42.810, 143.660}, {"Atsuma", 42.720, 141.880},
+	},
+	2: { // 青森県
+		{"Aomori", 40.822, 140.747}, {"Hirosaki", 40.603, 140.464}, {"Hachinohe", 40.512, 141.488},
+		{"Mutsu", 41.293, 141.183}, {"Goshogawara", 40.808, 140.440}, {"Towada", 40.613, 141.206},
+		{"Sannohe", 40.380, 141.260}, {"Ajigasawa", 40.780, 140.210}, {"Fukaura", 40.650, 139.930},
+		{"Oma", 41.530, 140.910}, {"Misawa", 40.683, 141.369}, {"Kuroishi", 40.640, 140.590},
+		{"Sai", 41.430, 140.860}, {"Rokkasho", 40.970, 141.370}, {"Nakadomari", 41.000, 140.440},
+		{"Imabetsu", 41.180, 140.480}, {"Towada Lake", 40.460, 140.900}, {"Nishimeya", 40.560, 140.300},
+		{"Shingo", 40.460, 141.170}, {"Nanbu", 40.430, 141.400}, {"Hashikami", 40.450, 141.630},
+		{"Noheji", 40.860, 141.130}, {"Yokohama (Aomori)", 41.080, 141.250}, {"Higashidori", 41.280, 141.330},
+		{"Iwasaki", 40.580, 139.930},
+	},
+	3: { // 岩手県
+		{"Morioka", 39.702, 141.154}, {"Miyako", 39.641, 141.957}, {"Kamaishi", 39.276, 141.886},
+		{"Ofunato", 39.082, 141.708}, {"Rikuzentakata", 39.020, 141.630}, {"Ichinoseki", 38.934, 141.127},
+		{"Oshu", 39.144, 141.139}, {"Kitakami", 39.287, 141.113}, {"Hanamaki", 39.389, 141.117},
+		{"Tono", 39.328, 141.533}, {"Kuji", 40.191, 141.775}, {"Ninohe", 40.271, 141.305},
+		{"Hachimantai", 39.956, 141.071}, {"Iwaizumi", 39.840, 141.800}, {"Nishiwaga", 39.320, 140.780},
+		{"Karumai", 40.320, 141.460}, {"Shizukuishi", 39.690, 140.980}, {"Hiraizumi", 38.990, 141.110},
+		{"Fujisawa (Iwate)", 38.860, 141.350}, {"Kuzumaki", 40.040, 141.440}, {"Ichinohe", 40.210, 141.300},
+		{"Hirono", 40.300, 141.780}, {"Noda (Iwate)", 40.110, 141.820}, {"Tanohata", 39.930, 141.940},
+		{"Yamada", 39.470, 141.950}, {"Otsuchi", 39.360, 141.900}, {"Sumita", 39.140, 141.590},
+		{"Kawai", 39.590, 141.550}, {"Hachimantai Ashiro", 40.100, 141.000}, {"Kanegasaki", 39.200, 141.120},
+		{"Murone", 38.960, 141.450}, {"Ichinoseki Maikawa", 38.920, 141.000}, {"Iwate", 39.970, 141.210},
+	},
+	4: { // 宮城県
+		{"Sendai", 38.268, 140.872}, {"Ishinomaki", 38.434, 141.303}, {"Kesennuma", 38.908, 141.570},
+		{"Osaki", 38.577, 140.956}, {"Kurihara", 38.730, 141.021}, {"Tome", 38.692, 141.188},
+		{"Shiroishi", 38.002, 140.620}, {"Natori", 38.172, 140.892}, {"Watari", 38.040, 140.850},
+		{"Kakuda", 37.977, 140.782}, {"Marumori", 37.910, 140.770}, {"Shichikashuku", 38.000, 140.440},
+		{"Zao", 38.100, 140.660}, {"Kawasaki (Miyagi)", 38.180, 140.640}, {"Naruko", 38.740, 140.720},
+		{"Minamisanriku", 38.680, 141.450}, {"Onagawa", 38.440, 141.440}, {"Shiogama", 38.314, 141.022},
+		{"Oshika", 38.300, 141.500}, {"Sendai Akiu", 38.270, 140.650}, {"Sendai Izumi", 38.320, 140.880},
+		{"Taiwa", 38.440, 140.890}, {"Kami", 38.570, 140.850}, {"Kurihara Hanayama", 38.820, 140.860},
+		{"Yamamoto", 37.960, 140.880}, {"Hasama", 38.800, 141.160},
+	},
+	5: { // 秋田県
+		{"Akita", 39.720, 140.102}, {"Noshiro", 40.212, 140.027}, {"Odate", 40.272, 140.564},
+		{"Kazuno", 40.215, 140.788}, {"Yokote", 39.311, 140.553}, {"Yuzawa", 39.164, 140.496},
+		{"Daisen", 39.453, 140.475}, {"Yurihonjo", 39.386, 140.049}, {"Nikaho", 39.200, 139.910},
+		{"Kitaakita", 40.226, 140.371}, {"Oga", 39.887, 139.848}, {"Semboku", 39.600, 140.560},
+		{"Tazawako", 39.700, 140.730}, {"Happo", 40.340, 140.040}, {"Kosaka", 40.330, 140.740},
+		{"Higashinaruse", 39.170, 140.660}, {"Katagami", 39.880, 140.050}, {"Fujisato", 40.410, 140.330},
+		{"Kamikoani", 40.050, 140.370}, {"Gojome", 39.940, 140.110}, {"Kitaakita Ani", 40.000, 140.450},
+		{"Yurihonjo Chokai", 39.200, 140.150}, {"Ugo", 39.200, 140.410}, {"Misato (Akita)", 39.420, 140.570},
+		{"Nishiki", 39.800, 140.620}, {"Kazuno Hachimantai", 40.050, 140.830},
+	},
+	6: { // 山形県
+		{"Yamagata", 38.240, 140.363}, {"Sakata", 38.914, 139.837}, {"Tsuruoka", 38.727, 139.827},
+		{"Shinjo", 38.765, 140.301}, {"Yonezawa", 37.922, 140.117}, {"Nagai", 38.107, 140.041},
+		{"Tendo", 38.362, 140.378}, {"Murayama", 38.483, 140.380}, {"Obanazawa", 38.600, 140.410},
+		{"Mogami", 38.760, 140.520}, {"Mamurogawa", 38.860, 140.250}, {"Yuza", 39.010, 139.910},
+		{"Oguni", 38.060, 139.740}, {"Nishikawa", 38.430, 140.150}, {"Shonai", 38.850, 139.920},
+		{"Atsumi", 38.620, 139.600}, {"Asahi", 38.350, 139.940}, {"Takahata", 38.000, 140.190},
+		{"Kaminoyama", 38.154, 140.278}, {"Iide", 38.040, 139.990}, {"Tsuruoka Asahi", 38.580, 139.850},
+		{"Sakata Yawata", 38.990, 140.000}, {"Kaneyama", 38.880, 140.340}, {"Higashine", 38.431, 140.391},
+		{"Oe", 38.380, 140.200}, {"Ohkura", 38.700, 140.230}, {"Yamagata Zao", 38.170, 140.400},
+	},
+	7: { // 福島県
+		{"Fukushima", 37.750, 140.468}, {"Koriyama", 37.400, 140.360}, {"Iwaki", 37.050, 140.888},
+		{"Aizuwakamatsu", 37.495, 139.930}, {"Shirakawa", 37.126, 140.211}, {"Sukagawa", 37.286, 140.373},
+		{"Minamisoma", 37.642, 140.957}, {"Soma", 37.797, 140.920}, {"Kitakata", 37.651, 139.875},
+		{"Nihonmatsu", 37.585, 140.431}, {"Date", 37.819, 140.563}, {"Tamura", 37.440, 140.580},
+		{"Kawamata", 37.660, 140.600}, {"Namie", 37.490, 141.000}, {"Tomioka", 37.340, 141.010},
+		{"Minamiaizu", 37.200, 139.770}, {"Tadami", 37.350, 139.320}, {"Hinoemata", 37.020, 139.390},
+		{"Shinchi", 37.870, 140.920}, {"Tanagura", 37.030, 140.380}, {"Yamatsuri", 36.870, 140.430},
+		{"Nishigo", 37.140, 140.160}, {"Inawashiro", 37.560, 140.110}, {"Kitashiobara", 37.660, 139.960},
+		{"Nishiaizu", 37.590, 139.650}, {"Iitate", 37.680, 140.730}, {"Kawauchi", 37.340, 140.810},
+		{"Iwaki Tono", 37.030, 140.620}, {"Iwaki Kawamae", 37.230, 140.750}, {"Hanawa", 36.960, 140.410},
+		{"Ten-ei", 37.270, 140.050}, {"Shimogo", 37.260, 139.870}, {"Kaneyama (Fukushima)", 37.460, 139.520},
+		{"Minamiaizu Ina", 37.080, 139.580}, {"Fukushima Tsuchiyu", 37.660, 140.270},
+		{"Kitakata Atsushio", 37.690, 139.800}, {"Koriyama Konan", 37.440, 140.120},
+	},
+	8: { // 茨城県
+		{"Mito", 36.366, 140.471}, {"Tsukuba", 36.083, 140.077}, {"Hitachi", 36.599, 140.651},
+		{"Tsuchiura", 36.078, 140.204}, {"Kashima", 35.966, 140.645}, {"Kamisu", 35.890, 140.665},
+		{"Koga", 36.178, 139.755}, {"Toride", 35.911, 140.050}, {"Ryugasaki", 35.911, 140.182},
+		{"Chikusei", 36.307, 139.983}, {"Kasama", 36.345, 140.304}, {"Hitachiota", 36.538, 140.531},
+		{"Kitaibaraki", 36.802, 140.751}, {"Daigo", 36.770, 140.350}, {"Hitachiomiya", 36.540, 140.410},
+		{"Itako", 35.947, 140.555}, {"Bando", 36.048, 139.889}, {"Joso", 36.023, 139.994},
+		{"Sakai (Ibaraki)", 36.100, 139.800}, {"Goka", 36.110, 139.720}, {"Tone", 35.860, 140.140},
+		{"Inashiki", 35.960, 140.320}, {"Hokota", 36.160, 140.520}, {"Sakuragawa", 36.330, 140.090},
+		{"Yuki", 36.305, 139.877}, {"Moriya", 35.951, 139.975}, {"Kawachi", 35.880, 140.240},
+		{"Takahagi", 36.719, 140.716}, {"Kasumigaura", 36.150, 140.240}, {"Hitachinaka", 36.397, 140.535},
+		{"Daigo Ashikura", 36.860, 140.390}, {"Kitaibaraki Sekimoto", 36.880, 140.620},
+		{"Kasama Iwama", 36.300, 140.290}, {"Chikusei Kyowa", 36.300, 140.040},
+	},
+	9: { // 栃木県
+		{"Utsunomiya", 36.555, 139.883}, {"Oyama", 36.315, 139.800}, {"Tochigi", 36.382, 139.730},
+		{"Ashikaga", 36.340, 139.450}, {"Sano", 36.314, 139.578}, {"Kanuma", 36.567, 139.745},
+		{"Nikko", 36.720, 139.698}, {"Nasushiobara", 36.962, 140.046}, {"Otawara", 36.871, 140.016},
+		{"Moka", 36.434, 140.013}, {"Yaita", 36.807, 139.924}, {"Nasu", 37.020, 140.120},
+		{"Nasukarasuyama", 36.657, 140.151}, {"Motegi", 36.530, 140.190}, {"Mashiko", 36.470, 140.090},
+		{"Nogi", 36.230, 139.740}, {"Shioya", 36.780, 139.850}, {"Kuriyama", 36.830, 139.580},
+		{"Ashio", 36.640, 139.450}, {"Iwafune", 36.320, 139.650}, {"Nakagawa", 36.740, 140.170},
+		{"Kawaji", 36.880, 139.700}, {"Yunishigawa", 36.930, 139.590}, {"Shiobara", 36.960, 139.830},
+		{"Nasu Mountains", 37.120, 139.970}, {"Sano Kuzu", 36.440, 139.530}, {"Ashikaga Kita", 36.420, 139.440},
+		{"Nikko Kirifuri", 36.790, 139.620},
+	},
+	10: { // 群馬県
+		{"Maebashi", 36.391, 139.060}, {"Takasaki", 36.322, 139.003}, {"Kiryu", 36.405, 139.331},
+		{"Isesaki", 36.311, 139.197}, {"Ota", 36.291, 139.375}, {"Numata", 36.646, 139.044},
+		{"Tatebayashi", 36.245, 139.542}, {"Shibukawa", 36.489, 139.000}, {"Fujioka", 36.259, 139.074},
+		{"Tomioka", 36.260, 138.890}, {"Annaka", 36.326, 138.887}, {"Minakami", 36.678, 138.999},
+		{"Naganohara", 36.550, 138.640}, {"Tsumagoi", 36.500, 138.520}, {"Kusatsu", 36.620, 138.600},
+		{"Nakanojo", 36.590, 138.840}, {"Katashina", 36.780, 139.230}, {"Ueno", 36.080, 138.780},
+		{"Kanna", 36.130, 138.970}, {"Shimonita", 36.210, 138.790}, {"Kanra", 36.240, 138.920},
+		{"Itakura", 36.220, 139.610}, {"Oizumi", 36.250, 139.410}, {"Chiyoda", 36.220, 139.440},
+		{"Meiwa", 36.210, 139.530}, {"Minakami Fujiwara", 36.850, 139.060}, {"Oze", 36.900, 139.250},
+		{"Kiryu Kurohone", 36.520, 139.360}, {"Nanmoku", 36.160, 138.710}, {"Midori", 36.500, 139.280},
+		{"Kusatsu Shirane", 36.700, 138.520}, {"Annaka Matsuida", 36.320, 138.800},
+		{"Kawaba", 36.700, 139.110}, {"Takayama (Gunma)", 36.620, 138.940},
+	},
+	11: { // 埼玉県
+		{"Saitama", 35.861, 139.645}, {"Kawaguchi", 35.807, 139.724}, {"Kawagoe", 35.925, 139.486},
+		{"Tokorozawa", 35.799, 139.469}, {"Koshigaya", 35.891, 139.791}, {"Kasukabe", 35.976, 139.752},
+		{"Kumagaya", 36.147, 139.389}, {"Chichibu", 35.992, 139.085}, {"Honjo", 36.244, 139.190},
+		{"Hanno", 35.856, 139.328}, {"Iruma", 35.836, 139.391}, {"Soka", 35.825, 139.806},
+		{"Misato", 35.830, 139.872}, {"Yashio", 35.823, 139.839}, {"Kuki", 36.062, 139.667},
+		{"Kazo", 36.131, 139.602}, {"Gyoda", 36.139, 139.456}, {"Fukaya", 36.197, 139.282},
+		{"Higashimatsuyama", 36.042, 139.400}, {"Sakado", 35.957, 139.403}, {"Asaka", 35.797, 139.593},
+		{"Wako", 35.781, 139.606}, {"Niiza", 35.794, 139.565}, {"Toda", 35.817, 139.678},
+		{"Warabi", 35.825, 139.680}, {"Yoshikawa", 35.894, 139.843}, {"Satte", 36.078, 139.726},
+		{"Kamisato", 36.250, 139.140}, {"Ogano", 36.020, 139.010}, {"Yokoze", 35.980, 139.100},
+		{"Chichibu Otaki", 35.950, 138.900}, {"Minano", 36.070, 139.100}, {"Kamikawa", 36.220, 139.100},
+		{"Hanyu", 36.173, 139.548}, {"Kitamoto", 36.030, 139.530}, {"Okegawa", 36.000, 139.560},
+		{"Ageo", 35.977, 139.593}, {"Fujimi", 35.857, 139.549}, {"Sayama", 35.853, 139.412},
+		{"Hidaka", 35.908, 139.339}, {"Tokigawa", 36.010, 139.300}, {"Misato (Kodama)", 36.170, 139.180},
+		{"Sugito", 36.030, 139.740}, {"Matsubushi", 35.930, 139.820}, {"Hanno Naguri", 35.900, 139.180},
+		{"Chichibu Urayama", 35.930, 139.040}, {"Kazo Kitakawabe", 36.190, 139.650},
+		{"Miyoshi", 35.830, 139.530}, {"Tokorozawa Sayama Lake", 35.770, 139.420},
+	},
+	12: { // 千葉県
+		{"Chiba", 35.607, 140.106}, {"Funabashi", 35.695, 139.983}, {"Matsudo", 35.788, 139.903},
+		{"Kashiwa", 35.868, 139.976}, {"Ichikawa", 35.722, 139.931}, {"Urayasu", 35.653, 139.902},
+		{"Narita", 35.777, 140.318}, {"Sakura", 35.724, 140.224}, {"Kisarazu", 35.381, 139.925},
+		{"Kimitsu", 35.330, 139.903}, {"Futtsu", 35.304, 139.857}, {"Tateyama", 34.996, 139.870},
+		{"Kamogawa", 35.114, 140.099}, {"Minamiboso", 34.980, 139.980}, {"Katsuura", 35.152, 140.321},
+		{"Isumi", 35.254, 140.385}, {"Mobara", 35.428, 140.288}, {"Togane", 35.560, 140.366},
+		{"Choshi", 35.735, 140.827}, {"Asahi", 35.721, 140.647}, {"Katori", 35.898, 140.499},
+		{"Noda", 35.955, 139.875}, {"Nagareyama", 35.856, 139.903}, {"Abiko", 35.864, 140.028},
+		{"Inzai", 35.832, 140.145}, {"Yachiyo", 35.723, 140.100}, {"Ichihara", 35.498, 140.115},
+		{"Sodegaura", 35.430, 139.954}, {"Otaki", 35.285, 140.245}, {"Kyonan", 35.110, 139.840},
+		{"Sosa", 35.707, 140.564}, {"Tonosho", 35.830, 140.670}, {"Kozaki", 35.900, 140.400},
+		{"Noda Sekiyado", 36.090, 139.780}, {"Sakae", 35.840, 140.240}, {"Kujukuri", 35.540, 140.440},
+		{"Ichinomiya", 35.370, 140.370}, {"Kimitsu Kazusa", 35.240, 140.030}, {"Sanmu", 35.630, 140.410},
+		{"Katori Sawara", 35.890, 140.500}, {"Chiba Makuhari", 35.650, 140.040},
+		{"Urayasu Maihama", 35.634, 139.886}, {"Urayasu Nekozane", 35.667, 139.897},
+		{"Ichikawa Konodai", 35.742, 139.906},
+	},
+	13: { // 東京都
+		{"Chiyoda", 35.694, 139.754}, {"Shinjuku", 35.694, 139.703}, {"Setagaya", 35.646, 139.653},
+		{"Nerima", 35.736, 139.652}, {"Itabashi", 35.751, 139.709}, {"Kita", 35.753, 139.734},
+		{"Adachi", 35.775, 139.805}, {"Katsushika", 35.743, 139.847}, {"Edogawa", 35.707, 139.868},
+		{"Koto", 35.673, 139.817}, {"Ota", 35.561, 139.716}, {"Shinagawa", 35.609, 139.730},
+		{"Suginami", 35.700, 139.636}, {"Hachioji", 35.666, 139.316}, {"Tachikawa", 35.714, 139.408},
+		{"Musashino", 35.718, 139.566}, {"Mitaka", 35.684, 139.560}, {"Fuchu", 35.669, 139.478},
+		{"Chofu", 35.652, 139.541}, {"Machida", 35.547, 139.439}, {"Tama", 35.637, 139.446},
+		{"Hino", 35.671, 139.395}, {"Kodaira", 35.729, 139.477}, {"Higashimurayama", 35.755, 139.468},
+		{"Kiyose", 35.786, 139.526}, {"Higashikurume", 35.758, 139.530}, {"Nishitokyo", 35.726, 139.538},
+		{"Akishima", 35.706, 139.353}, {"Fussa", 35.738, 139.327}, {"Hamura", 35.767, 139.311},
+		{"Mizuho", 35.772, 139.354}, {"Musashimurayama", 35.755, 139.387}, {"Higashiyamato", 35.746, 139.427},
+		{"Ome", 35.788, 139.276}, {"Akiruno", 35.729, 139.294}, {"Hinode", 35.742, 139.257},
+		{"Okutama", 35.809, 139.096}, {"Hinohara", 35.727, 139.148}, {"Inagi", 35.638, 139.504},
+		{"Komae", 35.635, 139.579}, {"Izu Oshima", 34.750, 139.356}, {"Niijima", 34.377, 139.257},
+		{"Miyakejima", 34.083, 139.525}, {"Hachijojima", 33.110, 139.790}, {"Chichijima", 27.094, 142.192},
+		{"Hahajima", 26.640, 142.160}, {"Kozushima", 34.210, 139.140}, {"Mikurajima", 33.890, 139.600},
+		{"Aogashima", 32.460, 139.760}, {"Odaiba", 35.627, 139.776}, {"Okutama Nippara", 35.850, 139.040},
+		{"Ome Nariki", 35.830, 139.220}, {"Hachioji Takao", 35.630, 139.240}, {"Machida Tsurukawa", 35.580, 139.477},
+		{"Machida Aihara", 35.600, 139.330}, {"Hachioji Minamiosawa", 35.610, 139.380},
+		{"Okutama Kumotori", 35.850, 138.950}, {"Hinohara Kazahari", 35.710, 139.070},
+		{"Setagaya Todoroki", 35.607, 139.650}, {"Ota Denenchofu", 35.590, 139.670},
+		{"Adachi Toneri", 35.800, 139.770}, {"Katsushika Mizumoto", 35.785, 139.865},
+		{"Katsushika Shibamata", 35.757, 139.873}, {"Edogawa Koiwa", 35.733, 139.880},
+		{"Edogawa Ichinoe", 35.686, 139.883}, {"Edogawa Nishikasai", 35.665, 139.859},
+		{"Edogawa Kasai Rinkai", 35.641, 139.861},
+	},
+	14: { // 神奈川県
+		{"Yokohama", 35.444, 139.638}, {"Kawasaki", 35.531, 139.703}, {"Sagamihara", 35.571, 139.373},
+		{"Yokosuka", 35.281, 139.672}, {"Fujisawa", 35.339, 139.490}, {"Kamakura", 35.319, 139.547},
+		{"Chigasaki", 35.334, 139.404}, {"Hiratsuka", 35.335, 139.350}, {"Odawara", 35.265, 139.152},
+		{"Hakone", 35.233, 139.107}, {"Yugawara", 35.148, 139.108}, {"Atsugi", 35.443, 139.362},
+		{"Yamato", 35.487, 139.458}, {"Ebina", 35.446, 139.391}, {"Zama", 35.488, 139.408},
+		{"Isehara", 35.403, 139.315}, {"Hadano", 35.375, 139.220}, {"Miura", 35.144, 139.620},
+		{"Zushi", 35.296, 139.578}, {"Ayase", 35.437, 139.426}, {"Aikawa", 35.528, 139.252},
+		{"Kiyokawa", 35.480, 139.220}, {"Yamakita", 35.361, 139.084}, {"Minamiashigara", 35.320, 139.100},
+		{"Hashimoto", 35.595, 139.345}, {"Sagamiko", 35.610, 139.190}, {"Tsukui", 35.570, 139.260},
+		{"Asao", 35.600, 139.495}, {"Aoba", 35.553, 139.537}, {"Tsuzuki", 35.545, 139.571},
+		{"Kohoku", 35.519, 139.633}, {"Tama-ku", 35.619, 139.562}, {"Totsuka", 35.401, 139.535},
+		{"Kanazawa-ku", 35.337, 139.624}, {"Fujino", 35.620, 139.140}, {"Sagamihara Midori", 35.610, 139.280},
+		{"Sagamihara Aoneri", 35.570, 139.100}, {"Yamakita Tanzawa", 35.420, 139.050},
+		{"Kawasaki Miyamae", 35.587, 139.580}, {"Kawasaki Nakahara", 35.576, 139.660},
+		{"Machida border (Sagamihara)", 35.535, 139.410}, {"Yamato Tsuruma", 35.522, 139.460},
+		{"Asao Kakio", 35.599, 139.486},
+	},
+	15: { // 新潟県
+		{"Niigata", 37.916, 139.036}, {"Nagaoka", 37.447, 138.851}, {"Joetsu", 37.148, 138.236},
+		{"Sanjo", 37.637, 138.962}, {"Kashiwazaki", 37.372, 138.559}, {"Shibata", 37.948, 139.327},
+		{"Murakami", 38.224, 139.480}, {"Tokamachi", 37.128, 138.758}, {"Minamiuonuma", 37.066, 138.876},
+		{"Uonuma", 37.230, 138.962}, {"Myoko", 37.025, 138.254}, {"Itoigawa", 37.039, 137.863},
+		{"Agano", 37.834, 139.226}, {"Gosen", 37.744, 139.183}, {"Tsubame", 37.673, 138.882},
+		{"Kamo", 37.666, 139.040}, {"Sado Ryotsu", 38.080, 138.440}, {"Sado Mano", 38.000, 138.360},
+		{"Sado North", 38.300, 138.470}, {"Aga", 37.760, 139.460}, {"Sekikawa", 38.090, 139.570},
+		{"Yuzawa", 36.930, 138.810}, {"Tsunan", 37.010, 138.650}, {"Awashima", 38.460, 139.230},
+		{"Tainai", 38.060, 139.410}, {"Sanpoku", 38.440, 139.550}, {"Ojiya", 37.314, 138.795},
+		{"Mitsuke", 37.530, 138.910}, {"Tagami", 37.700, 139.060}, {"Itoigawa Omi", 36.960, 137.730},
+		{"Itoigawa Otari border", 36.850, 137.880}, {"Joetsu Yasuzuka", 37.100, 138.440},
+		{"Myoko Sasagamine", 36.900, 138.150}, {"Uonuma Okutadami", 37.150, 139.150},
+		{"Aga Tsugawa", 37.680, 139.450}, {"Aga Kanose", 37.680, 139.620}, {"Murakami Asahi", 38.300, 139.650},
+		{"Tsunan Akiyamago", 36.880, 138.620}, {"Shibata Akadani", 37.900, 139.500},
+		{"Minamiuonuma Muikamachi", 37.060, 138.880}, {"Itoigawa Nou", 37.090, 138.000},
+	},
+	16: { // 富山県
+		{"Toyama", 36.696, 137.214}, {"Takaoka", 36.754, 137.026}, {"Uozu", 36.827, 137.409},
+		{"Himi", 36.856, 136.973}, {"Namerikawa", 36.765, 137.340}, {"Kurobe", 36.872, 137.447},
+		{"Tonami", 36.648, 136.962}, {"Oyabe", 36.676, 136.869}, {"Nanto", 36.559, 136.875},
+		{"Imizu", 36.731, 137.075}, {"Asahi", 36.950, 137.560}, {"Tateyama", 36.660, 137.310},
+		{"Kamiichi", 36.700, 137.360}, {"Gokayama", 36.420, 136.930}, {"Yatsuo", 36.560, 137.130},
+		{"Osawano", 36.620, 137.230}, {"Kurobe Dam", 36.570, 137.660}, {"Toyama Hosoiri", 36.490, 137.260},
+		{"Unazuki", 36.810, 137.560}, {"Asahi Ogawa", 36.880, 137.660}, {"Tateyama Murodo", 36.580, 137.600},
+		{"Nanto Taira", 36.360, 136.880}, {"Toyama Arimine", 36.490, 137.440}, {"Himi Hiroshima", 36.900, 136.870},
+	},
+	17: { // 石川県
+		{"Kanazawa", 36.561, 136.656}, {"Komatsu", 36.408, 136.445}, {"Kaga", 36.302, 136.315},
+		{"Hakusan", 36.514, 136.565}, {"Nanao", 37.043, 136.967}, {"Wajima", 37.390, 136.899},
+		{"Suzu", 37.437, 137.261}, {"Hakui", 36.893, 136.780}, {"Nonoichi", 36.519, 136.610},
+		{"Kahoku", 36.720, 136.707}, {"Anamizu", 37.231, 136.911}, {"Noto", 37.310, 137.150},
+		{"Shika", 37.020, 136.780}, {"Hodatsushimizu", 36.860, 136.800}, {"Tsubata", 36.670, 136.730},
+		{"Shiramine", 36.170, 136.620}, {"Torigoe", 36.280, 136.630}, {"Nakanoto", 36.990, 136.900},
+		{"Kanazawa Yuwaku", 36.490, 136.750}, {"Kaga Yamanaka", 36.240, 136.370}, {"Komatsu Shiramine", 36.300, 136.530},
+		{"Wajima Monzen", 37.290, 136.770}, {"Nanao Notojima", 37.120, 136.980},
+	},
+	18: { // 福井県
+		{"Fukui", 36.065, 136.222}, {"Sakai", 36.167, 136.232}, {"Sabae", 35.956, 136.184},
+		{"Echizen", 35.903, 136.169}, {"Tsuruga", 35.645, 136.055}, {"Obama", 35.496, 135.747},
+		{"Ono", 35.980, 136.487}, {"Katsuyama", 36.061, 136.500}, {"Awara", 36.211, 136.229},
+		{"Takahama", 35.490, 135.550}, {"Oi", 35.480, 135.620}, {"Wakasa", 35.550, 135.910},
+		{"Mihama", 35.600, 135.940}, {"Minamiechizen", 35.840, 136.190}, {"Ikeda", 35.890, 136.340},
+		{"Echizen-cho", 35.970, 136.030}, {"Ono Izumi", 35.850, 136.660}, {"Katsuyama Kitadani", 36.150, 136.580},
+		{"Ono Hokudan", 35.930, 136.580}, {"Tsuruga Aino", 35.590, 136.140}, {"Minamiechizen Imajo", 35.770, 136.200},
+		{"Oi Natashou", 35.400, 135.700}, {"Fukui Miyama", 36.010, 136.320}, {"Sakai Maruoka", 36.150, 136.280},
+	},
+	19: { // 山梨県
+		{"Kofu", 35.662, 138.568}, {"Fujiyoshida", 35.487, 138.808}, {"Otsuki", 35.610, 138.940},
+		{"Uenohara", 35.630, 139.108}, {"Tsuru", 35.552, 138.905}, {"Yamanashi", 35.693, 138.687},
+		{"Koshu", 35.704, 138.728}, {"Hokuto", 35.777, 138.423}, {"Nirasaki", 35.709, 138.446},
+		{"Minami-Alps", 35.608, 138.465}, {"Fujikawaguchiko", 35.497, 138.755}, {"Minobu", 35.367, 138.440},
+		{"Nanbu", 35.280, 138.450}, {"Hayakawa", 35.430, 138.350}, {"Doshi", 35.530, 139.030},
+		{"Yamanakako", 35.410, 138.880}, {"Kosuge", 35.760, 138.940}, {"Tabayama", 35.790, 138.940},
+		{"Kobuchizawa", 35.860, 138.320}, {"Narusawa", 35.470, 138.700}, {"Fujikawa", 35.560, 138.460},
+		{"Ichikawamisato", 35.570, 138.500}, {"Sutama", 35.800, 138.470}, {"Koshu Enzan", 35.800, 138.800},
+		{"Hokuto Masutomi", 35.880, 138.560}, {"Minami-Alps Ashiyasu", 35.600, 138.300},
+		{"Hayakawa Narada", 35.530, 138.270}, {"Kitayama (Kofu)", 35.790, 138.620}, {"Nanbu Tomizawa", 35.180, 138.440},
+	},
+	20: { // 長野県
+		{"Nagano", 36.651, 138.181}, {"Matsumoto", 36.238, 137.972}, {"Ueda", 36.402, 138.249},
+		{"Iida", 35.515, 137.822}, {"Suwa", 36.039, 138.114}, {"Saku", 36.249, 138.477},
+		{"Komoro", 36.327, 138.426}, {"Ina", 35.828, 137.954}, {"Komagane", 35.729, 137.934},
+		{"Chino", 35.996, 138.159}, {"Okaya", 36.067, 138.049}, {"Shiojiri", 36.115, 137.953},
+		{"Azumino", 36.304, 137.906}, {"Omachi", 36.503, 137.852}, {"Hakuba", 36.698, 137.862},
+		{"Otari", 36.770, 137.870}, {"Iiyama", 36.851, 138.365}, {"Nakano", 36.742, 138.370},
+		{"Suzaka", 36.651, 138.307}, {"Karuizawa", 36.348, 138.597}, {"Kiso", 35.840, 137.690},
+		{"Nagiso", 35.590, 137.610}, {"Agematsu", 35.780, 137.690}, {"Achi", 35.440, 137.750},
+		{"Tenryu", 35.270, 137.850}, {"Neba", 35.250, 137.600}, {"Sakae", 36.990, 138.580},
+		{"Nozawaonsen", 36.920, 138.440}, {"Minamimaki", 35.960, 138.490}, {"Kawakami", 35.930, 138.580},
+		{"Sakuho", 36.160, 138.480}, {"Tateshina", 36.240, 138.290}, {"Anan", 35.320, 137.820},
+		{"Hiraya", 35.320, 137.630}, {"Oshika", 35.560, 138.030}, {"Hase", 35.740, 138.070},
+		{"Fujimi", 35.910, 138.240}, {"Ikusaka", 36.430, 137.930}, {"Ogawa", 36.610, 137.970},
+		{"Nagawa", 36.190, 138.250}, {"Ikeda", 36.420, 137.870}, {"Yasuoka", 35.370, 137.860},
+		{"Urugi", 35.220, 137.720}, {"Narai", 35.970, 137.810}, {"Otaki", 35.810, 137.550},
+		{"Kamikochi", 36.250, 137.640}, {"Matsumoto Azusagawa", 36.180, 137.740}, {"Omachi Kurobe", 36.560, 137.720},
+		{"Takayama (Nagano)", 36.680, 138.360}, {"Shiga Kogen", 36.710, 138.500}, {"Sakaki", 36.460, 138.180},
+		{"Miyota", 36.320, 138.510}, {"Kiso Kaida", 35.950, 137.620}, {"Kiso Mitake", 35.850, 137.480},
+		{"Nagiso Tsumago", 35.570, 137.600}, {"Achi Namiai", 35.350, 137.650}, {"Ueda Sanada", 36.470, 138.320},
+		{"Karuizawa Kitakaruizawa", 36.470, 138.550}, {"Saku Usuda", 36.200, 138.470}, {"Ina Takato", 35.830, 138.060},
+		{"Iida Minamishinano", 35.380, 137.980}, {"Sakae Akiyama", 36.870, 138.630}, {"Yamanouchi", 36.740, 138.410},
+	},
+	21: { // 岐阜県
+		{"Gifu", 35.423, 136.761}, {"Ogaki", 35.359, 136.613}, {"Tajimi", 35.333, 137.132},
+		{"Seki", 35.496, 136.918}, {"Kani", 35.426, 137.061}, {"Minokamo", 35.440, 137.016},
+		{"Toki", 35.353, 137.183}, {"Nakatsugawa", 35.487, 137.500}, {"Ena", 35.449, 137.413},
+		{"Mizunami", 35.369, 137.254}, {"Gero", 35.806, 137.244}, {"Takayama", 36.146, 137.252},
+		{"Hida", 36.238, 137.186}, {"Gujo", 35.748, 136.964}, {"Mino", 35.545, 136.908},
+		{"Motosu", 35.480, 136.680}, {"Ibigawa", 35.480, 136.570}, {"Sekigahara", 35.365, 136.467},
+		{"Kaizu", 35.220, 136.640}, {"Hashima", 35.319, 136.703}, {"Kakamigahara", 35.399, 136.863},
+		{"Yamagata", 35.500, 136.780}, {"Shirakawa-go", 36.257, 136.906}, {"Kamioka", 36.330, 137.300},
+		{"Okuhida", 36.240, 137.550}, {"Nyukawa", 36.160, 137.450}, {"Hagiwara", 35.890, 137.210},
+		{"Shirotori", 35.880, 136.870}, {"Hichiso", 35.520, 137.130}, {"Yaotsu", 35.470, 137.140},
+		{"Higashishirakawa", 35.640, 137.320}, {"Kashimo", 35.630, 137.430}, {"Yamaoka", 35.330, 137.360},
+		{"Kamiyahagi", 35.240, 137.390}, {"Sakauchi", 35.600, 136.430}, {"Tarui", 35.370, 136.530},
+		{"Yoro", 35.310, 136.560}, {"Kaizu South", 35.160, 136.660}, {"Nakatsugawa Magome", 35.530, 137.570},
+		{"Nakatsugawa Tsukechi", 35.660, 137.490}, {"Gero Osaka", 35.950, 137.280}, {"Takayama Shokawa", 36.060, 136.950},
+		{"Takayama Kiyomi", 36.140, 137.050}, {"Hida Kawai", 36.310, 137.100}, {"Gujo Takasu", 35.950, 136.850},
+		{"Ibigawa Fujihashi", 35.610, 136.520}, {"Motosu Neo", 35.640, 136.640}, {"Seki Itadori", 35.640, 136.830},
+		{"Hida Kamitakara", 36.280, 137.460}, {"Kakamigahara Unuma", 35.405, 136.925}, {"Ena Akechi", 35.300, 137.420}, {"Takayama Nyukawa North", 36.120, 137.580},
+	},
+	22: { // 静岡県
+		{"Shizuoka", 34.976, 138.383}, {"Hamamatsu", 34.711, 137.726}, {"Numazu", 35.095, 138.864},
+		{"Fuji", 35.161, 138.676}, {"Fujinomiya", 35.222, 138.621}, {"Mishima", 35.118, 138.919},
+		{"Atami", 35.096, 139.072}, {"Ito", 34.966, 139.102}, {"Shimoda", 34.680, 138.945},
+		{"Gotemba", 35.309, 138.935}, {"Oyama", 35.360, 138.990}, {"Susono", 35.174, 138.907},
+		{"Izu", 34.976, 138.947}, {"Izunokuni", 35.028, 138.929}, {"Nishiizu", 34.770, 138.780},
+		{"Minamiizu", 34.650, 138.860}, {"Kawazu", 34.750, 139.000}, {"Higashiizu", 34.800, 139.040},
+		{"Yaizu", 34.867, 138.324}, {"Fujieda", 34.867, 138.258}, {"Shimada", 34.836, 138.176},
+		{"Kakegawa", 34.769, 137.999}, {"Fukuroi", 34.750, 137.925}, {"Iwata", 34.718, 137.851},
+		{"Kikugawa", 34.758, 138.085}, {"Omaezaki", 34.638, 138.128}, {"Makinohara", 34.740, 138.225},
+		{"Kosai", 34.719, 137.532}, {"Kawanehon", 35.120, 138.130}, {"Ikawa", 35.210, 138.220},
+		{"Tenryu", 34.870, 137.820}, {"Sakuma", 35.080, 137.800}, {"Misakubo", 35.160, 137.870},
+		{"Haruno", 34.980, 137.890}, {"Inasa", 34.820, 137.660}, {"Umegashima", 35.300, 138.350},
+		{"Shibakawa", 35.290, 138.560}, {"Fujinomiya Inokashira", 35.380, 138.620},
+		{"Hamamatsu Mikkabi", 34.800, 137.560}, {"Kawanehon Senzu", 35.000, 138.120},
+		{"Shizuoka Okouchi", 35.400, 138.300}, {"Oyama Subashiri", 35.370, 138.880},
+		{"Hamamatsu Tatsuyama", 34.970, 137.760}, {"Hamamatsu Sakuma North", 35.170, 137.770},
+	},
+	23: { // 愛知県
+		{"Nagoya", 35.181, 136.906}, {"Toyota", 35.083, 137.156}, {"Okazaki", 34.955, 137.174},
+		{"Toyohashi", 34.769, 137.392}, {"Ichinomiya", 35.303, 136.803}, {"Kasugai", 35.248, 136.972},
+		{"Seto", 35.223, 137.084}, {"Anjo", 34.959, 137.080}, {"Toyokawa", 34.827, 137.376},
+		{"Kariya", 34.989, 136.998}, {"Handa", 34.892, 136.938}, {"Tokoname", 34.887, 136.832},
+		{"Chita", 34.999, 136.865}, {"Mihama", 34.780, 136.910}, {"Minamichita", 34.720, 136.930},
+		{"Tahara", 34.668, 137.264}, {"Irago", 34.580, 137.020}, {"Gamagori", 34.826, 137.219},
+		{"Nishio", 34.862, 137.062}, {"Shinshiro", 34.900, 137.499}, {"Toei", 35.080, 137.700},
+		{"Shitara", 35.090, 137.570}, {"Toyone", 35.130, 137.720}, {"Inuyama", 35.378, 136.944},
+		{"Komaki", 35.291, 136.912}, {"Tsushima", 35.177, 136.741}, {"Aisai", 35.150, 136.730},
+		{"Yatomi", 35.110, 136.720}, {"Tobishima", 35.070, 136.780}, {"Inazawa", 35.248, 136.780},
+		{"Asuke", 35.140, 137.320}, {"Inabu", 35.210, 137.510}, {"Obu", 35.010, 136.960},
+		{"Toyoake", 35.050, 137.010}, {"Nisshin", 35.130, 137.040}, {"Toyota Obara", 35.230, 137.280},
+		{"Toyota Fujioka", 35.200, 137.190}, {"Shitara Tsugu", 35.000, 137.600}, {"Toyohashi East", 34.730, 137.480},
+		{"Shinshiro Hourai", 34.920, 137.580}, {"Iwakura", 35.280, 136.870},
+	},
+	24: { // 三重県
+		{"Tsu", 34.730, 136.509}, {"Yokkaichi", 34.965, 136.624}, {"Suzuka", 34.882, 136.584},
+		{"Matsusaka", 34.578, 136.527}, {"Ise", 34.487, 136.709}, {"Kuwana", 35.062, 136.684},
+		{"Nabari", 34.628, 136.108}, {"Iga", 34.769, 136.130}, {"Toba", 34.481, 136.843},
+		{"Shima", 34.328, 136.831}, {"Owase", 34.071, 136.191}, {"Kumano", 33.888, 136.100},
+		{"Kiho", 33.740, 136.010}, {"Mihama (Mie)", 33.830, 136.050}, {"Kihoku", 34.210, 136.340},
+		{"Odai", 34.390, 136.400}, {"Taiki", 34.390, 136.580}, {"Minamiise", 34.350, 136.700},
+		{"Kameyama", 34.856, 136.451}, {"Inabe", 35.115, 136.561}, {"Komono", 35.020, 136.510},
+		{"Misugi", 34.510, 136.250}, {"Kiwa", 33.820, 136.000}, {"Kawagoe (Mie)", 35.020, 136.670},
+		{"Kisosaki", 35.090, 136.720}, {"Kuwana Tado", 35.130, 136.620}, {"Iga Shimagahara", 34.780, 136.030},
+		{"Iga Ayama", 34.830, 136.150}, {"Tsu Geino", 34.830, 136.430}, {"Tsu Hakusan", 34.630, 136.370},
+		{"Matsusaka Iitaka", 34.450, 136.270}, {"Kihoku Miyama", 34.100, 136.240}, {"Kumano Kiwa", 33.850, 136.020},
+		{"Nabari Akame", 34.580, 136.080}, {"Kumano Iruka", 33.950, 136.060}, {"Inabe Fujiwara", 35.160, 136.480},
+	},
+	25: { // 滋賀県
+		{"Otsu", 35.018, 135.855}, {"Kusatsu", 35.013, 135.960}, {"Moriyama", 35.058, 135.994},
+		{"Ritto", 35.021, 136.000}, {"Konan", 34.995, 136.087}, {"Koka", 34.966, 136.165},
+		{"Shigaraki", 34.880, 136.070}, {"Omihachiman", 35.128, 136.098}, {"Higashiomi", 35.113, 136.207},
+		{"Hikone", 35.274, 136.260}, {"Nagahama", 35.381, 136.270}, {"Maibara", 35.315, 136.290},
+		{"Takashima", 35.353, 136.036}, {"Imazu", 35.410, 136.030}, {"Kutsuki", 35.360, 135.910},
+		{"Yogo", 35.530, 136.200}, {"Nishiazai", 35.520, 136.090}, {"Taga", 35.220, 136.290},
+		{"Hino", 35.013, 136.251}, {"Yasu", 35.068, 136.026}, {"Katata", 35.110, 135.920},
+		{"Makino", 35.450, 136.060}, {"Aisho", 35.160, 136.220}, {"Otsu Ishiyama", 34.960, 135.910},
+		{"Koka Tsuchiyama", 34.930, 136.270}, {"Higashiomi Eigenji", 35.100, 136.320}, {"Maibara Ibuki", 35.390, 136.380},
+		{"Takashima Asahi", 35.280, 135.980}, {"Otsu Katsuragawa", 35.200, 135.850}, {"Nagahama Kinomoto", 35.490, 136.230},
+		{"Otsu Seta", 34.980, 135.900}, {"Koka Shigaraki South", 34.830, 136.070},
+	},
+	26: { // 京都府
+		{"Kyoto", 35.012, 135.768}, {"Uji", 34.884, 135.800}, {"Kameoka", 35.013, 135.574},
+		{"Fukuchiyama", 35.297, 135.126}, {"Maizuru", 35.474, 135.386}, {"Ayabe", 35.298, 135.259},
+		{"Miyazu", 35.536, 135.196}, {"Kyotango", 35.624, 135.061}, {"Sonobe", 35.107, 135.468},
+		{"Miyama", 35.290, 135.560}, {"Kurama", 35.110, 135.770}, {"Hanase", 35.170, 135.770},
+		{"Keihoku", 35.160, 135.630}, {"Yawata", 34.871, 135.707}, {"Kyotanabe", 34.815, 135.768},
+		{"Kizugawa", 34.737, 135.820}, {"Minamiyamashiro", 34.770, 136.020}, {"Wazuka", 34.800, 135.900},
+		{"Ujitawara", 34.850, 135.860}, {"Kyotamba", 35.160, 135.420}, {"Ine", 35.680, 135.290},
+		{"Yosano", 35.570, 135.160}, {"Oyamazaki", 34.900, 135.690}, {"Nagaokakyo", 34.926, 135.695},
+		{"Muko", 34.948, 135.698}, {"Joyo", 34.853, 135.780}, {"Seika", 34.760, 135.790},
+		{"Kasagi", 34.760, 135.940}, {"Oe", 35.400, 135.160}, {"Yakuno", 35.290, 135.020},
+		{"Kyoto Ohara", 35.120, 135.830}, {"Kyoto Yamashina", 34.980, 135.810}, {"Kyoto Fushimi", 34.940, 135.760},
+		{"Kyoto Nishikyo", 34.980, 135.650}, {"Kameoka Higashibetsuin", 34.950, 135.520},
+		{"Miyama Ashiu", 35.330, 135.720}, {"Ayabe Kambayashi", 35.370, 135.450}, {"Fukuchiyama Miwa", 35.230, 135.220},
+		{"Maizuru East", 35.500, 135.450}, {"Kyotamba Mizuho", 35.100, 135.340}, {"Nantan Hiyoshi", 35.170, 135.500},
+	},
+	27: { // 大阪府
+		{"Osaka", 34.694, 135.502}, {"Sakai", 34.573, 135.483}, {"Toyonaka", 34.781, 135.470},
+		{"Suita", 34.759, 135.517}, {"Takatsuki", 34.846, 135.617}, {"Hirakata", 34.814, 135.651},
+		{"Ibaraki", 34.816, 135.568}, {"Neyagawa", 34.766, 135.628}, {"Higashiosaka", 34.679, 135.601},
+		{"Yao", 34.627, 135.601}, {"Kishiwada", 34.460, 135.371}, {"Izumi", 34.483, 135.424},
+		{"Izumisano", 34.407, 135.327}, {"Kaizuka", 34.437, 135.359}, {"Sennan", 34.366, 135.274},
+		{"Hannan", 34.359, 135.240}, {"Misaki", 34.320, 135.150}, {"Kawachinagano", 34.458, 135.564},
+		{"Tondabayashi", 34.500, 135.597}, {"Habikino", 34.558, 135.606}, {"Kashiwara", 34.579, 135.628},
+		{"Minoo", 34.827, 135.470}, {"Ikeda", 34.822, 135.429}, {"Nose", 34.970, 135.420},
+		{"Toyono", 34.920, 135.490}, {"Shimamoto", 34.880, 135.670}, {"Katano", 34.788, 135.680},
+		{"Shijonawate", 34.740, 135.693}, {"Daito", 34.712, 135.624}, {"Chihayaakasaka", 34.440, 135.620},
+		{"Kanan", 34.490, 135.620}, {"Taishi", 34.520, 135.650}, {"Takaishi", 34.520, 135.440},
+		{"Tadaoka", 34.490, 135.400}, {"Kumatori", 34.400, 135.360}, {"Tajiri", 34.390, 135.290},
+		{"Konohana", 34.683, 135.432}, {"Kansai Airport", 34.430, 135.230}, {"Takatsuki Kashihara", 34.920, 135.600},
+		{"Izumi Kouda", 34.420, 135.460}, {"Kawachinagano Amami", 34.400, 135.560}, {"Kishiwada Ushitaki", 34.380, 135.420},
+		{"Minoo North", 34.880, 135.460}, {"Nose South", 34.930, 135.400}, {"Hirakata East", 34.800, 135.700},
+		{"Higashiosaka Ikoma", 34.670, 135.650}, {"Yao Takayasu", 34.610, 135.650}, {"Sakai Minami", 34.500, 135.500},
+	},
+	28: { // 兵庫県
+		{"Kobe", 34.690, 135.196}, {"Himeji", 34.816, 134.686}, {"Nishinomiya", 34.738, 135.342},
+		{"Amagasaki", 34.733, 135.406}, {"Akashi", 34.643, 134.997}, {"Kakogawa", 34.757, 134.841},
+		{"Takarazuka", 34.800, 135.360}, {"Itami", 34.784, 135.401}, {"Kawanishi", 34.830, 135.417},
+		{"Sanda", 34.890, 135.226}, {"Toyooka", 35.544, 134.820}, {"Kinosaki", 35.620, 134.810},
+		{"Kasumi", 35.630, 134.630}, {"Shin-onsen", 35.620, 134.450}, {"Asago", 35.339, 134.852},
+		{"Yabu", 35.405, 134.767}, {"Tamba", 35.177, 135.036}, {"Tambasasayama", 35.075, 135.220},
+		{"Nishiwaki", 34.993, 134.969}, {"Kato", 34.917, 135.040}, {"Ono", 34.853, 134.931},
+		{"Miki", 34.797, 134.990}, {"Aioi", 34.804, 134.468}, {"Ako", 34.755, 134.390},
+		{"Tatsuno", 34.858, 134.546}, {"Shiso", 35.004, 134.549}, {"Sayo", 35.000, 134.360},
+		{"Kamigori", 34.870, 134.360}, {"Ichikawa", 34.990, 134.760}, {"Kamikawa", 35.060, 134.740},
+		{"Taka", 35.050, 134.920}, {"Inagawa", 34.890, 135.380}, {"Sumoto", 34.342, 134.895},
+		{"Awaji", 34.440, 134.910}, {"Minamiawaji", 34.296, 134.780}, {"Kobe Kita", 34.750, 135.150},
+		{"Kobe Nishi", 34.730, 134.990}, {"Takasago", 34.766, 134.791}, {"Fukusaki", 34.950, 134.760},
+		{"Kasai", 34.930, 134.830}, {"Shingu (Tatsuno)", 34.930, 134.540}, {"Muraoka", 35.480, 134.590},
+		{"Sekinomiya", 35.350, 134.630}, {"Tambasasayama East", 35.070, 135.330}, {"Tamba Aogaki", 35.220, 135.000},
+		{"Sanda North", 34.960, 135.230}, {"Inagawa North", 34.960, 135.360}, {"Shiso Chikusa", 35.150, 134.400},
+		{"Sayo Kozuki", 34.970, 134.300}, {"Toyooka Tanto", 35.480, 134.930}, {"Tambasasayama Ajima", 35.100, 135.140},
+		{"Kawanishi Kurokawa", 34.920, 135.410}, {"Ako Fukuura", 34.760, 134.300},
+	},
+	29: { // 奈良県
+		{"Nara", 34.685, 135.805}, {"Kashihara", 34.509, 135.793}, {"Yamatokoriyama", 34.649, 135.783},
+		{"Tenri", 34.597, 135.837}, {"Sakurai", 34.518, 135.843}, {"Gose", 34.463, 135.739},
+		{"Gojo", 34.352, 135.694}, {"Uda", 34.528, 135.952}, {"Yamazoe", 34.680, 136.040},
+		{"Soni", 34.530, 136.120}, {"Mitsue", 34.490, 136.160}, {"Higashiyoshino", 34.400, 135.990},
+		{"Kawakami", 34.340, 136.000}, {"Kamikitayama", 34.260, 136.000}, {"Shimokitayama", 34.100, 135.960},
+		{"Totsukawa", 33.990, 135.790}, {"Nosegawa", 34.120, 135.630}, {"Tenkawa", 34.240, 135.850},
+		{"Yoshino", 34.400, 135.860}, {"Oyodo", 34.390, 135.790}, {"Shimoichi", 34.360, 135.790},
+		{"Ikoma", 34.692, 135.700}, {"Heguri", 34.630, 135.700}, {"Oji", 34.590, 135.710},
+		{"Kashiba", 34.541, 135.699}, {"Katsuragi", 34.489, 135.726}, {"Tsuge", 34.650, 136.000},
+		{"Totsukawa South", 33.900, 135.800}, {"Nara Tsukigase", 34.740, 136.020}, {"Gojo Otou", 34.200, 135.720},
+		{"Totsukawa West", 34.000, 135.680}, {"Nara Yagyu", 34.720, 135.920}, {"Kamikitayama Odaigahara", 34.180, 136.100},
+		{"Gojo Nishiyoshino", 34.300, 135.640},
+	},
+	30: { // 和歌山県
+		{"Wakayama", 34.230, 135.171}, {"Kainan", 34.155, 135.209}, {"Hashimoto", 34.315, 135.605},
+		{"Kinokawa", 34.270, 135.363}, {"Iwade", 34.256, 135.311}, {"Arida", 34.083, 135.128},
+		{"Gobo", 33.891, 135.153}, {"Tanabe", 33.728, 135.378}, {"Shirahama", 33.678, 135.348},
+		{"Kushimoto", 33.472, 135.781}, {"Shingu", 33.724, 135.993}, {"Nachikatsuura", 33.626, 135.941},
+		{"Hongu", 33.840, 135.770}, {"Ryujin", 33.950, 135.550}, {"Koya", 34.210, 135.580},
+		{"Katsuragi (Wakayama)", 34.300, 135.510}, {"Kimino", 34.150, 135.310}, {"Aridagawa", 34.050, 135.260},
+		{"Hidakagawa", 33.900, 135.380}, {"Minabe", 33.770, 135.330}, {"Susami", 33.550, 135.500},
+		{"Kozagawa", 33.530, 135.730}, {"Kitayama", 33.930, 135.980}, {"Kozagawa North", 33.600, 135.740},
+		{"Taiji", 33.590, 135.940}, {"Wakayama Kada", 34.270, 135.070}, {"Hashimoto Kamuro", 34.260, 135.640},
+		{"Tanabe Nakahechi", 33.790, 135.530}, {"Shingu Kumanogawa", 33.800, 135.880}, {"Aridagawa Shimizu", 34.080, 135.460},
+	},
+	31: { // 鳥取県
+		{"Tottori", 35.501, 134.235}, {"Kurayoshi", 35.430, 133.826}, {"Yonago", 35.428, 133.331},
+		{"Sakaiminato", 35.540, 133.232}, {"Chizu", 35.260, 134.230}, {"Wakasa (Tottori)", 35.330, 134.400},
+		{"Iwami", 35.580, 134.330}, {"Yazu", 35.410, 134.250}, {"Misasa", 35.410, 133.880},
+		{"Kotoura", 35.500, 133.690}, {"Daisen", 35.510, 133.490}, {"Kofu (Tottori)", 35.280, 133.490},
+		{"Nichinan", 35.160, 133.300}, {"Hino", 35.240, 133.440}, {"Nanbu", 35.330, 133.340},
+		{"Yurihama", 35.480, 133.870}, {"Hokuei", 35.490, 133.760}, {"Chizu South", 35.190, 134.270},
+		{"Misasa South", 35.340, 133.920}, {"Nichinan West", 35.120, 133.200}, {"Wakasa East", 35.330, 134.470},
+		{"Tottori Saji", 35.330, 134.100}, {"Kurayoshi Sekigane", 35.380, 133.720},
+	},
+	32: { // 島根県
+		{"Matsue", 35.468, 133.049}, {"Izumo", 35.367, 132.755}, {"Hamada", 34.899, 132.080},
+		{"Masuda", 34.675, 131.843}, {"Oda", 35.192, 132.500}, {"Yasugi", 35.431, 133.251},
+		{"Gotsu", 35.011, 132.221}, {"Unnan", 35.288, 132.900}, {"Okuizumo", 35.197, 133.003},
+		{"Iinan", 35.070, 132.700}, {"Misato (Shimane)", 34.990, 132.510}, {"Onan", 34.880, 132.440},
+		{"Tsuwano", 34.468, 131.771}, {"Yoshika", 34.500, 131.930}, {"Kawamoto", 34.990, 132.490},
+		{"Okinoshima", 36.210, 133.320}, {"Nishinoshima", 36.090, 133.000}, {"Sakurae", 34.960, 132.370},
+		{"Kanagi", 34.800, 132.180}, {"Hikimi", 34.570, 132.000}, {"Yasugi Hirose", 35.340, 133.200},
+		{"Okuizumo Yokota", 35.170, 133.100}, {"Iinan Tonbara", 35.000, 132.750}, {"Onan Mizuho", 34.820, 132.520},
+		{"Tsuwano Nichihara", 34.540, 131.840}, {"Masuda Mito", 34.600, 131.950}, {"Unnan Kakeya", 35.200, 132.790},
+		{"Matsue Mihonoseki", 35.560, 133.300},
+	},
+	33: { // 岡山県
+		{"Okayama", 34.655, 133.919}, {"Kurashiki", 34.585, 133.772}, {"Tsuyama", 35.069, 134.004},
+		{"Tamano", 34.492, 133.946}, {"Kasaoka", 34.507, 133.507}, {"Ibara", 34.597, 133.464},
+		{"Soja", 34.673, 133.747}, {"Takahashi", 34.791, 133.617}, {"Niimi", 34.977, 133.470},
+		{"Bizen", 34.745, 134.189}, {"Setouchi", 34.667, 134.093}, {"Akaiwa", 34.755, 134.019},
+		{"Maniwa", 35.075, 133.753}, {"Mimasaka", 35.009, 134.149}, {"Asakuchi", 34.528, 133.584},
+		{"Wake", 34.800, 134.160}, {"Kagamino", 35.090, 133.930}, {"Nagi", 35.120, 134.180},
+		{"Nishiawakura", 35.170, 134.340}, {"Shinjo", 35.180, 133.570}, {"Kumenan", 34.930, 133.960},
+		{"Misaki", 34.990, 133.880}, {"Yakage", 34.630, 133.590}, {"Kibichuo", 34.860, 133.690},
+		{"Hayashima", 34.600, 133.830}, {"Hiruzen", 35.300, 133.680}, {"Saidaiji", 34.650, 134.040},
+		{"Ushimado", 34.620, 134.160}, {"Niimi Tetta", 35.000, 133.300}, {"Niimi Osa", 34.880, 133.380},
+		{"Ibara Yoshii", 34.700, 133.390}, {"Kagamino Okutsu", 35.200, 133.920}, {"Mimasaka Ohara", 35.120, 134.300},
+		{"Bizen Hinase", 34.730, 134.280}, {"Takahashi Bitchu", 34.900, 133.560}, {"Kasaoka Konoshima", 34.430, 133.500},
+	},
+	34: { // 広島県
+		{"Hiroshima", 34.385, 132.455}, {"Fukuyama", 34.486, 133.362}, {"Kure", 34.249, 132.566},
+		{"Onomichi", 34.409, 133.205}, {"Mihara", 34.398, 133.079}, {"Higashihiroshima", 34.426, 132.743},
+		{"Hatsukaichi", 34.348, 132.332}, {"Miyajima", 34.296, 132.320}, {"Otake", 34.238, 132.222},
+		{"Takehara", 34.342, 132.907}, {"Miyoshi", 34.805, 132.852}, {"Shobara", 34.858, 133.017},
+		{"Fuchu", 34.568, 133.237}, {"Akitakata", 34.663, 132.706}, {"Kitahiroshima", 34.674, 132.538},
+		{"Akiota", 34.571, 132.227}, {"Yoshiwa", 34.420, 132.120}, {"Sera", 34.590, 133.060},
+		{"Jinsekikogen", 34.710, 133.260}, {"Shinichi", 34.560, 133.310}, {"Saijo (Shobara)", 35.010, 133.170},
+		{"Tojo", 34.890, 133.280}, {"Etajima", 34.171, 132.482}, {"Osakikamijima", 34.220, 132.870},
+		{"Innoshima", 34.330, 133.180}, {"Geihoku", 34.760, 132.410}, {"Togochi", 34.500, 132.200},
+		{"Fukuyama East", 34.520, 133.430}, {"Jinsekikogen North", 34.800, 133.300}, {"Shobara Takano", 35.070, 132.970},
+		{"Miyoshi Kisa", 34.790, 132.700}, {"Akitakata Takamiya", 34.800, 132.620}, {"Kitahiroshima Yawata", 34.800, 132.200},
+		{"Otake Kurisu", 34.280, 132.150},
+	},
+	35: { // 山口県
+		{"Yamaguchi", 34.178, 131.474}, {"Shimonoseki", 33.958, 130.941}, {"Ube", 33.951, 131.247},
+		{"Shunan", 34.055, 131.806}, {"Iwakuni", 34.167, 132.219}, {"Hofu", 34.052, 131.563},
+		{"Hagi", 34.408, 131.399}, {"Nagato", 34.371, 131.182}, {"Kudamatsu", 34.015, 131.870},
+		{"Hikari", 33.962, 131.942}, {"Yanai", 33.964, 132.101}, {"Mine", 34.167, 131.205},
+		{"Sanyo-Onoda", 34.003, 131.182}, {"Suooshima", 33.930, 132.200}, {"Abu", 34.500, 131.470},
+		{"Nishiki", 34.240, 131.960}, {"Miwa", 34.260, 132.090}, {"Tokuji", 34.280, 131.640},
+		{"Ato", 34.380, 131.660}, {"Kano", 34.200, 131.820}, {"Susa", 34.620, 131.600},
+		{"Toyota (Yamaguchi)", 34.200, 131.050}, {"Mikawa", 34.180, 131.900}, {"Hohoku", 34.250, 130.940},
+		{"Tabuse", 33.950, 132.040}, {"Iwakuni Kuga", 34.100, 132.070}, {"Iwakuni Mikawa North", 34.330, 132.050},
+		{"Hagi Mutsumi", 34.470, 131.600}, {"Yamaguchi Tokusa", 34.400, 131.800}, {"Shunan Sugano", 34.300, 131.900},
+		{"Iwakuni Hongo", 34.340, 131.960}, {"Hagi Kawakami", 34.450, 131.500},
+	},
+	36: { // 徳島県
+		{"Tokushima", 34.070, 134.555}, {"Naruto", 34.172, 134.609}, {"Komatsushima", 34.004, 134.590},
+		{"Anan", 33.922, 134.660}, {"Yoshinogawa", 34.066, 134.359}, {"Awa", 34.102, 134.296},
+		{"Mima", 34.057, 134.169}, {"Miyoshi", 34.026, 133.807}, {"Kamiyama", 33.970, 134.350},
+		{"Naka", 33.850, 134.450}, {"Kaiyo", 33.600, 134.350}, {"Minami", 33.750, 134.570},
+		{"Mugi", 33.670, 134.420}, {"Tsurugi", 33.970, 134.070}, {"Kamikatsu", 33.890, 134.400},
+		{"Higashimiyoshi", 34.040, 133.930}, {"Iya", 33.870, 133.840}, {"Yamashiro", 33.940, 133.740},
+		{"Itano", 34.140, 134.460}, {"Kamiita", 34.120, 134.400}, {"Naka Kito", 33.790, 134.180},
+		{"Tsurugi Ichiu", 33.870, 134.050}, {"Kaiyo Kainan", 33.650, 134.260}, {"Naruto Kitanada", 34.230, 134.520},
+		{"Awa Donari", 34.150, 134.260}, {"Miyoshi Higashiiya", 33.850, 133.960},
+	},
+	37: { // 香川県
+		{"Takamatsu", 34.343, 134.046}, {"Marugame", 34.290, 133.798}, {"Sakaide", 34.317, 133.860},
+		{"Zentsuji", 34.227, 133.787}, {"Kanonji", 34.127, 133.661}, {"Mitoyo", 34.183, 133.715},
+		{"Sanuki", 34.325, 134.173}, {"Higashikagawa", 34.247, 134.358}, {"Shodoshima", 34.480, 134.230},
+		{"Tonosho", 34.480, 134.170}, {"Kotohira", 34.190, 133.820}, {"Manno", 34.210, 133.850},
+		{"Ayagawa", 34.250, 133.920}, {"Shionoe", 34.160, 134.010}, {"Naoshima", 34.460, 133.990},
+		{"Tadotsu", 34.270, 133.750}, {"Onohara", 34.070, 133.630}, {"Miki", 34.270, 134.130},
+		{"Mitoyo Saita", 34.130, 133.750}, {"Sanuki Nagao", 34.260, 134.170}, {"Higashikagawa Hiketa", 34.230, 134.400},
+	},
+	38: { // 愛媛県
+		{"Matsuyama", 33.839, 132.766}, {"Imabari", 34.066, 132.998}, {"Niihama", 33.960, 133.283},
+		{"Saijo", 33.919, 133.181}, {"Shikokuchuo", 33.981, 133.549}, {"Uwajima", 33.223, 132.560},
+		{"Yawatahama", 33.463, 132.423}, {"Ozu", 33.506, 132.544}, {"Seiyo", 33.363, 132.511},
+		{"Iyo", 33.757, 132.703}, {"Toon", 33.791, 132.872}, {"Ainan", 32.960, 132.580},
+		{"Ikata", 33.490, 132.350}, {"Kumakogen", 33.660, 132.900}, {"Tobe", 33.750, 132.790},
+		{"Uchiko", 33.530, 132.660}, {"Kihoku", 33.260, 132.690}, {"Matsuno", 33.230, 132.710},
+		{"Kamijima", 34.250, 133.200}, {"Oshima", 34.150, 133.050}, {"Shingu", 33.900, 133.660},
+		{"Nomura", 33.370, 132.620}, {"Omogo", 33.720, 133.090}, {"Besshi", 33.890, 133.320},
+		{"Tanbara", 33.890, 133.080}, {"Tsushima (Ehime)", 33.100, 132.520}, {"Seiyo Shirokawa", 33.380, 132.780},
+		{"Kumakogen Yanadani", 33.600, 133.050}, {"Ozu Hijikawa", 33.580, 132.800}, {"Kihoku Hiromi", 33.300, 132.820},
+		{"Ainan Ipponmatsu", 32.950, 132.680}, {"Saijo Ishizuchi", 33.800, 133.130},
+	},
+	39: { // 高知県
+		{"Kochi", 33.559, 133.531}, {"Nankoku", 33.576, 133.641}, {"Konan", 33.564, 133.700},
+		{"Kami", 33.604, 133.808}, {"Aki", 33.502, 133.907}, {"Muroto", 33.290, 134.152},
+		{"Tosa", 33.497, 133.426}, {"Susaki", 33.401, 133.283}, {"Shimanto", 32.991, 132.934},
+		{"Tosashimizu", 32.782, 132.955}, {"Sukumo", 32.914, 132.726}, {"Otsuki", 32.830, 132.710},
+		{"Kuroshio", 33.020, 133.010}, {"Nakatosa", 33.330, 133.220}, {"Shimanto-cho", 33.210, 133.140},
+		{"Yusuhara", 33.390, 132.930}, {"Tsuno", 33.440, 133.000}, {"Niyodogawa", 33.580, 133.160},
+		{"Ino", 33.547, 133.428}, {"Otoyo", 33.760, 133.660}, {"Motoyama", 33.760, 133.590},
+		{"Tosacho", 33.740, 133.430}, {"Monobe", 33.730, 133.880}, {"Umaji", 33.560, 134.050},
+		{"Kitagawa", 33.450, 134.040}, {"Toyo", 33.280, 134.270}, {"Geisei", 33.530, 133.810},
+		{"Nishitosa", 33.160, 132.780}, {"Yasuda", 33.440, 133.980}, {"Otoyo East", 33.800, 133.800},
+		{"Ino Hongawa", 33.720, 133.300}, {"Niyodogawa Ikegawa", 33.620, 133.060}, {"Tsuno Kitagawa", 33.470, 132.870},
+		{"Shimanto Tokawa", 33.080, 132.830}, {"Kami Monobe North", 33.800, 133.950},
+	},
+	40: { // 福岡県
+		{"Fukuoka", 33.590, 130.402}, {"Kitakyushu", 33.883, 130.875}, {"Kurume", 33.319, 130.508},
+		{"Omuta", 33.030, 130.446}, {"Iizuka", 33.646, 130.691}, {"Tagawa", 33.639, 130.806},
+		{"Yanagawa", 33.163, 130.406}, {"Yame", 33.212, 130.558}, {"Chikugo", 33.212, 130.502},
+		{"Asakura", 33.423, 130.665}, {"Ogori", 33.396, 130.556}, {"Kasuga", 33.533, 130.470},
+		{"Dazaifu", 33.513, 130.524}, {"Itoshima", 33.557, 130.196}, {"Munakata", 33.806, 130.541},
+		{"Fukutsu", 33.767, 130.491}, {"Koga", 33.729, 130.470}, {"Nogata", 33.744, 130.729},
+		{"Yukuhashi", 33.729, 130.983}, {"Buzen", 33.612, 131.130}, {"Miyako", 33.620, 130.980},
+		{"Kanda", 33.790, 130.980}, {"Soeda", 33.570, 130.850}, {"Toho", 33.430, 130.850},
+		{"Yabe", 33.200, 130.790}, {"Miyama", 33.152, 130.475}, {"Ukiha", 33.347, 130.755},
+		{"Onga", 33.860, 130.670}, {"Ashiya", 33.890, 130.660}, {"Chikuzen", 33.460, 130.590},
+		{"Kama", 33.560, 130.710}, {"Moji", 33.950, 130.960}, {"Nijo", 33.500, 130.150},
+		{"Kokuraminami", 33.800, 130.880}, {"Hisayama", 33.650, 130.500}, {"Sasaguri", 33.620, 130.520},
+		{"Nakagawa", 33.500, 130.420}, {"Kurate", 33.800, 130.630}, {"Fukuoka Sawara", 33.480, 130.340},
+		{"Yame Hoshino", 33.250, 130.770}, {"Yame Kurogi", 33.210, 130.660}, {"Koge", 33.560, 131.080},
+		{"Asakura Koishiwara", 33.480, 130.830}, {"Soeda Hikosan", 33.480, 130.930}, {"Kitakyushu Yahata", 33.860, 130.810},
+	},
+	41: { // 佐賀県
+		{"Saga", 33.249, 130.299}, {"Karatsu", 33.450, 129.968}, {"Tosu", 33.378, 130.506},
+		{"Imari", 33.264, 129.880}, {"Takeo", 33.191, 130.019}, {"Kashima", 33.104, 130.099},
+		{"Ogi", 33.268, 130.203}, {"Ureshino", 33.128, 129.975}, {"Kanzaki", 33.311, 130.373},
+		{"Taku", 33.288, 130.110}, {"Arita", 33.190, 129.890}, {"Genkai", 33.470, 129.870},
+		{"Kiyama", 33.420, 130.520}, {"Miyaki", 33.320, 130.460}, {"Yoshinogari", 33.320, 130.390},
+		{"Tara", 33.010, 130.180}, {"Shiroishi", 33.180, 130.140}, {"Omachi", 33.210, 130.120},
+		{"Kouhoku", 33.220, 130.160}, {"Hizen", 33.360, 129.790}, {"Kyuragi", 33.340, 130.080},
+		{"Fuji (Saga)", 33.380, 130.240}, {"Mitsuse", 33.410, 130.330}, {"Chinzei", 33.530, 129.880},
+		{"Karatsu Nanayama", 33.400, 130.150}, {"Imari Ochi", 33.310, 129.850}, {"Ureshino Shiota", 33.150, 130.060},
+		{"Tara Mountains", 33.050, 130.100},
+	},
+	42: { // 長崎県
+		{"Nagasaki", 32.750, 129.878}, {"Sasebo", 33.180, 129.715}, {"Isahaya", 32.843, 130.053},
+		{"Omura", 32.900, 129.958}, {"Shimabara", 32.788, 130.370}, {"Unzen", 32.830, 130.190},
+		{"Minamishimabara", 32.660, 130.300}, {"Hirado", 33.368, 129.554}, {"Matsuura", 33.341, 129.709},
+		{"Saikai", 32.933, 129.643}, {"Izuhara", 34.203, 129.288}, {"Tsushima North", 34.650, 129.470},
+		{"Iki", 33.750, 129.691}, {"Goto", 32.696, 128.841}, {"Shinkamigoto", 32.980, 129.070},
+		{"Ojika", 33.190, 129.060}, {"Nagayo", 32.830, 129.870}, {"Togitsu", 32.830, 129.850},
+		{"Higashisonogi", 33.040, 129.920}, {"Kawatana", 33.070, 129.860}, {"Hasami", 33.140, 129.900},
+		{"Sechibaru", 33.230, 129.780}, {"Nomozaki", 32.580, 129.760}, {"Iimori", 32.790, 130.070},
+		{"Takaki", 32.890, 130.090}, {"Emukae", 33.240, 129.630}, {"Saza", 33.240, 129.620},
+		{"Takashima (Matsuura)", 33.400, 129.740}, {"Kuchinotsu", 32.610, 130.190}, {"Tsushima Mitsushima", 34.400, 129.330},
+		{"Goto Tamanoura", 32.640, 128.640}, {"Omura Hill", 32.940, 130.010}, {"Sasebo Haiki", 33.130, 129.800},
+	},
+	43: { // 熊本県
+		{"Kumamoto", 32.803, 130.708}, {"Yatsushiro", 32.507, 130.602}, {"Hitoyoshi", 32.210, 130.762},
+		{"Tamana", 32.928, 130.563}, {"Arao", 32.987, 130.433}, {"Yamaga", 33.017, 130.691},
+		{"Kikuchi", 32.980, 130.813}, {"Aso", 32.952, 131.121}, {"Oguni", 33.120, 131.070},
+		{"Minamioguni", 33.100, 131.080}, {"Takamori", 32.820, 131.120}, {"Yamato", 32.680, 130.990},
+		{"Mifune", 32.710, 130.800}, {"Uki", 32.648, 130.684}, {"Kamiamakusa", 32.590, 130.430},
+		{"Amakusa", 32.458, 130.193}, {"Ushibuka", 32.190, 130.020}, {"Minamata", 32.213, 130.408},
+		{"Ashikita", 32.300, 130.490}, {"Tsunagi", 32.160, 130.420}, {"Yunomae", 32.270, 130.980},
+		{"Mizukami", 32.310, 131.010}, {"Itsuki", 32.390, 130.830}, {"Izumi (Yatsushiro)", 32.530, 130.920},
+		{"Kuma", 32.270, 130.810}, {"Taragi", 32.260, 130.940}, {"Asagiri", 32.240, 130.900},
+		{"Nishiki", 32.200, 130.840}, {"Sagara", 32.240, 130.800}, {"Yamae", 32.250, 130.770},
+		{"Nagomi", 33.030, 130.580}, {"Nankan", 33.060, 130.540}, {"Ubuyama", 32.990, 131.220},
+		{"Kosa", 32.640, 130.810}, {"Misato", 32.600, 130.790}, {"Matsushima (Amakusa)", 32.520, 130.450},
+		{"Kikuchi Kikuchi Gorge", 33.050, 130.900}, {"Yamaga Kahoku", 33.100, 130.700}, {"Yamato Soyo", 32.600, 131.050},
+		{"Yatsushiro Izumi South", 32.420, 130.950}, {"Minamata East", 32.230, 130.520}, {"Amakusa Kawaura", 32.350, 130.100},
+		{"Aso Ichinomiya", 33.000, 131.130}, {"Oguni North", 33.160, 131.070}, {"Hitoyoshi South", 32.130, 130.740},
+	},
+	44: { // 大分県
+		{"Oita", 33.238, 131.613}, {"Beppu", 33.285, 131.491}, {"Nakatsu", 33.598, 131.188},
+		{"Hita", 33.321, 130.941}, {"Saiki", 32.960, 131.900}, {"Usuki", 33.126, 131.805},
+		{"Tsukumi", 33.072, 131.861}, {"Taketa", 32.974, 131.398}, {"Bungotakada", 33.557, 131.447},
+		{"Kitsuki", 33.417, 131.616}, {"Usa", 33.532, 131.349}, {"Bungoono", 32.977, 131.585},
+		{"Yufu", 33.180, 131.427}, {"Kunisaki", 33.565, 131.732}, {"Himeshima", 33.720, 131.650},
+		{"Hiji", 33.370, 131.530}, {"Kokonoe", 33.230, 131.190}, {"Kusu", 33.280, 131.150},
+		{"Ume", 32.830, 131.670}, {"Kamae", 32.800, 131.930}, {"Yayoi", 32.920, 131.760},
+		{"Yabakei", 33.430, 131.130}, {"Oyama", 33.230, 131.030}, {"Nakatsue", 33.200, 130.970},
+		{"Kuju", 33.050, 131.250}, {"Ogata", 32.880, 131.470}, {"Notsuharu", 33.140, 131.550},
+		{"Saiki Ume South", 32.750, 131.620}, {"Taketa Ogi", 32.880, 131.300}, {"Nakatsu Yamakuni", 33.400, 131.050},
+		{"Usa Innai", 33.420, 131.330}, {"Bungoono Asaji", 33.020, 131.500}, {"Saiki Naokawa", 32.880, 131.800},
+	},
+	45: { // 宮崎県
+		{"Miyazaki", 31.908, 131.420}, {"Miyakonojo", 31.720, 131.061}, {"Nobeoka", 32.582, 131.665},
+		{"Hyuga", 32.423, 131.624}, {"Nichinan", 31.601, 131.379}, {"Kobayashi", 31.997, 130.973},
+		{"Ebino", 32.045, 130.811}, {"Saito", 32.108, 131.401}, {"Kushima", 31.465, 131.229},
+		{"Takachiho", 32.712, 131.308}, {"Gokase", 32.580, 131.200}, {"Hinokage", 32.650, 131.400},
+		{"Shiiba", 32.460, 131.160}, {"Misato (Miyazaki)", 32.400, 131.380}, {"Morotsuka", 32.510, 131.330},
+		{"Nishimera", 32.220, 131.160}, {"Kijo", 32.200, 131.460}, {"Kawaminami", 32.190, 131.530},
+		{"Takanabe", 32.130, 131.500}, {"Aya", 31.970, 131.250}, {"Kunitomi", 31.990, 131.320},
+		{"Takaharu", 31.930, 131.000}, {"Mimata", 31.730, 131.130}, {"Kadogawa", 32.470, 131.650},
+		{"Kitakata", 32.570, 131.540}, {"Kitaura", 32.730, 131.810}, {"Kitagawa", 32.700, 131.660},
+		{"Ebino North", 32.080, 130.800}, {"Toi", 31.370, 131.340}, {"Yamada", 31.780, 130.970},
+		{"Takasaki", 31.860, 131.100}, {"Nishimera West", 32.240, 131.060}, {"Shiiba South", 32.380, 131.100},
+		{"Kushima West", 31.470, 131.130}, {"Ebino Kogen", 31.950, 130.850}, {"Miyakonojo South", 31.650, 131.050}, {"Nichinan Kitago", 31.680, 131.300},
+	},
+	46: { // 鹿児島県
+		{"Kagoshima", 31.560, 130.558}, {"Kirishima", 31.741, 130.763}, {"Kanoya", 31.378, 130.852},
+		{"Satsumasendai", 31.813, 130.304}, {"Izumi", 32.090, 130.353}, {"Akune", 32.014, 130.193},
+		{"Isa", 32.057, 130.613}, {"Aira", 31.728, 130.627}, {"Ibusuki", 31.253, 130.633},
+		{"Minamikyushu", 31.378, 130.441}, {"Makurazaki", 31.272, 130.297}, {"Minamisatsuma", 31.417, 130.323},
+		{"Hioki", 31.634, 130.402}, {"Ichikikushikino", 31.714, 130.272}, {"Satsuma", 31.900, 130.450},
+		{"Nagashima", 32.200, 130.130}, {"Shibushi", 31.495, 131.045}, {"Osaki", 31.430, 131.000},
+		{"Soo", 31.653, 131.019}, {"Tarumizu", 31.492, 130.701}, {"Minamiosumi", 31.220, 130.770},
+		{"Kinko", 31.250, 130.790}, {"Kimotsuki", 31.340, 130.940}, {"Higashikushira", 31.380, 130.970},
+		{"Sakurajima", 31.580, 130.660}, {"Yakushima", 30.390, 130.650}, {"Nishinoomote", 30.730, 130.990},
+		{"Minamitane", 30.410, 130.910}, {"Amami", 28.377, 129.494}, {"Tokunoshima", 27.730, 128.970},
+		{"Okinoerabu", 27.360, 128.600}, {"Yoron", 27.040, 128.420}, {"Kikai", 28.320, 129.930},
+		{"Kakeroma", 28.150, 129.310}, {"Koshikijima", 31.830, 129.880}, {"Yusui", 31.980, 130.730},
+		{"Makizono", 31.870, 130.800}, {"Osumi", 31.710, 131.000}, {"Hishikari", 32.010, 130.670},
+		{"Kirishima Fukuyama", 31.720, 130.820}, {"Minamiosumi Sata", 31.040, 130.680}, {"Kanoya Kushira", 31.440, 130.920},
+		{"Kagoshima Koriyama", 31.680, 130.470}, {"Satsumasendai Iriki", 31.850, 130.450}, {"Izumi East", 32.050, 130.450},
+		{"Kuchinoerabu", 30.460, 130.190}, {"Tokara", 29.620, 129.710}, {"Amami Uken", 28.290, 129.250},
+		{"Kagoshima Matsumoto", 31.600, 130.470}, {"Soo Takarabe", 31.720, 130.970},
+	},
+	47: { // 沖縄県
+		{"Naha", 26.212, 127.681}, {"Okinawa", 26.334, 127.805}, {"Uruma", 26.379, 127.857},
+		{"Nago", 26.592, 127.977}, {"Kunigami", 26.745, 128.178}, {"Higashi", 26.630, 128.150},
+		{"Itoman", 26.124, 127.666}, {"Nanjo", 26.163, 127.770}, {"Ginowan", 26.282, 127.778},
+		{"Urasoe", 26.246, 127.722}, {"Yomitan", 26.396, 127.744}, {"Kin", 26.460, 127.930},
+		{"Motobu", 26.660, 127.900}, {"Ishigaki", 24.341, 124.156}, {"Miyakojima", 24.805, 125.281},
+		{"Yonaguni", 24.468, 123.004}, {"Iriomote", 24.320, 123.820}, {"Kumejima", 26.340, 126.800},
+		{"Kitadaito", 25.950, 131.300}, {"Minamidaito", 25.830, 131.230}, {"Izena", 26.930, 127.940},
+		{"Iheya", 27.040, 127.970}, {"Zamami", 26.230, 127.300}, {"Tonaki", 26.370, 127.140},
+		{"Tarama", 24.660, 124.700}, {"Ogimi", 26.680, 128.120}, {"Hateruma", 24.060, 123.780},
+	},
+}
@@ -0,0 +1,186 @@
+package geo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Locality is the municipality and neighbourhood containing a coordinate
+type Locality struct {
+	Prefecture    string // e.g. "東京都"
+	City          string // e.g. "武蔵野市", "八王子市", "横浜市", "千代田区"
+	Ward          string // Ward of a designated city, e.g. "港北区"
+	Neighbourhood string // e.g. "吉祥寺本町一丁目", "日吉本町"
+}
+
+// Name joins the city, ward and neighbourhood as written in addresses, e.g.
+// "横浜市港北区日吉本町", or returns "" when none is known
+func (l Locality) Name() string {
+	// e-Stat names wards of designated cities as part of the city
+	if strings.HasSuffix(l.City, l.Ward) {
+		return l.City + l.Neighbourhood
+	}
+	return l.City + l.Ward + l.Neighbourhood
+}
+
+// LocalityFields names the GeoJSON properties holding each part of a
+// Locality. Empty names are not read.
+type LocalityFields struct {
+	Prefecture    string
+	City          string
+	Ward          string
+	Neighbourhood string
+}
+
+var (
+	// N03Fields reads the administrative area data (N03) of the National
+	// Land Numerical Information, one feature per municipality
+	N03Fields = LocalityFields{Prefecture: "N03_001", City: "N03_004", Ward: "N03_005"}
+	// EStatFields reads the census small area boundaries (町丁・字等) of
+	// e-Stat, one feature per neighbourhood
+	EStatFields = LocalityFields{Prefecture: "PREF_NAME", City: "CITY_NAME", Neighbourhood: "S_NAME"}
+)
+
+// ParseLocalityFields overrides defaults with a comma separated list of
+// part=property pairs, e.g. "city=N03_004,ward=N03_005". A pair with an
+// empty property ("ward=") stops reading that part.
+func ParseLocalityFields(value string, defaults LocalityFields) (LocalityFields, error) {
+	fields := defaults
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		part, property, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fields, fmt.Errorf("invalid locality field %q, expected part=property", pair)
+		}
+		property = strings.TrimSpace(property)
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "prefecture":
+			fields.Prefecture = property
+		case "city":
+			fields.City = property
+		case "ward":
+			fields.Ward = property
+		case "neighbourhood", "neighborhood":
+			fields.Neighbourhood = property
+		default:
+			return fields, fmt.Errorf("unknown locality part %q, expected prefecture, city, ward or neighbourhood", part)
+		}
+	}
+	return fields, nil
+}
+
+// LocalityIndex reverse geocodes coordinates to municipalities or
+// neighbourhoods
+type LocalityIndex struct {
+	localities []Locality
+	grid       polygonGrid
+}
+
+// NewLocalityIndex builds an index from regions carrying the properties
+// named by fields. Regions without any of them are skipped.
+func NewLocalityIndex(regions []*Region, fields LocalityFields) (*LocalityIndex, error) {
+	index := &LocalityIndex{}
+	for _, region := range regions {
+		locality := Locality{
+			Prefecture:    stringProperty(region.Properties, fields.Prefecture),
+			City:          stringProperty(region.Properties, fields.City),
+			Ward:          stringProperty(region.Properties, fields.Ward),
+			Neighbourhood: stringProperty(region.Properties, fields.Neighbourhood),
+		}
+		if locality == (Locality{}) {
+			continue
+		}
+		id := len(index.localities)
+		index.localities = append(index.localities, locality)
+		for _, polygon := range region.Polygons {
+			index.grid.add(id, polygon)
+		}
+	}
+	if index.grid.empty() {
+		return nil, fmt.Errorf("no polygons with properties %+v", fields)
+	}
+	return index, nil
+}
+
+// Locate returns the locality containing the coordinate, or false when the
+// coordinate is outside the data
+func (x *LocalityIndex) Locate(latitude, longitude float64) (Locality, bool) {
+	id, ok := x.grid.find(latitude, longitude)
+	if !ok {
+		return Locality{}, false
+	}
+	return x.localities[id], true
+}
+
+// LoadLocalityIndex reads municipal or neighbourhood boundaries from a
+// GeoJSON file, e.g. with N03Fields or EStatFields
+func LoadLocalityIndex(path string, fields LocalityFields) (*LocalityIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	regions, err := ParseGeoJSONRegions(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	index, err := NewLocalityIndex(regions, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// Localities combines several datasets, e.g. municipalities covering the
+// whole country and neighbourhoods covering a few cities
+type Localities []*LocalityIndex
+
+// Locate fills each part of the locality from the first dataset that has it.
+// A dataset disagreeing on the city, e.g. a neighbourhood just across the
+// border of coarser municipal data, is ignored. Cities match when one
+// contains the other, as in "横浜市" and "横浜市港北区".
+func (l Localities) Locate(latitude, longitude float64) (Locality, bool) {
+	var result Locality
+	found := false
+	for _, index := range l {
+		if index == nil {
+			continue
+		}
+		locality, ok := index.Locate(latitude, longitude)
+		if !ok {
+			continue
+		}
+		if result.City != "" && locality.City != "" &&
+			!strings.HasPrefix(result.City, locality.City) && !strings.HasPrefix(locality.City, result.City) {
+			continue
+		}
+		found = true
+		result.Prefecture = firstNonEmpty(result.Prefecture, locality.Prefecture)
+		result.City = firstNonEmpty(result.City, locality.City)
+		result.Ward = firstNonEmpty(result.Ward, locality.Ward)
+		result.Neighbourhood = firstNonEmpty(result.Neighbourhood, locality.Neighbourhood)
+	}
+	return result, found
+}
+
+// stringProperty returns the trimmed string value of a property, or "" when
+// the property is missing, null or not a string
+func stringProperty(properties map[string]any, name string) string {
+	if name == "" {
+		return ""
+	}
+	value, _ := properties[name].(string)
+	return strings.TrimSpace(value)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
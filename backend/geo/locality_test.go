@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLocalityFixtures writes municipalities in the N03 format, where wards
+// of designated cities are a separate property, and neighbourhoods in the
+// e-Stat format, where they are part of the city name
+func writeLocalityFixtures(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	municipalities := filepath.Join(dir, "n03.geojson")
+	require.NoError(t, os.WriteFile(municipalities, []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"N03_001":"東京都","N03_004":"武蔵野市","N03_005":null},"geometry":{"type":"Polygon","coordinates":[[[139.5,35.6],[139.6,35.6],[139.6,35.8],[139.5,35.8],[139.5,35.6]]]}},
+		{"type":"Feature","properties":{"N03_001":"東京都","N03_004":"八王子市","N03_005":null},"geometry":{"type":"Polygon","coordinates":[[[139.2,35.6],[139.4,35.6],[139.4,35.8],[139.2,35.8],[139.2,35.6]]]}},
+		{"type":"Feature","properties":{"N03_001":"神奈川県","N03_004":"横浜市","N03_005":"港北区"},"geometry":{"type":"MultiPolygon","coordinates":[[[[139.6,35.5],[139.7,35.5],[139.7,35.6],[139.6,35.6],[139.6,35.5]]]]}},
+		{"type":"Feature","properties":{"N03_001":"所属未定地"},"geometry":null}
+	]}`), 0o644))
+
+	neighbourhoods := filepath.Join(dir, "estat.geojson")
+	require.NoError(t, os.WriteFile(neighbourhoods, []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"PREF_NAME":"東京都","CITY_NAME":"武蔵野市","S_NAME":"吉祥寺本町一丁目"},"geometry":{"type":"Polygon","coordinates":[[[139.57,35.70],[139.59,35.70],[139.59,35.72],[139.57,35.72],[139.57,35.70]]]}},
+		{"type":"Feature","properties":{"PREF_NAME":"神奈川県","CITY_NAME":"横浜市港北区","S_NAME":"日吉本町"},"geometry":{"type":"Polygon","coordinates":[[[139.64,35.54],[139.66,35.54],[139.66,35.56],[139.64,35.56],[139.64,35.54]]]}},
+		{"type":"Feature","properties":{"PREF_NAME":"東京都","CITY_NAME":"三鷹市","S_NAME":"下連雀"},"geometry":{"type":"Polygon","coordinates":[[[139.55,35.65],[139.57,35.65],[139.57,35.67],[139.55,35.67],[139.55,35.65]]]}}
+	]}`), 0o644))
+
+	return municipalities, neighbourhoods
+}
+
+func TestLoadLocalityIndex(t *testing.T) {
+	municipalities, neighbourhoods := writeLocalityFixtures(t)
+
+	index, err := LoadLocalityIndex(municipalities, N03Fields)
+	require.NoError(t, err)
+
+	locality, ok := index.Locate(35.703, 139.579)
+	require.True(t, ok)
+	assert.Equal(t, Locality{Prefecture: "東京都", City: "武蔵野市"}, locality)
+
+	locality, ok = index.Locate(35.656, 139.339)
+	require.True(t, ok)
+	assert.Equal(t, "八王子市", locality.Name())
+
+	locality, ok = index.Locate(35.553, 139.646)
+	require.True(t, ok)
+	assert.Equal(t, "横浜市港北区", locality.Name())
+
+	_, ok = index.Locate(34.5, 139.5)
+	assert.False(t, ok)
+
+	index, err = LoadLocalityIndex(neighbourhoods, EStatFields)
+	require.NoError(t, err)
+	locality, ok = index.Locate(35.553, 139.646)
+	require.True(t, ok)
+	assert.Equal(t, "横浜市港北区日吉本町", locality.Name())
+
+	_, err = LoadLocalityIndex(neighbourhoods, N03Fields)
+	assert.Error(t, err, "e-Stat data has no N03 properties")
+}
+
+func TestLocalities(t *testing.T) {
+	municipalityPath, neighbourhoodPath := writeLocalityFixtures(t)
+	municipalities, err := LoadLocalityIndex(municipalityPath, N03Fields)
+	require.NoError(t, err)
+	neighbourhoods, err := LoadLocalityIndex(neighbourhoodPath, EStatFields)
+	require.NoError(t, err)
+	localities := Localities{municipalities, neighbourhoods}
+
+	locality, ok := localities.Locate(35.703, 139.579)
+	require.True(t, ok)
+	assert.Equal(t, Locality{Prefecture: "東京都", City: "武蔵野市", Neighbourhood: "吉祥寺本町一丁目"}, locality)
+	assert.Equal(t, "武蔵野市吉祥寺本町一丁目", locality.Name())
+
+	locality, ok = localities.Locate(35.553, 139.646)
+	require.True(t, ok)
+	assert.Equal(t, Locality{Prefecture: "神奈川県", City: "横浜市", Ward: "港北区", Neighbourhood: "日吉本町"}, locality)
+	assert.Equal(t, "横浜市港北区日吉本町", locality.Name())
+
+	// Outside the neighbourhood data
+	locality, ok = localities.Locate(35.656, 139.339)
+	require.True(t, ok)
+	assert.Equal(t, "八王子市", locality.Name())
+
+	// A neighbourhood of another city than the municipal data is ignored
+	locality, ok = localities.Locate(35.66, 139.56)
+	require.True(t, ok)
+	assert.Equal(t, "武蔵野市", locality.Name())
+
+	_, ok = localities.Locate(34.5, 139.5)
+	assert.False(t, ok)
+	_, ok = Localities(nil).Locate(35.703, 139.579)
+	assert.False(t, ok)
+}
+
+func TestParseLocalityFields(t *testing.T) {
+	fields, err := ParseLocalityFields("", N03Fields)
+	require.NoError(t, err)
+	assert.Equal(t, N03Fields, fields)
+
+	fields, err = ParseLocalityFields(" city = N03_003 , ward= ,neighbourhood=N03_006", N03Fields)
+	require.NoError(t, err)
+	assert.Equal(t, LocalityFields{Prefecture: "N03_001", City: "N03_003", Neighbourhood: "N03_006"}, fields)
+
+	_, err = ParseLocalityFields("town=N03_004", N03Fields)
+	assert.Error(t, err)
+	_, err = ParseLocalityFields("N03_004", N03Fields)
+	assert.Error(t, err)
+}
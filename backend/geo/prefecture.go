@@ -0,0 +1,183 @@
+package geo
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ./internal/prefgen -o data/japan_prefectures.geojson
+
+// Prefecture is one of the 47 prefectures of Japan
+type Prefecture struct {
+	Code   int    // JIS X 0401 code, from 1 (Hokkaido) to 47 (Okinawa)
+	Name   string // e.g. "東京都", "北海道", "大阪府", "埼玉県"
+	Area   string // Name without its suffix, e.g. "東京", "北海道", "大阪", "埼玉"
+	Region string // e.g. "関東", "近畿", "九州・沖縄"
+}
+
+// AreaType returns "都内", "道内", "府内" or "県内"
+func (p Prefecture) AreaType() string {
+	suffix := []rune(p.Name)
+	return string(suffix[len(suffix)-1]) + "内"
+}
+
+var prefectures = []Prefecture{
+	{1, "北海道", "北海道", "北海道"},
+	{2, "青森県", "青森", "東北"},
+	{3, "岩手県", "岩手", "東北"},
+	{4, "宮城県", "宮城", "東北"},
+	{5, "秋田県", "秋田", "東北"},
+	{6, "山形県", "山形", "東北"},
+	{7, "福島県", "福島", "東北"},
+	{8, "茨城県", "茨城", "関東"},
+	{9, "栃木県", "栃木", "関東"},
+	{10, "群馬県", "群馬", "関東"},
+	{11, "埼玉県", "埼玉", "関東"},
+	{12, "千葉県", "千葉", "関東"},
+	{13, "東京都", "東京", "関東"},
+	{14, "神奈川県", "神奈川", "関東"},
+	{15, "新潟県", "新潟", "中部"},
+	{16, "富山県", "富山", "中部"},
+	{17, "石川県", "石川", "中部"},
+	{18, "福井県", "福井", "中部"},
+	{19, "山梨県", "山梨", "中部"},
+	{20, "長野県", "長野", "中部"},
+	{21, "岐阜県", "岐阜", "中部"},
+	{22, "静岡県", "静岡", "中部"},
+	{23, "愛知県", "愛知", "中部"},
+	{24, "三重県", "三重", "近畿"},
+	{25, "滋賀県", "滋賀", "近畿"},
+	{26, "京都府", "京都", "近畿"},
+	{27, "大阪府", "大阪", "近畿"},
+	{28, "兵庫県", "兵庫", "近畿"},
+	{29, "奈良県", "奈良", "近畿"},
+	{30, "和歌山県", "和歌山", "近畿"},
+	{31, "鳥取県", "鳥取", "中国"},
+	{32, "島根県", "島根", "中国"},
+	{33, "岡山県", "岡山", "中国"},
+	{34, "広島県", "広島", "中国"},
+	{35, "山口県", "山口", "中国"},
+	{36, "徳島県", "徳島", "四国"},
+	{37, "香川県", "香川", "四国"},
+	{38, "愛媛県", "愛媛", "四国"},
+	{39, "高知県", "高知", "四国"},
+	{40, "福岡県", "福岡", "九州・沖縄"},
+	{41, "佐賀県", "佐賀", "九州・沖縄"},
+	{42, "長崎県", "長崎", "九州・沖縄"},
+	{43, "熊本県", "熊本", "九州・沖縄"},
+	{44, "大分県", "大分", "九州・沖縄"},
+	{45, "宮崎県", "宮崎", "九州・沖縄"},
+	{46, "鹿児島県", "鹿児島", "九州・沖縄"},
+	{47, "沖縄県", "沖縄", "九州・沖縄"},
+}
+
+// Prefectures returns all 47 prefectures ordered by code
+func Prefectures() []Prefecture {
+	return append([]Prefecture(nil), prefectures...)
+}
+
+// PrefectureByCode looks up a prefecture by its JIS X 0401 code
+func PrefectureByCode(code int) (Prefecture, bool) {
+	if code < 1 || code > len(prefectures) {
+		return Prefecture{}, false
+	}
+	return prefectures[code-1], true
+}
+
+// PrefectureByName looks up a prefecture by its name, with or without the
+// suffix ("東京都" or "東京"), or by its two-digit code ("13")
+func PrefectureByName(name string) (Prefecture, bool) {
+	name = strings.TrimSpace(name)
+	for _, prefecture := range prefectures {
+		if name == prefecture.Name || name == prefecture.Area || name == fmt.Sprintf("%02d", prefecture.Code) {
+			return prefecture, true
+		}
+	}
+	return Prefecture{}, false
+}
+
+// PrefectureIndex reverse geocodes coordinates to prefectures
+type PrefectureIndex struct {
+	grid polygonGrid
+}
+
+// NewPrefectureIndex builds an index from regions named after prefectures
+// (see PrefectureByName). Several regions may belong to one prefecture, e.g.
+// one per municipality.
+func NewPrefectureIndex(regions []*Region) (*PrefectureIndex, error) {
+	index := &PrefectureIndex{}
+	for _, region := range regions {
+		prefecture, ok := PrefectureByName(region.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown prefecture %q", region.Name)
+		}
+		for _, polygon := range region.Polygons {
+			index.grid.add(prefecture.Code, polygon)
+		}
+	}
+	if index.grid.empty() {
+		return nil, fmt.Errorf("no prefecture polygons")
+	}
+	return index, nil
+}
+
+// Locate returns the prefecture containing the coordinate, or false when the
+// coordinate is outside Japan
+func (x *PrefectureIndex) Locate(latitude, longitude float64) (Prefecture, bool) {
+	code, ok := x.grid.find(latitude, longitude)
+	if !ok {
+		return Prefecture{}, false
+	}
+	return prefectures[code-1], true
+}
+
+// LoadPrefectureIndex reads prefecture boundaries from a GeoJSON file whose
+// features are named by nameProperty, e.g. "N03_001" for the administrative
+// area data (N03) of the National Land Numerical Information
+func LoadPrefectureIndex(path, nameProperty string) (*PrefectureIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	regions, err := ParseGeoJSONRegions(data, nameProperty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	index, err := NewPrefectureIndex(regions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+//go:embed data/japan_prefectures.geojson
+var japanPrefecturesGeoJSON []byte
+
+var (
+	japanPrefecturesOnce  sync.Once
+	japanPrefecturesIndex *PrefectureIndex
+)
+
+// JapanPrefectures returns an index of coarse boundaries of all 47
+// prefectures. The boundaries are approximated from about 1,700 points,
+// mostly municipal offices (see internal/prefgen): they are reliable away from
+// prefectural borders, may be off by a few km near them, and extend up to
+// 45 km offshore so that coastal points and small islands are covered.
+// Points on both sides of the borders around Tokyo keep them within about
+// a km there. Regenerate the file from the N03 data with prefgen -n03, or
+// load precise data with LoadPrefectureIndex, where borders matter.
+func JapanPrefectures() *PrefectureIndex {
+	japanPrefecturesOnce.Do(func() {
+		regions, err := ParseGeoJSONRegions(japanPrefecturesGeoJSON, "name")
+		if err == nil {
+			japanPrefecturesIndex, err = NewPrefectureIndex(regions)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("embedded prefecture data is invalid: %v", err))
+		}
+	})
+	return japanPrefecturesIndex
+}
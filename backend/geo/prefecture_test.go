@@ -0,0 +1,186 @@
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJapanPrefectures(t *testing.T) {
+	index := JapanPrefectures()
+
+	tests := []struct {
+		name       string
+		latitude   float64
+		longitude  float64
+		prefecture string
+	}{
+		{name: "Sapporo", latitude: 43.068, longitude: 141.351, prefecture: "北海道"},
+		{name: "Daisetsuzan", latitude: 43.66, longitude: 142.85, prefecture: "北海道"},
+		{name: "Aomori", latitude: 40.829, longitude: 140.734, prefecture: "青森県"},
+		{name: "Sendai", latitude: 38.260, longitude: 140.882, prefecture: "宮城県"},
+		{name: "Omiya", latitude: 35.906, longitude: 139.624, prefecture: "埼玉県"},
+		{name: "Kawaguchi", latitude: 35.802, longitude: 139.717, prefecture: "埼玉県"},
+		{name: "Chiba", latitude: 35.613, longitude: 140.113, prefecture: "千葉県"},
+		{name: "Matsudo", latitude: 35.784, longitude: 139.900, prefecture: "千葉県"},
+		{name: "Tokyo Station", latitude: 35.681, longitude: 139.767, prefecture: "東京都"},
+		{name: "Machida", latitude: 35.542, longitude: 139.446, prefecture: "東京都"},
+		{name: "Hachijojima", latitude: 33.11, longitude: 139.79, prefecture: "東京都"},
+		{name: "Shin-Yokohama", latitude: 35.508, longitude: 139.617, prefecture: "神奈川県"},
+		{name: "Hashimoto", latitude: 35.595, longitude: 139.345, prefecture: "神奈川県"},
+		{name: "Enoshima", latitude: 35.30, longitude: 139.48, prefecture: "神奈川県"},
+		{name: "Atami", latitude: 35.104, longitude: 139.078, prefecture: "静岡県"},
+		{name: "Karuizawa", latitude: 36.342, longitude: 138.635, prefecture: "長野県"},
+		{name: "Kuwana", latitude: 35.065, longitude: 136.684, prefecture: "三重県"},
+		{name: "Kyoto Station", latitude: 34.985, longitude: 135.758, prefecture: "京都府"},
+		{name: "Uji", latitude: 34.889, longitude: 135.807, prefecture: "京都府"},
+		{name: "Osaka Station", latitude: 34.702, longitude: 135.496, prefecture: "大阪府"},
+		{name: "Kuzuha", latitude: 34.863, longitude: 135.678, prefecture: "大阪府"},
+		{name: "Sannomiya", latitude: 34.695, longitude: 135.195, prefecture: "兵庫県"},
+		{name: "Nara", latitude: 34.681, longitude: 135.820, prefecture: "奈良県"},
+		{name: "Shimonoseki", latitude: 33.950, longitude: 130.922, prefecture: "山口県"},
+		{name: "Mojiko", latitude: 33.944, longitude: 130.962, prefecture: "福岡県"},
+		{name: "Tosu", latitude: 33.378, longitude: 130.520, prefecture: "佐賀県"},
+		{name: "Kagoshima", latitude: 31.584, longitude: 130.541, prefecture: "鹿児島県"},
+		{name: "Naha", latitude: 26.212, longitude: 127.681, prefecture: "沖縄県"},
+		{name: "Ishigaki", latitude: 24.34, longitude: 124.16, prefecture: "沖縄県"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefecture, ok := index.Locate(tt.latitude, tt.longitude)
+			require.True(t, ok)
+			assert.Equal(t, tt.prefecture, prefecture.Name)
+		})
+	}
+
+	outside := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+	}{
+		{name: "Seoul", latitude: 37.5665, longitude: 126.978},
+		{name: "Busan", latitude: 35.10, longitude: 129.04},
+		{name: "Vladivostok", latitude: 43.12, longitude: 131.9},
+		{name: "Taipei", latitude: 25.03, longitude: 121.56},
+		{name: "Sea of Japan", latitude: 38.0, longitude: 135.0},
+		{name: "Pacific off Chiba", latitude: 35.0, longitude: 141.5},
+		{name: "Null Island", latitude: 0, longitude: 0},
+	}
+	for _, tt := range outside {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := index.Locate(tt.latitude, tt.longitude)
+			assert.False(t, ok)
+		})
+	}
+}
+
+// Points on either side of prefectural borders in the Tokyo area, where most
+// requests come from
+func TestJapanPrefectures_Borders(t *testing.T) {
+	index := JapanPrefectures()
+
+	tests := []struct {
+		name       string
+		latitude   float64
+		longitude  float64
+		prefecture string
+	}{
+		// Edogawa-ku and Urayasu, across the Kyu-Edogawa
+		{name: "Kasai", latitude: 35.664, longitude: 139.873, prefecture: "東京都"},
+		{name: "Kasai-Rinkai-Koen", latitude: 35.644, longitude: 139.861, prefecture: "東京都"},
+		{name: "Urayasu", latitude: 35.666, longitude: 139.893, prefecture: "千葉県"},
+		{name: "Maihama", latitude: 35.636, longitude: 139.884, prefecture: "千葉県"},
+		// Koiwa and Ichikawa, across the Edogawa
+		{name: "Koiwa", latitude: 35.733, longitude: 139.884, prefecture: "東京都"},
+		{name: "Ichikawa", latitude: 35.730, longitude: 139.908, prefecture: "千葉県"},
+		{name: "Shibamata", latitude: 35.758, longitude: 139.876, prefecture: "東京都"},
+		// Machida and Asao-ku, Kawasaki
+		{name: "Tsurukawa", latitude: 35.584, longitude: 139.481, prefecture: "東京都"},
+		{name: "Tamagawagakuen-mae", latitude: 35.563, longitude: 139.464, prefecture: "東京都"},
+		{name: "Kakio", latitude: 35.595, longitude: 139.483, prefecture: "神奈川県"},
+		{name: "Shin-Yurigaoka", latitude: 35.604, longitude: 139.508, prefecture: "神奈川県"},
+		// Komae and Tama-ku, Kawasaki, across the Tamagawa
+		{name: "Komae", latitude: 35.635, longitude: 139.579, prefecture: "東京都"},
+		{name: "Noborito", latitude: 35.621, longitude: 139.570, prefecture: "神奈川県"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefecture, ok := index.Locate(tt.latitude, tt.longitude)
+			require.True(t, ok)
+			assert.Equal(t, tt.prefecture, prefecture.Name)
+		})
+	}
+}
+
+func TestJapanPrefecturesCoverAllPrefectures(t *testing.T) {
+	index := JapanPrefectures()
+
+	codes := make(map[int]bool)
+	for _, entry := range index.grid.entries {
+		codes[entry.owner] = true
+	}
+	assert.Len(t, codes, 47)
+}
+
+func TestPrefectureByName(t *testing.T) {
+	for _, name := range []string{"埼玉県", "埼玉", "11"} {
+		prefecture, ok := PrefectureByName(name)
+		require.True(t, ok, name)
+		assert.Equal(t, 11, prefecture.Code)
+		assert.Equal(t, "関東", prefecture.Region)
+	}
+
+	_, ok := PrefectureByName("日本")
+	assert.False(t, ok)
+
+	hokkaido, ok := PrefectureByCode(1)
+	require.True(t, ok)
+	assert.Equal(t, "北海道", hokkaido.Area)
+	_, ok = PrefectureByCode(48)
+	assert.False(t, ok)
+}
+
+func TestPrefectureAreaType(t *testing.T) {
+	expected := map[int]string{1: "道内", 13: "都内", 26: "府内", 27: "府内", 11: "県内", 47: "県内"}
+	for code, areaType := range expected {
+		prefecture, ok := PrefectureByCode(code)
+		require.True(t, ok)
+		assert.Equal(t, areaType, prefecture.AreaType(), prefecture.Name)
+	}
+}
+
+func TestLoadPrefectureIndex(t *testing.T) {
+	// Administrative area data has one feature per municipality
+	path := filepath.Join(t.TempDir(), "n03.geojson")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"N03_001":"東京都","N03_004":"千代田区"},"geometry":{"type":"Polygon","coordinates":[[[139,35],[140,35],[140,36],[139,36],[139,35]]]}},
+		{"type":"Feature","properties":{"N03_001":"東京都","N03_004":"八丈町"},"geometry":{"type":"Polygon","coordinates":[[[139.7,33],[139.9,33],[139.9,33.2],[139.7,33.2],[139.7,33]]]}},
+		{"type":"Feature","properties":{"N03_001":"埼玉県","N03_004":"さいたま市"},"geometry":{"type":"Polygon","coordinates":[[[139,36],[140,36],[140,37],[139,37],[139,36]]]}}
+	]}`), 0o644))
+
+	index, err := LoadPrefectureIndex(path, "N03_001")
+	require.NoError(t, err)
+
+	prefecture, ok := index.Locate(35.5, 139.5)
+	require.True(t, ok)
+	assert.Equal(t, "東京都", prefecture.Name)
+
+	prefecture, ok = index.Locate(33.1, 139.8)
+	require.True(t, ok)
+	assert.Equal(t, "東京都", prefecture.Name)
+
+	prefecture, ok = index.Locate(36.5, 139.5)
+	require.True(t, ok)
+	assert.Equal(t, "埼玉県", prefecture.Name)
+
+	_, ok = index.Locate(34.5, 139.5)
+	assert.False(t, ok)
+
+	_, err = LoadPrefectureIndex(path, "N03_004")
+	assert.Error(t, err, "municipality names are not prefectures")
+}
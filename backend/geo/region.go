@@ -0,0 +1,176 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Ring is a closed polygon ring of [longitude, latitude] pairs as in GeoJSON
+type Ring [][2]float64
+
+// Polygon is an outer ring followed by optional holes
+type Polygon []Ring
+
+// Region is a set of polygons, e.g. land areas or an administrative area
+type Region struct {
+	Name     string
+	Polygons []Polygon
+	// Properties of the GeoJSON feature the region was read from, if any
+	Properties map[string]any
+}
+
+// Contains reports whether the coordinate lies inside the region
+func (r *Region) Contains(latitude, longitude float64) bool {
+	for _, polygon := range r.Polygons {
+		if polygon.contains(latitude, longitude) {
+			return true
+		}
+	}
+	return false
+}
+
+// DistanceKm returns zero for a coordinate inside the region and otherwise
+// the distance in km to the nearest boundary
+func (r *Region) DistanceKm(latitude, longitude float64) float64 {
+	if r.Contains(latitude, longitude) {
+		return 0
+	}
+
+	nearest := math.Inf(1)
+	for _, polygon := range r.Polygons {
+		for _, ring := range polygon {
+			for i := range ring {
+				a, b := ring[i], ring[(i+1)%len(ring)]
+				nearest = math.Min(nearest, segmentDistanceKm(latitude, longitude, a[1], a[0], b[1], b[0]))
+			}
+		}
+	}
+	return nearest
+}
+
+func (p Polygon) contains(latitude, longitude float64) bool {
+	if len(p) == 0 || !p[0].contains(latitude, longitude) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(latitude, longitude) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains uses ray casting; points exactly on an edge may go either way
+func (r Ring) contains(latitude, longitude float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > latitude) != (yj > latitude) &&
+			longitude < (xj-xi)*(latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// geoJSON covers the parts of GeoJSON needed to read polygons
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Features    []geoJSON       `json:"features"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Properties  map[string]any  `json:"properties"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ParseGeoJSONRegions reads every Polygon and MultiPolygon of a GeoJSON
+// FeatureCollection, Feature or geometry. Each feature becomes a region named
+// after its nameProperty, if given.
+func ParseGeoJSONRegions(data []byte, nameProperty string) ([]*Region, error) {
+	var document geoJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var regions []*Region
+	var collect func(node geoJSON, name string, properties map[string]any) error
+	collect = func(node geoJSON, name string, properties map[string]any) error {
+		switch node.Type {
+		case "FeatureCollection":
+			for _, feature := range node.Features {
+				if err := collect(feature, "", nil); err != nil {
+					return err
+				}
+			}
+		case "Feature":
+			if node.Geometry == nil {
+				return nil
+			}
+			if value, ok := node.Properties[nameProperty].(string); ok {
+				name = value
+			}
+			return collect(*node.Geometry, name, node.Properties)
+		case "Polygon":
+			var polygon Polygon
+			if err := json.Unmarshal(node.Coordinates, &polygon); err != nil {
+				return fmt.Errorf("invalid Polygon coordinates: %w", err)
+			}
+			regions = append(regions, &Region{Name: name, Polygons: []Polygon{polygon}, Properties: properties})
+		case "MultiPolygon":
+			var polygons []Polygon
+			if err := json.Unmarshal(node.Coordinates, &polygons); err != nil {
+				return fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+			}
+			regions = append(regions, &Region{Name: name, Polygons: polygons, Properties: properties})
+		}
+		return nil
+	}
+
+	if err := collect(document, "", nil); err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("GeoJSON contains no polygons")
+	}
+	return regions, nil
+}
+
+// LoadRegion reads a GeoJSON file and merges all its polygons into one region
+func LoadRegion(path string) (*Region, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	regions, err := ParseGeoJSONRegions(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return mergeRegions(path, regions), nil
+}
+
+func mergeRegions(name string, regions []*Region) *Region {
+	merged := &Region{Name: name}
+	for _, region := range regions {
+		merged.Polygons = append(merged.Polygons, region.Polygons...)
+	}
+	return merged
+}
+
+//go:embed data/japan_land.geojson
+var japanLandGeoJSON []byte
+
+// JapanLand returns coarse outlines of the main islands of Japan (Hokkaido,
+// Honshu, Shikoku, Kyushu and Okinawa). The outlines have a few dozen
+// vertices each: they tell land from open sea but cut across bays and miss
+// small islands, so callers should allow a tolerance of a few km or load
+// precise data with LoadRegion.
+func JapanLand() *Region {
+	regions, err := ParseGeoJSONRegions(japanLandGeoJSON, "name")
+	if err != nil {
+		panic(fmt.Sprintf("embedded Japan land data is invalid: %v", err))
+	}
+	return mergeRegions("japan", regions)
+}
@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJapanLand(t *testing.T) {
+	land := JapanLand()
+
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		onLand    bool
+	}{
+		{name: "Tokyo Station", latitude: 35.681236, longitude: 139.767125, onLand: true},
+		{name: "Osaka", latitude: 34.702485, longitude: 135.495951, onLand: true},
+		{name: "Kyoto", latitude: 35.011636, longitude: 135.768029, onLand: true},
+		{name: "Sapporo", latitude: 43.062096, longitude: 141.354376, onLand: true},
+		{name: "Fukuoka", latitude: 33.590355, longitude: 130.401716, onLand: true},
+		{name: "Matsuyama", latitude: 33.839157, longitude: 132.765575, onLand: true},
+		{name: "Naha", latitude: 26.212401, longitude: 127.680932, onLand: true},
+		{name: "Sendai", latitude: 38.268215, longitude: 140.869356, onLand: true},
+		{name: "Niigata", latitude: 37.916192, longitude: 139.036413, onLand: true},
+		{name: "Kanazawa", latitude: 36.561325, longitude: 136.656205, onLand: true},
+		{name: "Hiroshima", latitude: 34.385203, longitude: 132.455293, onLand: true},
+		{name: "Kagoshima", latitude: 31.596554, longitude: 130.557116, onLand: true},
+		{name: "Pacific off Chiba", latitude: 35.0, longitude: 141.5, onLand: false},
+		{name: "Sea of Japan", latitude: 38.0, longitude: 135.0, onLand: false},
+		{name: "Null Island", latitude: 0, longitude: 0, onLand: false},
+		{name: "Seoul", latitude: 37.5665, longitude: 126.978, onLand: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := land.DistanceKm(tt.latitude, tt.longitude)
+			if tt.onLand {
+				// Coastal cities may fall just outside the coarse outlines
+				assert.Less(t, distance, 5.0)
+			} else {
+				assert.Greater(t, distance, 20.0)
+			}
+		})
+	}
+}
+
+func TestParseGeoJSONRegions(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"square"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]],[[0.4,0.4],[0.6,0.4],[0.6,0.6],[0.4,0.6],[0.4,0.4]]]}},
+		{"type":"Feature","properties":{"name":"pair"},"geometry":{"type":"MultiPolygon","coordinates":[[[[2,0],[3,0],[3,1],[2,0]]],[[[4,0],[5,0],[5,1],[4,0]]]]}}
+	]}`)
+
+	regions, err := ParseGeoJSONRegions(data, "name")
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+
+	assert.Equal(t, "square", regions[0].Name)
+	assert.True(t, regions[0].Contains(0.2, 0.2))
+	assert.False(t, regions[0].Contains(0.5, 0.5), "inside the hole")
+	assert.InDelta(t, 111.2, regions[0].DistanceKm(0.5, 2), 0.5)
+
+	assert.Equal(t, "pair", regions[1].Name)
+	assert.True(t, regions[1].Contains(0.2, 4.8))
+
+	_, err = ParseGeoJSONRegions([]byte(`{"type":"FeatureCollection","features":[]}`), "")
+	assert.Error(t, err)
+}
+
+func TestHaversineKm(t *testing.T) {
+	// Tokyo Station to Shin-Osaka Station
+	assert.InDelta(t, 403, HaversineKm(35.681236, 139.767125, 34.733488, 135.500246), 2)
+	assert.Zero(t, HaversineKm(35, 139, 35, 139))
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"potarin-backend/usage"
+	"potarin-backend/utils"
+	shared "potarin-shared"
+)
+
+// AdminHandler serves operational endpoints behind admin authentication
+type AdminHandler struct {
+	usage *usage.Tracker
+}
+
+func NewAdminHandler(tracker *usage.Tracker) *AdminHandler {
+	return &AdminHandler{
+		usage: tracker,
+	}
+}
+
+// GetUsage reports token usage and cost of a day (query "day", YYYY-MM-DD,
+// defaulting to today in Japan time) per endpoint, API client and model
+func (h *AdminHandler) GetUsage(c *fiber.Ctx) error {
+	day := c.Query("day", h.usage.Today())
+	if _, err := time.Parse(usage.DayFormat, day); err != nil {
+		return utils.SendError(c, utils.NewValidationError("無効な日付です").
+			WithDetail("day", "invalid_format", "日付はYYYY-MM-DD形式で指定してください", day))
+	}
+
+	entries := h.usage.Entries(day)
+	report := shared.UsageReport{
+		Day:     day,
+		Entries: make([]shared.UsageEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		report.Entries[i] = shared.UsageEntry{
+			Endpoint:         entry.Endpoint,
+			Client:           entry.Client,
+			Model:            entry.Model,
+			Requests:         entry.Requests,
+			PromptTokens:     entry.PromptTokens,
+			CompletionTokens: entry.CompletionTokens,
+			CostUSD:          entry.Cost,
+		}
+		report.TotalCostUSD += entry.Cost
+	}
+	if budget := h.usage.Budget(); budget > 0 {
+		report.DailyBudgetUSD = &budget
+		report.BudgetExceeded = day == h.usage.Today() && h.usage.BudgetExceeded()
+	}
+
+	return utils.SendSuccess(c, report)
+}
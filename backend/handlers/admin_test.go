@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/middleware"
+	"potarin-backend/usage"
+	shared "potarin-shared"
+)
+
+func TestGetUsage(t *testing.T) {
+	tracker := usage.NewTracker(usage.PriceTable{"gpt-4o": {Input: 2.5, Output: 10}}, 5)
+	ctx := usage.WithLabels(context.Background(), usage.Labels{Endpoint: "suggestions", Client: "web"})
+	tracker.Record(ctx, "gpt-4o", 1000, 500)
+
+	app := fiber.New()
+	app.Get("/api/v1/admin/usage", middleware.AdminAuth("secret"), NewAdminHandler(tracker).GetUsage)
+
+	tests := []struct {
+		name           string
+		query          string
+		authorization  string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "today", authorization: "Bearer secret", expectedStatus: fiber.StatusOK, expectedCount: 1},
+		{name: "other day", query: "?day=2000-01-01", authorization: "Bearer secret", expectedStatus: fiber.StatusOK},
+		{name: "invalid day", query: "?day=yesterday", authorization: "Bearer secret", expectedStatus: fiber.StatusBadRequest},
+		{name: "wrong token", authorization: "Bearer nope", expectedStatus: fiber.StatusUnauthorized},
+		{name: "no token", expectedStatus: fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/admin/usage"+tt.query, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if resp.StatusCode != fiber.StatusOK {
+				return
+			}
+
+			raw, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			var envelope struct {
+				Data shared.UsageReport `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(raw, &envelope))
+
+			report := envelope.Data
+			assert.Len(t, report.Entries, tt.expectedCount)
+			require.NotNil(t, report.DailyBudgetUSD)
+			assert.Equal(t, 5.0, *report.DailyBudgetUSD)
+			assert.False(t, report.BudgetExceeded)
+			if tt.expectedCount > 0 {
+				assert.Equal(t, "web", report.Entries[0].Client)
+				assert.InDelta(t, 0.0075, report.TotalCostUSD, 1e-9)
+			}
+		})
+	}
+}
@@ -1,25 +1,46 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"potarin-backend/courses"
 	"potarin-backend/middleware"
+	"potarin-backend/pace"
 	"potarin-backend/services"
+	"potarin-backend/usage"
 	"potarin-backend/utils"
 	shared "potarin-shared"
 )
 
 type CourseHandler struct {
-	openaiService *services.OpenAIService
+	generator services.CourseGenerator
+	pace      pace.Model
+	courses   *courses.Store
 }
 
-func NewCourseHandler(openaiService *services.OpenAIService) *CourseHandler {
+func NewCourseHandler(generator services.CourseGenerator) *CourseHandler {
 	return &CourseHandler{
-		openaiService: openaiService,
+		generator: generator,
+		pace:      pace.DefaultModel,
 	}
 }
 
+// WithCourseStore keeps the returned course details so that they can be
+// exported by ID
+func (h *CourseHandler) WithCourseStore(store *courses.Store) *CourseHandler {
+	h.courses = store
+	return h
+}
+
+// WithPaceModel replaces the default model used for estimated times
+func (h *CourseHandler) WithPaceModel(model pace.Model) *CourseHandler {
+	h.pace = model
+	return h
+}
+
 func (h *CourseHandler) GetSuggestions(c *fiber.Ctx) error {
 	middleware.LogInfo(c, "Course suggestions request received")
 
@@ -42,55 +63,28 @@ func (h *CourseHandler) GetSuggestions(c *fiber.Ctx) error {
 	}
 
 	// Convert shared types to service types
-	serviceRequest := services.CourseRequest{
-		CourseType: request.Request.CourseType,
-		Distance:   request.Request.Distance,
-	}
-
-	if request.Request.Location != nil {
-		serviceRequest.Location = &services.Position{
-			Latitude:  request.Request.Location.Latitude,
-			Longitude: request.Request.Location.Longitude,
-		}
-	}
-
-	if request.Request.Preferences != nil {
-		serviceRequest.Preferences = &services.CoursePreferences{
-			Scenery:    request.Request.Preferences.Scenery,
-			Difficulty: request.Request.Preferences.Difficulty,
-			AvoidHills: request.Request.Preferences.AvoidHills,
-		}
-	}
+	serviceRequest := h.toServiceCourseRequest(request.Request)
 
-	middleware.LogInfo(c, "Calling OpenAI service for course suggestions", map[string]interface{}{
+	middleware.LogInfo(c, "Calling course generator for course suggestions", map[string]interface{}{
 		"course_type": serviceRequest.CourseType,
 		"distance":    serviceRequest.Distance,
 	})
 
-	// Call OpenAI service with error handling
-	openaiResponse, err := h.openaiService.GenerateCourseSuggestions(c.Context(), serviceRequest)
+	// Call course generator with error handling
+	generated, err := h.generator.GenerateCourseSuggestions(usage.WithLabels(c.Context(), usageLabels(c, "suggestions")), serviceRequest)
 	if err != nil {
 		middleware.LogError(c, err, "Failed to generate course suggestions")
-		return utils.SendError(c, utils.NewExternalAPIError("OpenAI", err))
+		return utils.SendError(c, generationError(err))
 	}
 
 	// Convert service response to shared types
-	suggestions := make([]shared.CourseSuggestion, len(openaiResponse.Suggestions))
-	for i, suggestion := range openaiResponse.Suggestions {
-		suggestions[i] = shared.CourseSuggestion{
-			ID:            suggestion.ID,
-			Title:         suggestion.Title,
-			Description:   suggestion.Description,
-			Distance:      suggestion.Distance,
-			EstimatedTime: suggestion.EstimatedTime,
-			Difficulty:    suggestion.Difficulty,
-			CourseType:    suggestion.CourseType,
-			StartPoint: shared.Position{
-				Latitude:  suggestion.StartPoint.Latitude,
-				Longitude: suggestion.StartPoint.Longitude,
-			},
-			Highlights: suggestion.Highlights,
-			Summary:    suggestion.Summary,
+	warnings := generated.Warnings
+	suggestions := make([]shared.CourseSuggestion, len(generated.Suggestions))
+	for i, suggestion := range generated.Suggestions {
+		suggestions[i] = toSharedSuggestion(suggestion)
+		h.estimateSuggestionTime(&suggestions[i], request.Request.PaceMinPerKm)
+		if warning := timeBudgetWarning(suggestions[i].ID, suggestions[i].Title, suggestions[i].EstimatedTime, request.Request.TimeBudgetMinutes); warning != nil {
+			warnings = append(warnings, *warning)
 		}
 	}
 
@@ -98,13 +92,20 @@ func (h *CourseHandler) GetSuggestions(c *fiber.Ctx) error {
 		Suggestions: suggestions,
 		RequestID:   services.GenerateRequestID(),
 		GeneratedAt: time.Now(),
+		Warnings:    toSharedWarnings(warnings),
+		Metadata:    toSharedMetadata(generated.Metadata),
 	}
 
 	middleware.LogInfo(c, "Course suggestions generated successfully", map[string]interface{}{
 		"suggestions_count": len(suggestions),
+		"warnings_count":    len(response.Warnings),
 		"request_id":        response.RequestID,
+		"cache_hit":         response.Metadata.CacheHit,
 	})
 
+	if wantsGeoJSON(c) {
+		return sendSuggestionsGeoJSON(c, response)
+	}
 	return utils.SendSuccess(c, response)
 }
 
@@ -144,23 +145,212 @@ func (h *CourseHandler) GetDetails(c *fiber.Ctx) error {
 		},
 		Highlights: request.Suggestion.Highlights,
 		Summary:    request.Suggestion.Summary,
+		RouteShape: request.Suggestion.RouteShape,
+	}
+	if request.Suggestion.EndPoint != nil {
+		suggestion.EndPoint = &services.Position{
+			Latitude:  request.Suggestion.EndPoint.Latitude,
+			Longitude: request.Suggestion.EndPoint.Longitude,
+		}
 	}
 
-	middleware.LogInfo(c, "Calling OpenAI service for course details", map[string]interface{}{
+	// The length the original request asked for. A time budget too short
+	// for the distance is rejected as it is for suggestions.
+	requestedDistance := ""
+	if request.RequestedDistance != nil {
+		requestedDistance = *request.RequestedDistance
+	}
+	requested, appErr := h.distanceRange(suggestion.CourseType, requestedDistance, request.DistanceKm, request.TimeBudgetMinutes, suggestion.Difficulty, request.PaceMinPerKm)
+	if appErr != nil {
+		middleware.LogWarn(c, "Request validation failed", map[string]interface{}{
+			"error": appErr.Error(),
+		})
+		return utils.SendError(c, appErr)
+	}
+
+	middleware.LogInfo(c, "Calling course generator for course details", map[string]interface{}{
 		"suggestion_id": suggestion.ID,
 		"course_type":   suggestion.CourseType,
 	})
 
-	// Call OpenAI service with error handling
-	openaiResponse, err := h.openaiService.GenerateCourseDetails(c.Context(), suggestion)
+	// Call course generator with error handling
+	generated, err := h.generator.GenerateCourseDetails(usage.WithLabels(c.Context(), usageLabels(c, "details")), suggestion)
 	if err != nil {
 		middleware.LogError(c, err, "Failed to generate course details")
-		return utils.SendError(c, utils.NewExternalAPIError("OpenAI", err))
+		return utils.SendError(c, generationError(err))
 	}
+	// The course is stored under a new ID, not the suggestion ID of the body
+	generated.Course.ID = courses.NewID()
+
+	// Check the waypoints against the claimed and requested distance
+	warnings := services.ReviewCourseDetails(&generated.Course, requested)
 
 	// Convert service response to shared types
-	waypoints := make([]shared.Waypoint, len(openaiResponse.Course.Waypoints))
-	for i, waypoint := range openaiResponse.Course.Waypoints {
+	course := toSharedCourse(generated.Course)
+	h.estimateCourseTime(&course, request.PaceMinPerKm)
+	if warning := timeBudgetWarning(course.ID, course.Title, course.EstimatedTime, request.TimeBudgetMinutes); warning != nil {
+		warnings = append(warnings, *warning)
+	}
+	h.saveCourse(c, course)
+
+	response := shared.DetailsResponse{
+		Course:      course,
+		RequestID:   services.GenerateRequestID(),
+		GeneratedAt: time.Now(),
+		Warnings:    toSharedWarnings(warnings),
+		Metadata:    toSharedMetadata(generated.Metadata),
+	}
+
+	middleware.LogInfo(c, "Course details generated successfully", map[string]interface{}{
+		"course_id":       course.ID,
+		"waypoints_count": len(course.Waypoints),
+		"warnings_count":  len(response.Warnings),
+		"routed":          course.Polyline != nil,
+		"has_elevation":   course.Elevation != nil,
+		"request_id":      response.RequestID,
+		"cache_hit":       response.Metadata.CacheHit,
+	})
+
+	return utils.SendSuccess(c, response)
+}
+
+// saveCourse keeps course in the course store, if any. Failures are only
+// logged: the course is returned either way, it just cannot be exported by ID.
+func (h *CourseHandler) saveCourse(c *fiber.Ctx, course shared.CourseDetails) {
+	if h.courses == nil {
+		return
+	}
+	if err := h.courses.Save(course); err != nil {
+		middleware.LogWarn(c, "Failed to store course details", map[string]interface{}{
+			"course_id": course.ID,
+			"error":     err.Error(),
+		})
+	}
+}
+
+// estimateSuggestionTime replaces the generated estimated time with the pace
+// model's, keeping the generated one as ModelEstimatedTime
+func (h *CourseHandler) estimateSuggestionTime(suggestion *shared.CourseSuggestion, paceMinPerKm *float64) {
+	modelTime := suggestion.EstimatedTime
+	suggestion.ModelEstimatedTime = &modelTime
+	suggestion.EstimatedTime = h.pace.Minutes(pace.Course{
+		CourseType:   suggestion.CourseType,
+		Difficulty:   suggestion.Difficulty,
+		DistanceKm:   suggestion.Distance,
+		PaceMinPerKm: userPace(paceMinPerKm),
+	})
+}
+
+// estimateCourseTime replaces the generated estimated time with the pace
+// model's, including the elevation gain when known
+func (h *CourseHandler) estimateCourseTime(course *shared.CourseDetails, paceMinPerKm *float64) {
+	gain := 0.0
+	if course.Elevation != nil {
+		gain = course.Elevation.Gain
+	}
+	modelTime := course.EstimatedTime
+	course.ModelEstimatedTime = &modelTime
+	course.EstimatedTime = h.pace.Minutes(pace.Course{
+		CourseType:   course.CourseType,
+		Difficulty:   course.Difficulty,
+		DistanceKm:   course.Distance,
+		GainM:        gain,
+		PaceMinPerKm: userPace(paceMinPerKm),
+	})
+}
+
+func userPace(paceMinPerKm *float64) float64 {
+	if paceMinPerKm == nil {
+		return 0
+	}
+	return *paceMinPerKm
+}
+
+// usageLabels attributes usage to the endpoint and API client. Clients
+// identify themselves with the X-Client-ID header and are otherwise
+// identified by IP address.
+func usageLabels(c *fiber.Ctx, endpoint string) usage.Labels {
+	client := c.Get("X-Client-ID")
+	if client == "" {
+		client = c.IP()
+	}
+	return usage.Labels{Endpoint: endpoint, Client: client}
+}
+
+// toServiceCourseRequest converts a validated shared course request to the
+// service type, resolving the requested distance range
+func (h *CourseHandler) toServiceCourseRequest(request shared.CourseRequest) services.CourseRequest {
+	serviceRequest := services.CourseRequest{
+		CourseType: request.CourseType,
+		Distance:   request.Distance,
+		RouteShape: request.RouteShape,
+	}
+
+	if request.DistanceKm != nil || request.TimeBudgetMinutes != nil {
+		if limits, err := h.requestDistanceRange(request); err == nil {
+			serviceRequest.DistanceRange = &limits
+		}
+	}
+	if request.TimeBudgetMinutes != nil {
+		serviceRequest.TimeBudgetMinutes = *request.TimeBudgetMinutes
+	}
+
+	if request.Location != nil {
+		serviceRequest.Location = &services.Position{
+			Latitude:  request.Location.Latitude,
+			Longitude: request.Location.Longitude,
+		}
+	}
+
+	if request.EndLocation != nil {
+		serviceRequest.EndLocation = &services.Position{
+			Latitude:  request.EndLocation.Latitude,
+			Longitude: request.EndLocation.Longitude,
+		}
+	}
+
+	if request.Preferences != nil {
+		serviceRequest.Preferences = &services.CoursePreferences{
+			Scenery:    request.Preferences.Scenery,
+			Difficulty: request.Preferences.Difficulty,
+			AvoidHills: request.Preferences.AvoidHills,
+		}
+	}
+
+	return serviceRequest
+}
+
+// toSharedSuggestion converts a generated suggestion to the shared type
+func toSharedSuggestion(suggestion services.CourseSuggestion) shared.CourseSuggestion {
+	converted := shared.CourseSuggestion{
+		ID:            suggestion.ID,
+		Title:         suggestion.Title,
+		Description:   suggestion.Description,
+		Distance:      suggestion.Distance,
+		EstimatedTime: suggestion.EstimatedTime,
+		Difficulty:    suggestion.Difficulty,
+		CourseType:    suggestion.CourseType,
+		StartPoint: shared.Position{
+			Latitude:  suggestion.StartPoint.Latitude,
+			Longitude: suggestion.StartPoint.Longitude,
+		},
+		Highlights: suggestion.Highlights,
+		Summary:    suggestion.Summary,
+		RouteShape: suggestion.RouteShape,
+	}
+	if suggestion.EndPoint != nil {
+		converted.EndPoint = &shared.Position{
+			Latitude:  suggestion.EndPoint.Latitude,
+			Longitude: suggestion.EndPoint.Longitude,
+		}
+	}
+	return converted
+}
+
+// toSharedCourse converts generated course details to the shared type
+func toSharedCourse(course services.CourseDetails) shared.CourseDetails {
+	waypoints := make([]shared.Waypoint, len(course.Waypoints))
+	for i, waypoint := range course.Waypoints {
 		waypoints[i] = shared.Waypoint{
 			ID:          waypoint.ID,
 			Title:       waypoint.Title,
@@ -169,34 +359,69 @@ func (h *CourseHandler) GetDetails(c *fiber.Ctx) error {
 				Latitude:  waypoint.Position.Latitude,
 				Longitude: waypoint.Position.Longitude,
 			},
-			Type: waypoint.Type,
+			Type:            waypoint.Type,
+			Verified:        waypoint.Verified,
+			MatchConfidence: waypoint.MatchConfidence,
 		}
 	}
 
-	course := shared.CourseDetails{
-		ID:            openaiResponse.Course.ID,
-		Title:         openaiResponse.Course.Title,
-		Description:   openaiResponse.Course.Description,
-		Distance:      openaiResponse.Course.Distance,
-		EstimatedTime: openaiResponse.Course.EstimatedTime,
-		Difficulty:    openaiResponse.Course.Difficulty,
-		CourseType:    openaiResponse.Course.CourseType,
+	return shared.CourseDetails{
+		ID:            course.ID,
+		Title:         course.Title,
+		Description:   course.Description,
+		Distance:      course.Distance,
+		EstimatedTime: course.EstimatedTime,
+		Difficulty:    course.Difficulty,
+		CourseType:    course.CourseType,
 		Waypoints:     waypoints,
+		RouteShape:    course.RouteShape,
+		Polyline:      course.Polyline,
+		Elevation:     toSharedElevation(course.Elevation),
 	}
+}
 
-	response := shared.DetailsResponse{
-		Course:      course,
-		RequestID:   services.GenerateRequestID(),
-		GeneratedAt: time.Now(),
+// toSharedMetadata converts generation metadata to the shared type. Responses
+// always carry metadata so clients can tell fresh results from cached ones.
+func toSharedMetadata(metadata *services.GenerationMetadata) *shared.ResponseMetadata {
+	if metadata == nil {
+		return &shared.ResponseMetadata{}
+	}
+	converted := &shared.ResponseMetadata{
+		CacheHit: metadata.CacheHit,
+		CachedAt: metadata.CachedAt,
 	}
+	if metadata.PromptVersion != "" {
+		converted.PromptVersion = &metadata.PromptVersion
+	}
+	return converted
+}
 
-	middleware.LogInfo(c, "Course details generated successfully", map[string]interface{}{
-		"course_id":       course.ID,
-		"waypoints_count": len(course.Waypoints),
-		"request_id":      response.RequestID,
-	})
+// toSharedElevation converts a course's elevation profile to the shared type
+func toSharedElevation(elevation *services.Elevation) *shared.Elevation {
+	if elevation == nil {
+		return nil
+	}
+	profile := make([]shared.ElevationProfile, len(elevation.Profile))
+	for i, sample := range elevation.Profile {
+		profile[i] = shared.ElevationProfile{Distance: sample.Distance, Elevation: sample.Elevation}
+	}
+	return &shared.Elevation{Gain: elevation.Gain, Profile: profile}
+}
 
-	return utils.SendSuccess(c, response)
+// toSharedWarnings converts validation warnings to the shared type
+func toSharedWarnings(warnings []services.ValidationWarning) []shared.Warning {
+	if len(warnings) == 0 {
+		return nil
+	}
+	converted := make([]shared.Warning, len(warnings))
+	for i, warning := range warnings {
+		converted[i] = shared.Warning{
+			ID:      warning.ID,
+			Code:    warning.Code,
+			Message: warning.Message,
+		}
+	}
+	return converted
 }
 
 // validateSuggestionsRequest performs additional validation for suggestions request
@@ -219,22 +444,31 @@ func (h *CourseHandler) validateSuggestionsRequest(request *shared.SuggestionsRe
 			WithDetail("courseType", "invalid_value", "有効なコースタイプを選択してください: walking, cycling, jogging", request.Request.CourseType)
 	}
 
-	if request.Request.Distance == "" {
+	if request.Request.Distance == "" && request.Request.DistanceKm == nil && request.Request.TimeBudgetMinutes == nil {
 		return utils.NewValidationError("距離が必要です").
-			WithDetail("distance", "required", "距離を選択してください", nil)
+			WithDetail("distance", "required", "距離を選択するか、distanceKmまたはtimeBudgetMinutesを指定してください", nil)
 	}
 
-	validDistances := []string{"short", "medium", "long"}
-	isValidDistance := false
-	for _, validDistance := range validDistances {
-		if request.Request.Distance == validDistance {
-			isValidDistance = true
-			break
+	if request.Request.Distance != "" {
+		validDistances := []string{"short", "medium", "long"}
+		isValidDistance := false
+		for _, validDistance := range validDistances {
+			if request.Request.Distance == validDistance {
+				isValidDistance = true
+				break
+			}
+		}
+		if !isValidDistance {
+			return utils.NewValidationError("無効な距離です").
+				WithDetail("distance", "invalid_value", "有効な距離を選択してください: short, medium, long", request.Request.Distance)
 		}
 	}
-	if !isValidDistance {
-		return utils.NewValidationError("無効な距離です").
-			WithDetail("distance", "invalid_value", "有効な距離を選択してください: short, medium, long", request.Request.Distance)
+
+	if err := validateDistanceRange("distanceKm", request.Request.DistanceKm); err != nil {
+		return err
+	}
+	if err := validateTimeBudget("timeBudgetMinutes", request.Request.TimeBudgetMinutes); err != nil {
+		return err
 	}
 
 	// Validate location if provided
@@ -249,6 +483,10 @@ func (h *CourseHandler) validateSuggestionsRequest(request *shared.SuggestionsRe
 		}
 	}
 
+	if err := validateRouteShape(request.Request.RouteShape, request.Request.EndLocation); err != nil {
+		return err
+	}
+
 	// Validate preferences if provided
 	if request.Request.Preferences != nil {
 		if request.Request.Preferences.Scenery != nil {
@@ -282,7 +520,11 @@ func (h *CourseHandler) validateSuggestionsRequest(request *shared.SuggestionsRe
 		}
 	}
 
-	return nil
+	if err := validatePace("paceMinPerKm", request.Request.PaceMinPerKm); err != nil {
+		return err
+	}
+	_, err := h.requestDistanceRange(request.Request)
+	return err
 }
 
 // validateDetailsRequest performs additional validation for details request
@@ -312,5 +554,124 @@ func (h *CourseHandler) validateDetailsRequest(request *shared.DetailsRequest) *
 			WithDetail("suggestion.startPoint.longitude", "invalid_range", "経度は-180から180の間である必要があります", request.Suggestion.StartPoint.Longitude)
 	}
 
+	if err := validateDistanceRange("distanceKm", request.DistanceKm); err != nil {
+		return err
+	}
+	if err := validateTimeBudget("timeBudgetMinutes", request.TimeBudgetMinutes); err != nil {
+		return err
+	}
+	return validatePace("paceMinPerKm", request.PaceMinPerKm)
+}
+
+// validateDistanceRange checks an optional distance range in km
+func validateDistanceRange(field string, distanceKm *shared.DistanceRange) *utils.AppError {
+	if distanceKm == nil {
+		return nil
+	}
+	if distanceKm.Min < 0 || distanceKm.Max < 0 || (distanceKm.Min == 0 && distanceKm.Max == 0) {
+		return utils.NewValidationError("無効な距離の範囲です").
+			WithDetail(field, "invalid_range", "minまたはmaxに0より大きい距離（km）を指定してください", *distanceKm)
+	}
+	if distanceKm.Max > 0 && distanceKm.Min > distanceKm.Max {
+		return utils.NewValidationError("無効な距離の範囲です").
+			WithDetail(field, "invalid_range", "minはmax以下である必要があります", *distanceKm)
+	}
+	return nil
+}
+
+// validateTimeBudget checks an optional time budget in minutes
+func validateTimeBudget(field string, minutes *int) *utils.AppError {
+	if minutes != nil && (*minutes <= 0 || *minutes > 1440) {
+		return utils.NewValidationError("無効な所要時間です").
+			WithDetail(field, "invalid_range", "所要時間は1分以上1440分以下である必要があります", *minutes)
+	}
+	return nil
+}
+
+// requestDistanceRange resolves the distance range of a course request
+func (h *CourseHandler) requestDistanceRange(request shared.CourseRequest) (services.DistanceRange, *utils.AppError) {
+	difficulty := ""
+	if request.Preferences != nil && request.Preferences.Difficulty != nil {
+		difficulty = *request.Preferences.Difficulty
+	}
+	return h.distanceRange(request.CourseType, request.Distance, request.DistanceKm, request.TimeBudgetMinutes, difficulty, request.PaceMinPerKm)
+}
+
+// distanceRange resolves the course length asked for: distanceKm, or else
+// the range of the distance bucket for the course type, capped at how far
+// the time budget goes at the user's or the pace model's pace. It fails when
+// the budget does not reach the minimum.
+func (h *CourseHandler) distanceRange(courseType, distance string, distanceKm *shared.DistanceRange, timeBudgetMinutes *int, difficulty string, paceMinPerKm *float64) (services.DistanceRange, *utils.AppError) {
+	limits, _ := services.BucketRange(courseType, distance)
+	if distanceKm != nil {
+		limits = services.DistanceRange{MinKm: distanceKm.Min, MaxKm: distanceKm.Max}
+	}
+	if timeBudgetMinutes == nil {
+		return limits, nil
+	}
+
+	budgetKm := h.pace.DistanceKm(pace.Course{
+		CourseType:   courseType,
+		Difficulty:   difficulty,
+		PaceMinPerKm: userPace(paceMinPerKm),
+	}, float64(*timeBudgetMinutes))
+	budgetKm = math.Round(budgetKm*10) / 10
+	if limits.MinKm > budgetKm {
+		return services.DistanceRange{}, utils.NewValidationError("所要時間内に希望の距離を移動できません").
+			WithDetail("timeBudgetMinutes", "invalid_combination", fmt.Sprintf("%d分で移動できる距離は約%.1fkmです", *timeBudgetMinutes, budgetKm), *timeBudgetMinutes)
+	}
+	if limits.MaxKm == 0 || budgetKm < limits.MaxKm {
+		limits.MaxKm = budgetKm
+	}
+	return limits, nil
+}
+
+// timeBudgetWarning reports a course estimated to take longer than the time
+// budget, if any
+func timeBudgetWarning(id, title string, estimatedTime int, timeBudgetMinutes *int) *services.ValidationWarning {
+	if timeBudgetMinutes == nil || estimatedTime <= *timeBudgetMinutes {
+		return nil
+	}
+	return &services.ValidationWarning{
+		ID:      id,
+		Code:    services.WarnTimeBudgetExceeded,
+		Message: fmt.Sprintf("「%s」の推定所要時間%d分が希望の%d分を超えています", title, estimatedTime, *timeBudgetMinutes),
+	}
+}
+
+// validateRouteShape checks an optional route shape and the end location,
+// which only a one way course can have
+func validateRouteShape(routeShape string, endLocation *shared.Position) *utils.AppError {
+	switch routeShape {
+	case "", services.RouteShapeLoop, services.RouteShapeOneWay, services.RouteShapeOutAndBack:
+	default:
+		return utils.NewValidationError("無効なコース形状です").
+			WithDetail("routeShape", "invalid_value", "有効なコース形状を選択してください: loop, one_way, out_and_back", routeShape)
+	}
+
+	if endLocation == nil {
+		return nil
+	}
+	if routeShape != services.RouteShapeOneWay {
+		return utils.NewValidationError("ゴール地点は片道コースでのみ指定できます").
+			WithDetail("endLocation", "not_allowed", "ゴール地点を指定する場合はrouteShapeにone_wayを指定してください", nil)
+	}
+	if endLocation.Latitude < -90 || endLocation.Latitude > 90 {
+		return utils.NewValidationError("無効なゴール地点の緯度です").
+			WithDetail("endLocation.latitude", "invalid_range", "緯度は-90から90の間である必要があります", endLocation.Latitude)
+	}
+	if endLocation.Longitude < -180 || endLocation.Longitude > 180 {
+		return utils.NewValidationError("無効なゴール地点の経度です").
+			WithDetail("endLocation.longitude", "invalid_range", "経度は-180から180の間である必要があります", endLocation.Longitude)
+	}
+	return nil
+}
+
+// validatePace checks an optional user pace in minutes per km
+func validatePace(field string, paceMinPerKm *float64) *utils.AppError {
+	if paceMinPerKm != nil && (*paceMinPerKm <= 0 || *paceMinPerKm > 60) {
+		return utils.NewValidationError("無効なペースです").
+			WithDetail(field, "invalid_range", "ペースは1kmあたり0分より大きく60分以下である必要があります", *paceMinPerKm)
+	}
 	return nil
 }
@@ -0,0 +1,605 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+	"potarin-backend/pace"
+	"potarin-backend/services"
+	shared "potarin-shared"
+)
+
+func newTestApp(generator services.CourseGenerator) *fiber.App {
+	handler := NewCourseHandler(generator)
+
+	app := fiber.New()
+	app.Post("/api/v1/suggestions", handler.GetSuggestions)
+	app.Post("/api/v1/details", handler.GetDetails)
+	return app
+}
+
+// doJSON posts body to path and decodes the data field of the API response into out
+func doJSON(t *testing.T, app *fiber.App, path string, body any, out any) int {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	if out != nil {
+		envelope := struct {
+			Success bool            `json:"success"`
+			Data    json.RawMessage `json:"data"`
+		}{}
+		require.NoError(t, json.Unmarshal(raw, &envelope))
+		if envelope.Success {
+			require.NoError(t, json.Unmarshal(envelope.Data, out))
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestGetSuggestions_FakeGenerator(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+
+	request := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{
+			CourseType: "walking",
+			Distance:   "short",
+			Location:   &shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+		},
+	}
+
+	var first, second shared.SuggestionsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &first))
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &second))
+
+	require.Len(t, first.Suggestions, 3)
+	assert.NotEmpty(t, first.RequestID)
+	for _, suggestion := range first.Suggestions {
+		assert.Equal(t, "walking", suggestion.CourseType)
+		assert.Greater(t, suggestion.Distance, 0.0)
+		assert.Greater(t, suggestion.EstimatedTime, 0)
+	}
+
+	// The fake generator is deterministic
+	assert.Equal(t, first.Suggestions, second.Suggestions)
+}
+
+func TestGetSuggestions_ValidationError(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+
+	request := map[string]any{
+		"request": map[string]any{
+			"courseType": "swimming",
+			"distance":   "short",
+		},
+	}
+
+	status := doJSON(t, app, "/api/v1/suggestions", request, nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestGetSuggestions_RouteShape(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+
+	invalid := map[string]map[string]any{
+		"unknown shape": {"courseType": "walking", "distance": "short", "routeShape": "figure_eight"},
+		"end of a loop": {
+			"courseType": "walking", "distance": "short", "routeShape": "loop",
+			"endLocation": map[string]any{"latitude": 35.6851, "longitude": 139.7528},
+		},
+		"end out of range": {
+			"courseType": "walking", "distance": "short", "routeShape": "one_way",
+			"endLocation": map[string]any{"latitude": 135.6851, "longitude": 139.7528},
+		},
+	}
+	for name, request := range invalid {
+		t.Run(name, func(t *testing.T) {
+			status := doJSON(t, app, "/api/v1/suggestions", map[string]any{"request": request}, nil)
+			assert.Equal(t, fiber.StatusBadRequest, status)
+		})
+	}
+
+	end := shared.Position{Latitude: 35.6851, Longitude: 139.7528}
+	var suggestions shared.SuggestionsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", shared.SuggestionsRequest{
+		Request: shared.CourseRequest{
+			CourseType:  "walking",
+			Distance:    "short",
+			Location:    &shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+			RouteShape:  "one_way",
+			EndLocation: &end,
+		},
+	}, &suggestions))
+	require.NotEmpty(t, suggestions.Suggestions)
+	suggestion := suggestions.Suggestions[0]
+	assert.Equal(t, "one_way", suggestion.RouteShape)
+	assert.Equal(t, &end, suggestion.EndPoint)
+
+	var details shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/details", shared.DetailsRequest{
+		CourseID:   suggestion.ID,
+		Suggestion: suggestion,
+	}, &details))
+	assert.Equal(t, "one_way", details.Course.RouteShape)
+	assert.Equal(t, end, details.Course.Waypoints[len(details.Course.Waypoints)-1].Position)
+}
+
+func TestGetSuggestions_DistanceConstraints(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+	budget, tooShortBudget := 30, 10
+
+	tests := []struct {
+		name     string
+		request  shared.CourseRequest
+		min, max float64
+	}{
+		{"cycling bucket", shared.CourseRequest{CourseType: "cycling", Distance: "short"}, 5, 15},
+		{"range in km", shared.CourseRequest{CourseType: "walking", Distance: "long", DistanceKm: &shared.DistanceRange{Min: 2, Max: 4}}, 2, 4},
+		{"time budget", shared.CourseRequest{CourseType: "walking", TimeBudgetMinutes: &budget}, 0, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response shared.SuggestionsResponse
+			require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", shared.SuggestionsRequest{Request: tt.request}, &response))
+			require.Len(t, response.Suggestions, 3)
+			for _, suggestion := range response.Suggestions {
+				assert.GreaterOrEqual(t, suggestion.Distance, tt.min)
+				assert.LessOrEqual(t, suggestion.Distance, tt.max)
+			}
+			assert.Empty(t, response.Warnings)
+		})
+	}
+
+	invalid := map[string]shared.CourseRequest{
+		"no distance":          {CourseType: "walking"},
+		"inverted range":       {CourseType: "walking", DistanceKm: &shared.DistanceRange{Min: 5, Max: 2}},
+		"budget below minimum": {CourseType: "walking", Distance: "medium", TimeBudgetMinutes: &tooShortBudget},
+	}
+	for name, request := range invalid {
+		t.Run(name, func(t *testing.T) {
+			status := doJSON(t, app, "/api/v1/suggestions", shared.SuggestionsRequest{Request: request}, nil)
+			assert.Equal(t, fiber.StatusBadRequest, status)
+		})
+	}
+}
+
+func TestGetDetails_TimeBudget(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+	budget := 30
+
+	var response shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/details", shared.DetailsRequest{
+		CourseID: "fake-1",
+		Suggestion: shared.CourseSuggestion{
+			ID:          "fake-1",
+			Title:       "テストコース",
+			Description: "テスト用のコースです",
+			Distance:    4,
+			Difficulty:  "easy",
+			CourseType:  "walking",
+			StartPoint:  shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+			Highlights:  []string{"公園"},
+			Summary:     "テスト",
+		},
+		TimeBudgetMinutes: &budget,
+	}, &response))
+
+	codes := make([]string, len(response.Warnings))
+	for i, warning := range response.Warnings {
+		codes[i] = warning.Code
+	}
+	assert.ElementsMatch(t, []string{services.WarnDistanceOutsideRange, services.WarnTimeBudgetExceeded}, codes)
+
+	// A budget too short for the requested distance is rejected before generating
+	tooShort := 10
+	status := doJSON(t, app, "/api/v1/details", shared.DetailsRequest{
+		CourseID: "fake-1",
+		Suggestion: shared.CourseSuggestion{
+			ID:         "fake-1",
+			Title:      "テストコース",
+			Difficulty: "easy",
+			CourseType: "walking",
+			StartPoint: shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+		},
+		DistanceKm:        &shared.DistanceRange{Min: 5},
+		TimeBudgetMinutes: &tooShort,
+	}, nil)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+func TestGetDetails_FakeGenerator(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+
+	suggestion := shared.CourseSuggestion{
+		ID:            "fake-1",
+		Title:         "テストコース",
+		Description:   "テスト用のコースです",
+		Distance:      4,
+		EstimatedTime: 48,
+		Difficulty:    "easy",
+		CourseType:    "walking",
+		StartPoint:    shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+		Highlights:    []string{"公園"},
+		Summary:       "テスト",
+	}
+
+	var response shared.DetailsResponse
+	status := doJSON(t, app, "/api/v1/details", shared.DetailsRequest{
+		CourseID:   suggestion.ID,
+		Suggestion: suggestion,
+	}, &response)
+	require.Equal(t, fiber.StatusOK, status)
+
+	course := response.Course
+	assert.NotEqual(t, suggestion.ID, course.ID, "courses get IDs of their own")
+	assert.Regexp(t, `^course-`, course.ID)
+	require.NotEmpty(t, course.Waypoints)
+	assert.Equal(t, "start", course.Waypoints[0].Type)
+	assert.Equal(t, "end", course.Waypoints[len(course.Waypoints)-1].Type)
+}
+
+// newOpenAIStub serves chat completions built from the fake generator output,
+// standing in for the real OpenAI API while recording cassettes
+func newOpenAIStub(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	fake := services.NewFakeGenerator()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ResponseFormat struct {
+				JSONSchema struct {
+					Name string `json:"name"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		var content any
+		var err error
+		switch request.ResponseFormat.JSONSchema.Name {
+		case "course_suggestions":
+			content, err = fake.GenerateCourseSuggestions(r.Context(), services.CourseRequest{CourseType: "walking", Distance: "short"})
+		default:
+			content, err = fake.GenerateCourseDetails(r.Context(), services.CourseSuggestion{ID: "stub-1", Title: "スタブ", CourseType: "walking", Distance: 2})
+		}
+		require.NoError(t, err)
+
+		encoded, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"id":     "chatcmpl-stub",
+			"object": "chat.completion",
+			"choices": []map[string]any{{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]any{"role": "assistant", "content": string(encoded)},
+			}},
+		}))
+	}))
+}
+
+func TestEndToEnd_CassetteReplay(t *testing.T) {
+	upstream := newOpenAIStub(t)
+	dir := t.TempDir()
+
+	newGenerator := func(mode string) services.CourseGenerator {
+		generator, err := services.NewCourseGenerator(services.GeneratorConfig{
+			Provider:     services.ProviderOpenAI,
+			APIKey:       "test-key",
+			BaseURL:      upstream.URL + "/v1",
+			CassetteMode: mode,
+			CassetteDir:  dir,
+		})
+		require.NoError(t, err)
+		return generator
+	}
+
+	suggestionsRequest := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "walking", Distance: "short"},
+	}
+
+	// Record against the stub
+	var recorded shared.SuggestionsResponse
+	recordApp := newTestApp(newGenerator(services.CassetteModeRecord))
+	require.Equal(t, fiber.StatusOK, doJSON(t, recordApp, "/api/v1/suggestions", suggestionsRequest, &recorded))
+	require.NotEmpty(t, recorded.Suggestions)
+
+	detailsRequest := shared.DetailsRequest{CourseID: recorded.Suggestions[0].ID, Suggestion: recorded.Suggestions[0]}
+	var recordedDetails shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, recordApp, "/api/v1/details", detailsRequest, &recordedDetails))
+
+	// Replay with the network gone
+	upstream.Close()
+	replayApp := newTestApp(newGenerator(services.CassetteModeReplay))
+
+	var replayed shared.SuggestionsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, replayApp, "/api/v1/suggestions", suggestionsRequest, &replayed))
+	assert.Equal(t, recorded.Suggestions, replayed.Suggestions)
+
+	var replayedDetails shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, replayApp, "/api/v1/details", detailsRequest, &replayedDetails))
+	// Every response issues a new course ID
+	assert.NotEqual(t, recordedDetails.Course.ID, replayedDetails.Course.ID)
+	replayedDetails.Course.ID = recordedDetails.Course.ID
+	assert.Equal(t, recordedDetails.Course, replayedDetails.Course)
+
+	// Unrecorded requests fail instead of reaching the network
+	otherRequest := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "cycling", Distance: "long"},
+	}
+	assert.Equal(t, fiber.StatusBadGateway, doJSON(t, replayApp, "/api/v1/suggestions", otherRequest, nil))
+}
+
+// failingGenerator always fails with the configured error
+type failingGenerator struct {
+	err error
+}
+
+func (g *failingGenerator) GenerateCourseSuggestions(ctx context.Context, request services.CourseRequest) (*services.CourseSuggestionsResponse, error) {
+	return nil, g.err
+}
+
+func (g *failingGenerator) GenerateCourseDetails(ctx context.Context, suggestion services.CourseSuggestion) (*services.CourseDetailsResponse, error) {
+	return nil, g.err
+}
+
+func TestGetSuggestions_GenerationErrors(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{
+			name:               "rate limited with upstream hint",
+			err:                &services.GenerationError{Kind: services.ErrKindRateLimited, RetryAfter: 20 * time.Second, Err: errors.New("429")},
+			expectedStatus:     fiber.StatusTooManyRequests,
+			expectedRetryAfter: "20",
+		},
+		{
+			name:               "rate limited without hint",
+			err:                &services.GenerationError{Kind: services.ErrKindRateLimited, Err: errors.New("429")},
+			expectedStatus:     fiber.StatusTooManyRequests,
+			expectedRetryAfter: "10",
+		},
+		{
+			name:           "quota exceeded",
+			err:            &services.GenerationError{Kind: services.ErrKindQuotaExceeded, Err: errors.New("quota")},
+			expectedStatus: fiber.StatusServiceUnavailable,
+		},
+		{
+			name:           "refusal",
+			err:            &services.GenerationError{Kind: services.ErrKindRefusal, Err: errors.New("refused")},
+			expectedStatus: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:               "timeout",
+			err:                &services.GenerationError{Kind: services.ErrKindTimeout, Err: context.DeadlineExceeded},
+			expectedStatus:     fiber.StatusGatewayTimeout,
+			expectedRetryAfter: "5",
+		},
+		{
+			name:               "daily budget exceeded",
+			err:                &services.GenerationError{Kind: services.ErrKindBudgetExceeded, RetryAfter: time.Hour, Err: errors.New("budget")},
+			expectedStatus:     fiber.StatusServiceUnavailable,
+			expectedRetryAfter: "3600",
+		},
+		{
+			name:           "unclassified",
+			err:            errors.New("boom"),
+			expectedStatus: fiber.StatusBadGateway,
+		},
+	}
+
+	request := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "walking", Distance: "short"},
+	}
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(&failingGenerator{err: tt.err})
+
+			req := httptest.NewRequest("POST", "/api/v1/suggestions", bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedRetryAfter, resp.Header.Get("Retry-After"))
+		})
+	}
+}
+
+// sseEvent is a parsed Server-Sent Event
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStreamSuggestions(t *testing.T) {
+	handler := NewCourseHandler(services.NewFakeGenerator())
+	app := fiber.New()
+	app.Get("/api/v1/suggestions/stream", handler.StreamSuggestions)
+	app.Post("/api/v1/suggestions/stream", handler.StreamSuggestions)
+
+	postBody, err := json.Marshal(shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "cycling", Distance: "long"},
+	})
+	require.NoError(t, err)
+
+	getReq := httptest.NewRequest("GET", "/api/v1/suggestions/stream?courseType=cycling&distance=long&latitude=35.0116&longitude=135.7681&avoidHills=true", nil)
+	postReq := httptest.NewRequest("POST", "/api/v1/suggestions/stream", bytes.NewReader(postBody))
+	postReq.Header.Set("Content-Type", "application/json")
+
+	for _, req := range []*http.Request{getReq, postReq} {
+		t.Run(req.Method, func(t *testing.T) {
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			events := parseSSE(t, string(body))
+			require.Len(t, events, 4)
+			for _, event := range events[:3] {
+				assert.Equal(t, "suggestion", event.Event)
+				var suggestion shared.CourseSuggestion
+				require.NoError(t, json.Unmarshal([]byte(event.Data), &suggestion))
+				assert.Equal(t, "cycling", suggestion.CourseType)
+			}
+
+			assert.Equal(t, "done", events[3].Event)
+			var done shared.SuggestionsStreamComplete
+			require.NoError(t, json.Unmarshal([]byte(events[3].Data), &done))
+			assert.NotEmpty(t, done.RequestID)
+			assert.Equal(t, 3, done.Count)
+		})
+	}
+}
+
+func TestStreamSuggestions_Errors(t *testing.T) {
+	t.Run("invalid query", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/stream", NewCourseHandler(services.NewFakeGenerator()).StreamSuggestions)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/stream?courseType=walking", nil), -1)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("generation failure", func(t *testing.T) {
+		app := fiber.New()
+		generator := &failingGenerator{err: &services.GenerationError{Kind: services.ErrKindRateLimited, Err: errors.New("429")}}
+		app.Get("/stream", NewCourseHandler(generator).StreamSuggestions)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/stream?courseType=walking&distance=short", nil), -1)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		events := parseSSE(t, string(body))
+		require.Len(t, events, 1)
+		assert.Equal(t, "error", events[0].Event)
+		assert.Contains(t, events[0].Data, "rate_limited")
+	})
+}
+
+func TestGetSuggestions_CacheMetadata(t *testing.T) {
+	generator := services.NewCachedGenerator(services.NewFakeGenerator(), cache.NewMemoryBackend(cache.Limits{}), services.CacheOptions{})
+	app := newTestApp(generator)
+
+	request := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "jogging", Distance: "medium"},
+	}
+
+	var first, second shared.SuggestionsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &first))
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &second))
+
+	require.NotNil(t, first.Metadata)
+	assert.False(t, first.Metadata.CacheHit)
+	assert.Nil(t, first.Metadata.CachedAt)
+
+	require.NotNil(t, second.Metadata)
+	assert.True(t, second.Metadata.CacheHit)
+	assert.NotNil(t, second.Metadata.CachedAt)
+	assert.Equal(t, first.Suggestions, second.Suggestions)
+}
+
+func TestEstimatedTime_PaceModel(t *testing.T) {
+	app := newTestApp(services.NewFakeGenerator())
+	request := shared.SuggestionsRequest{
+		Request: shared.CourseRequest{CourseType: "jogging", Distance: "medium"},
+	}
+
+	var response shared.SuggestionsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &response))
+	require.NotEmpty(t, response.Suggestions)
+	for _, suggestion := range response.Suggestions {
+		require.NotNil(t, suggestion.ModelEstimatedTime)
+		assert.Equal(t, pace.DefaultModel.Minutes(pace.Course{
+			CourseType: suggestion.CourseType,
+			Difficulty: suggestion.Difficulty,
+			DistanceKm: suggestion.Distance,
+		}), suggestion.EstimatedTime)
+	}
+
+	// The user's pace overrides the model
+	userPace := 6.0
+	request.Request.PaceMinPerKm = &userPace
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/suggestions", request, &response))
+	for _, suggestion := range response.Suggestions {
+		assert.Equal(t, int(math.Round(suggestion.Distance*userPace)), suggestion.EstimatedTime)
+	}
+
+	suggestion := response.Suggestions[0]
+	var details shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/details", shared.DetailsRequest{
+		CourseID:     suggestion.ID,
+		Suggestion:   suggestion,
+		PaceMinPerKm: &userPace,
+	}, &details))
+	require.NotNil(t, details.Course.ModelEstimatedTime)
+	assert.Equal(t, int(math.Round(details.Course.Distance*userPace)), details.Course.EstimatedTime)
+
+	invalidPace := 0.0
+	request.Request.PaceMinPerKm = &invalidPace
+	assert.Equal(t, fiber.StatusBadRequest, doJSON(t, app, "/api/v1/suggestions", request, nil))
+}
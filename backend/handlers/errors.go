@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"time"
+
+	"potarin-backend/services"
+	"potarin-backend/utils"
+)
+
+// Retry-After values used when the upstream did not provide a hint
+const (
+	defaultRateLimitRetryAfter = 10 * time.Second
+	defaultUpstreamRetryAfter  = 5 * time.Second
+)
+
+// generationError converts a course generator failure into an API error with
+// a matching error code and, for transient failures, a Retry-After delay
+func generationError(err error) *utils.AppError {
+	genErr := services.AsGenerationError(err)
+
+	switch genErr.Kind {
+	case services.ErrKindRateLimited:
+		return utils.NewUpstreamError(utils.RateLimited, err).
+			WithRetryAfter(retryAfterOrDefault(genErr.RetryAfter, defaultRateLimitRetryAfter))
+	case services.ErrKindQuotaExceeded:
+		return utils.NewUpstreamError(utils.QuotaExceeded, err)
+	case services.ErrKindContextLength:
+		return utils.NewUpstreamError(utils.ContextLengthExceeded, err)
+	case services.ErrKindRefusal:
+		return utils.NewUpstreamError(utils.ContentRefused, err)
+	case services.ErrKindInvalidAPIKey:
+		return utils.NewUpstreamError(utils.UpstreamAuthError, err)
+	case services.ErrKindTimeout:
+		return utils.NewUpstreamError(utils.UpstreamTimeout, err).
+			WithRetryAfter(retryAfterOrDefault(genErr.RetryAfter, defaultUpstreamRetryAfter))
+	case services.ErrKindBudgetExceeded:
+		return utils.NewAppError(utils.BudgetExceeded, utils.GetErrorMessage(utils.BudgetExceeded)).
+			WithRetryAfter(genErr.RetryAfter)
+	case services.ErrKindUpstream:
+		return utils.NewExternalAPIError("AI", err).
+			WithRetryAfter(retryAfterOrDefault(genErr.RetryAfter, defaultUpstreamRetryAfter))
+	default:
+		return utils.NewExternalAPIError("AI", err)
+	}
+}
+
+func retryAfterOrDefault(retryAfter, fallback time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	return fallback
+}
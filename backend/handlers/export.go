@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"potarin-backend/courses"
+	"potarin-backend/export"
+	"potarin-backend/middleware"
+	"potarin-backend/utils"
+	shared "potarin-shared"
+)
+
+// ExportCourse renders a stored course, by the id path parameter, as a file
+// in the requested format
+func (h *CourseHandler) ExportCourse(c *fiber.Ctx) error {
+	format, err := exportFormat(c)
+	if err != nil {
+		return utils.SendError(c, err)
+	}
+	course, err := h.storedCourse(c)
+	if err != nil {
+		return utils.SendError(c, err)
+	}
+	if err := checkExportFormat(format, course.CourseType); err != nil {
+		return utils.SendError(c, err)
+	}
+
+	// Stored courses were generated here, so failing to render them is ours
+	data, renderErr := format.Render(*course)
+	if renderErr != nil {
+		middleware.LogError(c, renderErr, "Failed to render stored course export")
+		return utils.SendInternalError(c, "")
+	}
+	return sendExport(c, format, course.ID, data)
+}
+
+// ExportCourseBody renders the CourseDetails of the request body as a file
+// in the requested format
+func (h *CourseHandler) ExportCourseBody(c *fiber.Ctx) error {
+	format, err := exportFormat(c)
+	if err != nil {
+		return utils.SendError(c, err)
+	}
+	course, err := parseCourseDetails(c)
+	if err != nil {
+		middleware.LogWarn(c, "Invalid course details", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return utils.SendError(c, err)
+	}
+	if err := checkExportFormat(format, course.CourseType); err != nil {
+		return utils.SendError(c, err)
+	}
+
+	data, renderErr := format.Render(*course)
+	if renderErr != nil {
+		middleware.LogWarn(c, "Failed to render course export", map[string]interface{}{
+			"error": renderErr.Error(),
+		})
+		return utils.SendError(c, utils.NewValidationError("コースをエクスポートできませんでした").
+			WithDetail("polyline", "invalid_format", renderErr.Error(), nil))
+	}
+	return sendExport(c, format, course.ID, data)
+}
+
+// exportFormat picks the format from the extension of the path
+// (export.kml), the format query parameter or the Accept header, in that
+// order. Without any of them the default format is GPX.
+func exportFormat(c *fiber.Ctx) (export.Format, *utils.AppError) {
+	formats := export.Formats()
+	names := make([]string, len(formats))
+	contentTypes := make([]string, len(formats))
+	for i, format := range formats {
+		names[i], contentTypes[i] = format.Name, format.ContentType
+	}
+
+	name := c.Params("format")
+	if name == "" {
+		name = c.Query("format")
+	}
+	if name == "" {
+		accepted := c.Accepts(contentTypes...)
+		if format, ok := export.FormatByContentType(accepted); ok {
+			return format, nil
+		}
+		return export.Format{}, utils.NewValidationError("対応していないエクスポート形式です").
+			WithDetail(fiber.HeaderAccept, "invalid_value", "形式は"+strings.Join(contentTypes, ", ")+"のいずれかを指定してください", c.Get(fiber.HeaderAccept))
+	}
+
+	format, ok := export.FormatByName(name)
+	if !ok {
+		return export.Format{}, utils.NewValidationError("対応していないエクスポート形式です").
+			WithDetail("format", "invalid_value", "形式は"+strings.Join(names, ", ")+"のいずれかを指定してください", name)
+	}
+	return format, nil
+}
+
+// storedCourse loads the course named by the id path parameter
+func (h *CourseHandler) storedCourse(c *fiber.Ctx) (*shared.CourseDetails, *utils.AppError) {
+	id := c.Params("id")
+	notFound := utils.NewAppError(utils.NotFound, "コースが見つかりません").
+		WithDetail("id", "not_found", "コースが存在しないか、保存期間が過ぎています", id)
+	if h.courses == nil {
+		return nil, notFound
+	}
+
+	course, err := h.courses.Get(id)
+	if errors.Is(err, courses.ErrNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		middleware.LogError(c, err, "Failed to load stored course")
+		return nil, utils.NewInternalError("")
+	}
+	return course, nil
+}
+
+// parseCourseDetails decodes and validates a CourseDetails request body
+func parseCourseDetails(c *fiber.Ctx) (*shared.CourseDetails, *utils.AppError) {
+	var course shared.CourseDetails
+	if err := c.BodyParser(&course); err != nil {
+		return nil, utils.NewValidationError("入力データが無効です").
+			WithDetail("body", "invalid_value", "JSONの解析に失敗しました", nil)
+	}
+	if err := middleware.ValidateStruct(&course); err != nil {
+		return nil, err
+	}
+	for i := range course.Waypoints {
+		if err := middleware.ValidateStruct(&course.Waypoints[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &course, nil
+}
+
+// checkExportFormat rejects formats that are not offered for the course type
+func checkExportFormat(format export.Format, courseType string) *utils.AppError {
+	if format.Supports(courseType) {
+		return nil
+	}
+	return utils.NewValidationError("このコースタイプはこの形式でエクスポートできません").
+		WithDetail("courseType", "not_allowed", strings.ToUpper(format.Name)+"形式は"+strings.Join(format.CourseTypes, ", ")+"のコースのみ対応しています", courseType)
+}
+
+// sendExport sends the rendered course as a file download
+func sendExport(c *fiber.Ctx, format export.Format, id string, data []byte) error {
+	middleware.LogInfo(c, "Course exported", map[string]interface{}{
+		"course_id": id,
+		"format":    format.Name,
+	})
+
+	c.Attachment(exportFilename(id) + "." + format.Name)
+	c.Set(fiber.HeaderContentType, format.ContentType)
+	c.Vary(fiber.HeaderAccept)
+	return c.Send(data)
+}
+
+// wantsGeoJSON reports whether the client asked for GeoJSON instead of the
+// JSON API response, with the format query parameter or the Accept header
+func wantsGeoJSON(c *fiber.Ctx) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "geojson"
+	}
+	return c.Accepts(fiber.MIMEApplicationJSON, export.GeoJSONContentType) == export.GeoJSONContentType
+}
+
+// sendSuggestionsGeoJSON sends the suggestions as a GeoJSON FeatureCollection
+// of their start points
+func sendSuggestionsGeoJSON(c *fiber.Ctx, response shared.SuggestionsResponse) error {
+	data, err := export.SuggestionsGeoJSON(response.Suggestions)
+	if err != nil {
+		middleware.LogError(c, err, "Failed to render suggestions GeoJSON")
+		return utils.SendInternalError(c, "")
+	}
+	c.Set(fiber.HeaderContentType, export.GeoJSONContentType)
+	c.Vary(fiber.HeaderAccept)
+	return c.Send(data)
+}
+
+// exportFilename makes a file name out of a course ID, which clients may
+// have chosen themselves in POST exports
+func exportFilename(id string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, id)
+	if strings.Trim(name, "_") == "" {
+		return "course"
+	}
+	return name
+}
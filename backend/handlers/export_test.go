@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+	"potarin-backend/courses"
+	"potarin-backend/services"
+	shared "potarin-shared"
+)
+
+func newExportTestApp() *fiber.App {
+	handler := NewCourseHandler(services.NewFakeGenerator()).
+		WithCourseStore(courses.NewStore(cache.NewMemoryBackend(cache.Limits{}), 0))
+
+	app := fiber.New()
+	app.Post("/api/v1/suggestions", handler.GetSuggestions)
+	app.Post("/api/v1/details", handler.GetDetails)
+	app.Get("/api/v1/courses/:id/export", handler.ExportCourse)
+	app.Get("/api/v1/courses/:id/export.:format", handler.ExportCourse)
+	app.Post("/api/v1/courses/export", handler.ExportCourseBody)
+	app.Post("/api/v1/courses/export.:format", handler.ExportCourseBody)
+	app.Get("/api/v1/courses/:id.:format", handler.ExportCourse)
+	return app
+}
+
+func TestExportGPX_StoredCourse(t *testing.T) {
+	app := newExportTestApp()
+
+	request := shared.DetailsRequest{
+		CourseID: "fake-1",
+		Suggestion: shared.CourseSuggestion{
+			ID:          "fake-1",
+			Title:       "テストコース",
+			Description: "テスト用のコースです",
+			Distance:    4,
+			Difficulty:  "easy",
+			CourseType:  "walking",
+			StartPoint:  shared.Position{Latitude: 35.6812, Longitude: 139.7671},
+			Highlights:  []string{"公園"},
+			Summary:     "テスト",
+		},
+	}
+	var details shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/details", request, &details))
+	id := details.Course.ID
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/courses/"+id+"/export.gpx", nil), -1)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/gpx+xml", resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `attachment; filename="`+id+`.gpx"`, resp.Header.Get(fiber.HeaderContentDisposition))
+	assert.Contains(t, string(body), "<name>テストコース</name>")
+	assert.Equal(t, len(details.Course.Waypoints), bytes.Count(body, []byte("<wpt ")))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/courses/"+id+".geojson", nil), -1)
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/geo+json", resp.Header.Get(fiber.HeaderContentType))
+	var collection struct {
+		Type     string `json:"type"`
+		Features []any  `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(body, &collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Len(t, collection.Features, len(details.Course.Waypoints)+1)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/courses/unknown/export.gpx", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	// Details requested with the ID of a stored course are stored separately
+	request.CourseID, request.Suggestion.ID = id, id
+	request.Suggestion.Title = "別のコース"
+	var other shared.DetailsResponse
+	require.Equal(t, fiber.StatusOK, doJSON(t, app, "/api/v1/details", request, &other))
+	assert.NotEqual(t, id, other.Course.ID)
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/v1/courses/"+id+"/export.gpx", nil), -1)
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "<name>テストコース</name>")
+}
+
+func TestExportCourse_StoredRenderFailure(t *testing.T) {
+	store := courses.NewStore(cache.NewMemoryBackend(cache.Limits{}), 0)
+	handler := NewCourseHandler(services.NewFakeGenerator()).WithCourseStore(store)
+	app := fiber.New()
+	app.Get("/api/v1/courses/:id/export.:format", handler.ExportCourse)
+
+	broken := "_p~iF~ps|U_"
+	require.NoError(t, store.Save(shared.CourseDetails{
+		ID:         "course-1",
+		Title:      "皇居ラン",
+		CourseType: "jogging",
+		Polyline:   &broken,
+		Waypoints:  []shared.Waypoint{{ID: "wp-1", Title: "桜田門", Type: "start"}},
+	}))
+
+	// A stored course that cannot be rendered is a server error, not the client's
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/courses/course-1/export.gpx", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestExportGPX_Body(t *testing.T) {
+	app := newExportTestApp()
+
+	post := func(body any) (*http.Response, string) {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/api/v1/courses/export.gpx", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(raw)
+	}
+
+	course := shared.CourseDetails{
+		ID:          "../course 1",
+		Title:       "皇居ラン",
+		Description: "皇居を一周します。",
+		Difficulty:  "easy",
+		CourseType:  "jogging",
+		Waypoints: []shared.Waypoint{
+			{ID: "wp-1", Title: "桜田門", Description: "出発", Position: shared.Position{Latitude: 35.6778, Longitude: 139.7528}, Type: "start"},
+		},
+	}
+	resp, body := post(course)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, `attachment; filename="___course_1.gpx"`, resp.Header.Get(fiber.HeaderContentDisposition))
+	assert.Contains(t, body, `<sym>Flag, Green</sym>`)
+
+	course.Waypoints[0].Type = "summit"
+	resp, _ = post(course)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	broken := "_p~iF~ps|U_"
+	course.Waypoints[0].Type = "start"
+	course.Polyline = &broken
+	resp, _ = post(course)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestExportCourse_Formats(t *testing.T) {
+	app := newExportTestApp()
+	course := shared.CourseDetails{
+		ID:          "course-1",
+		Title:       "皇居ラン",
+		Description: "皇居を一周します。",
+		Difficulty:  "easy",
+		CourseType:  "jogging",
+		Waypoints: []shared.Waypoint{
+			{ID: "wp-1", Title: "桜田門", Description: "出発", Position: shared.Position{Latitude: 35.6778, Longitude: 139.7528}, Type: "start"},
+			{ID: "wp-2", Title: "二重橋", Description: "到着", Position: shared.Position{Latitude: 35.6800, Longitude: 139.7540}, Type: "end"},
+		},
+	}
+	payload, err := json.Marshal(course)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		filename    string
+	}{
+		{"default", "/api/v1/courses/export", "", fiber.StatusOK, "application/gpx+xml", "course-1.gpx"},
+		{"extension", "/api/v1/courses/export.kml", "", fiber.StatusOK, "application/vnd.google-earth.kml+xml", "course-1.kml"},
+		{"query parameter", "/api/v1/courses/export?format=kmz", "", fiber.StatusOK, "application/vnd.google-earth.kmz", "course-1.kmz"},
+		{"accept header", "/api/v1/courses/export", "application/vnd.google-earth.kml+xml, */*;q=0.1", fiber.StatusOK, "application/vnd.google-earth.kml+xml", "course-1.kml"},
+		{"extension wins over accept", "/api/v1/courses/export.gpx", "application/vnd.google-earth.kml+xml", fiber.StatusOK, "application/gpx+xml", "course-1.gpx"},
+		{"fit", "/api/v1/courses/export.fit", "", fiber.StatusOK, "application/vnd.ant.fit", "course-1.fit"},
+		{"unknown extension", "/api/v1/courses/export.shp", "", fiber.StatusBadRequest, "", ""},
+		{"unacceptable", "/api/v1/courses/export", "text/csv", fiber.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.status == fiber.StatusOK {
+				assert.Equal(t, tt.contentType, resp.Header.Get(fiber.HeaderContentType))
+				assert.Equal(t, `attachment; filename="`+tt.filename+`"`, resp.Header.Get(fiber.HeaderContentDisposition))
+			}
+		})
+	}
+
+	// FIT course files are only offered for cycling and jogging
+	course.CourseType = "walking"
+	payload, err = json.Marshal(course)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/v1/courses/export.fit", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	var envelope struct {
+		Error struct {
+			Details []struct {
+				Field string `json:"field"`
+				Code  string `json:"code"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	require.NotEmpty(t, envelope.Error.Details)
+	assert.Equal(t, "courseType", envelope.Error.Details[0].Field)
+	assert.Equal(t, "not_allowed", envelope.Error.Details[0].Code)
+}
+
+func TestGetSuggestions_GeoJSON(t *testing.T) {
+	app := newExportTestApp()
+	payload, err := json.Marshal(shared.SuggestionsRequest{Request: shared.CourseRequest{CourseType: "walking", Distance: "short"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name, path, accept string
+		geoJSON            bool
+	}{
+		{"query parameter", "/api/v1/suggestions?format=geojson", "", true},
+		{"accept header", "/api/v1/suggestions", "application/geo+json", true},
+		{"default", "/api/v1/suggestions", "", false},
+		{"json preferred", "/api/v1/suggestions", "application/json, application/geo+json;q=0.5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			if tt.geoJSON {
+				assert.Equal(t, "application/geo+json", resp.Header.Get(fiber.HeaderContentType))
+				assert.Equal(t, "FeatureCollection", body["type"])
+				assert.NotEmpty(t, body["features"])
+			} else {
+				assert.Equal(t, true, body["success"])
+			}
+		})
+	}
+}
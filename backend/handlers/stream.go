@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"potarin-backend/middleware"
+	"potarin-backend/services"
+	"potarin-backend/usage"
+	"potarin-backend/utils"
+	shared "potarin-shared"
+)
+
+// streamTimeout bounds how long a single suggestions stream may run
+const streamTimeout = 2 * time.Minute
+
+// StreamSuggestions streams course suggestions as Server-Sent Events. Each
+// suggestion is sent as a "suggestion" event as soon as it is complete,
+// followed by a "done" event, or an "error" event on failure.
+// GET takes the request as query parameters, POST as a SuggestionsRequest body.
+func (h *CourseHandler) StreamSuggestions(c *fiber.Ctx) error {
+	middleware.LogInfo(c, "Course suggestions stream request received")
+
+	var request shared.SuggestionsRequest
+	if c.Method() == fiber.MethodGet {
+		parsed, appErr := parseSuggestionsQuery(c)
+		if appErr != nil {
+			return utils.SendError(c, appErr)
+		}
+		request = *parsed
+		if appErr := middleware.ValidateStruct(&request); appErr != nil {
+			return utils.SendError(c, appErr)
+		}
+	} else if err := middleware.ValidateJSON(c, &request); err != nil {
+		middleware.LogWarn(c, "Invalid request body", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	if err := h.validateSuggestionsRequest(&request); err != nil {
+		middleware.LogWarn(c, "Request validation failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return utils.SendError(c, err)
+	}
+
+	serviceRequest := h.toServiceCourseRequest(request.Request)
+	labels := usageLabels(c, "suggestions_stream")
+	requestID := services.GenerateRequestID()
+	logFields := map[string]interface{}{
+		"method":      c.Method(),
+		"path":        c.Path(),
+		"course_type": serviceRequest.CourseType,
+		"distance":    serviceRequest.Distance,
+		"request_id":  requestID,
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The stream writer runs after the handler returns, so it must not touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(usage.WithLabels(context.Background(), labels), streamTimeout)
+		defer cancel()
+
+		count := 0
+		clientGone := false
+		emit := func(suggestion services.CourseSuggestion) error {
+			converted := toSharedSuggestion(suggestion)
+			h.estimateSuggestionTime(&converted, request.Request.PaceMinPerKm)
+			if err := writeSSEEvent(w, "suggestion", converted); err != nil {
+				clientGone = true
+				return err
+			}
+			count++
+			return nil
+		}
+
+		err := h.streamSuggestions(ctx, serviceRequest, emit)
+		if clientGone {
+			middleware.AppLogger.Warn("Client disconnected from suggestions stream", logFields)
+			return
+		}
+		if err != nil {
+			logFields["error"] = err.Error()
+			middleware.AppLogger.Error("Failed to stream course suggestions", logFields)
+			_ = writeSSEEvent(w, "error", generationError(err))
+			return
+		}
+
+		_ = writeSSEEvent(w, "done", shared.SuggestionsStreamComplete{
+			RequestID:   requestID,
+			GeneratedAt: time.Now(),
+			Count:       count,
+		})
+
+		logFields["suggestions_count"] = count
+		middleware.AppLogger.Info("Course suggestions streamed successfully", logFields)
+	})
+
+	return nil
+}
+
+// streamSuggestions uses the generator's native streaming when available and
+// otherwise emits the complete result one suggestion at a time
+func (h *CourseHandler) streamSuggestions(ctx context.Context, request services.CourseRequest, emit func(services.CourseSuggestion) error) error {
+	if streamer, ok := h.generator.(services.SuggestionStreamer); ok {
+		return streamer.StreamCourseSuggestions(ctx, request, emit)
+	}
+
+	generated, err := h.generator.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return err
+	}
+	for _, suggestion := range generated.Suggestions {
+		if err := emit(suggestion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSSEEvent writes a single Server-Sent Event with a JSON payload and flushes it
+func writeSSEEvent(w *bufio.Writer, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// parseSuggestionsQuery builds a SuggestionsRequest from query parameters:
+// courseType, distance, latitude, longitude, scenery, difficulty, avoidHills,
+// paceMinPerKm, routeShape, endLatitude, endLongitude, minDistanceKm,
+// maxDistanceKm and timeBudgetMinutes
+func parseSuggestionsQuery(c *fiber.Ctx) (*shared.SuggestionsRequest, *utils.AppError) {
+	request := &shared.SuggestionsRequest{
+		Request: shared.CourseRequest{
+			CourseType: c.Query("courseType"),
+			Distance:   c.Query("distance"),
+			RouteShape: c.Query("routeShape"),
+		},
+	}
+
+	latitude, longitude := c.Query("latitude"), c.Query("longitude")
+	if latitude != "" || longitude != "" {
+		lat, err := strconv.ParseFloat(latitude, 64)
+		if err != nil {
+			return nil, utils.NewValidationError("無効な緯度です").
+				WithDetail("location.latitude", "invalid_format", "緯度は数値で指定してください", latitude)
+		}
+		lng, err := strconv.ParseFloat(longitude, 64)
+		if err != nil {
+			return nil, utils.NewValidationError("無効な経度です").
+				WithDetail("location.longitude", "invalid_format", "経度は数値で指定してください", longitude)
+		}
+		request.Request.Location = &shared.Position{Latitude: lat, Longitude: lng}
+	}
+
+	endLatitude, endLongitude := c.Query("endLatitude"), c.Query("endLongitude")
+	if endLatitude != "" || endLongitude != "" {
+		lat, err := strconv.ParseFloat(endLatitude, 64)
+		if err != nil {
+			return nil, utils.NewValidationError("無効なゴール地点の緯度です").
+				WithDetail("endLocation.latitude", "invalid_format", "緯度は数値で指定してください", endLatitude)
+		}
+		lng, err := strconv.ParseFloat(endLongitude, 64)
+		if err != nil {
+			return nil, utils.NewValidationError("無効なゴール地点の経度です").
+				WithDetail("endLocation.longitude", "invalid_format", "経度は数値で指定してください", endLongitude)
+		}
+		request.Request.EndLocation = &shared.Position{Latitude: lat, Longitude: lng}
+	}
+
+	minDistance, maxDistance := c.Query("minDistanceKm"), c.Query("maxDistanceKm")
+	if minDistance != "" || maxDistance != "" {
+		distanceKm := &shared.DistanceRange{}
+		if minDistance != "" {
+			km, err := strconv.ParseFloat(minDistance, 64)
+			if err != nil {
+				return nil, utils.NewValidationError("無効な距離の範囲です").
+					WithDetail("minDistanceKm", "invalid_format", "距離はkm単位の数値で指定してください", minDistance)
+			}
+			distanceKm.Min = km
+		}
+		if maxDistance != "" {
+			km, err := strconv.ParseFloat(maxDistance, 64)
+			if err != nil {
+				return nil, utils.NewValidationError("無効な距離の範囲です").
+					WithDetail("maxDistanceKm", "invalid_format", "距離はkm単位の数値で指定してください", maxDistance)
+			}
+			distanceKm.Max = km
+		}
+		request.Request.DistanceKm = distanceKm
+	}
+
+	if value := c.Query("timeBudgetMinutes"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, utils.NewValidationError("無効な所要時間です").
+				WithDetail("timeBudgetMinutes", "invalid_format", "所要時間は分単位の整数で指定してください", value)
+		}
+		request.Request.TimeBudgetMinutes = &minutes
+	}
+
+	if value := c.Query("paceMinPerKm"); value != "" {
+		paceMinPerKm, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, utils.NewValidationError("無効なペースです").
+				WithDetail("paceMinPerKm", "invalid_format", "ペースは1kmあたりの分数で指定してください", value)
+		}
+		request.Request.PaceMinPerKm = &paceMinPerKm
+	}
+
+	scenery, difficulty, avoidHills := c.Query("scenery"), c.Query("difficulty"), c.Query("avoidHills")
+	if scenery != "" || difficulty != "" || avoidHills != "" {
+		preferences := &shared.CoursePreferences{}
+		if scenery != "" {
+			preferences.Scenery = &scenery
+		}
+		if difficulty != "" {
+			preferences.Difficulty = &difficulty
+		}
+		if avoidHills != "" {
+			value, err := strconv.ParseBool(avoidHills)
+			if err != nil {
+				return nil, utils.NewValidationError("無効な坂道設定です").
+					WithDetail("preferences.avoidHills", "invalid_format", "avoidHillsはtrueまたはfalseで指定してください", avoidHills)
+			}
+			preferences.AvoidHills = &value
+		}
+		request.Request.Preferences = preferences
+	}
+
+	return request, nil
+}
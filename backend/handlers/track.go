@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"potarin-backend/courses"
+	"potarin-backend/middleware"
+	"potarin-backend/services"
+	"potarin-backend/track"
+	"potarin-backend/usage"
+	"potarin-backend/utils"
+	shared "potarin-shared"
+)
+
+// defaultTrackCourseType is used when neither the request nor the file
+// names the kind of course
+const defaultTrackCourseType = "walking"
+
+// AnnotateTrack turns an uploaded GPX or TCX file into course details. The
+// file is sent as the multipart field "file" or as the raw request body; the
+// courseType form field or query parameter overrides the activity of the
+// file. The uploaded geometry is kept and only the waypoints and texts are
+// generated.
+func (h *CourseHandler) AnnotateTrack(c *fiber.Ctx) error {
+	middleware.LogInfo(c, "Track annotation request received")
+
+	annotator, ok := h.generator.(services.TrackAnnotator)
+	if !ok {
+		return utils.SendError(c, utils.NewServiceUnavailableError("トラック解析"))
+	}
+
+	data, appErr := trackUpload(c)
+	if appErr != nil {
+		return utils.SendError(c, appErr)
+	}
+	parsed, err := track.Parse(data)
+	if err != nil {
+		middleware.LogWarn(c, "Invalid track file", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return utils.SendError(c, utils.NewValidationError("トラックファイルを読み込めません").
+			WithDetail("file", "invalid_format", "2点以上を含むGPXまたはTCXファイルを指定してください", nil))
+	}
+
+	// Covers the query string as well as form fields
+	courseType := c.FormValue("courseType")
+	if courseType == "" {
+		courseType = parsed.CourseType
+	}
+	if courseType == "" {
+		courseType = defaultTrackCourseType
+	}
+	switch courseType {
+	case "walking", "cycling", "jogging":
+	default:
+		return utils.SendError(c, utils.NewValidationError("無効なコースタイプです").
+			WithDetail("courseType", "invalid_value", "有効なコースタイプを選択してください: walking, cycling, jogging", courseType))
+	}
+
+	request, err := services.NewTrackRequest(parsed.Name, courseType, parsed.Points)
+	if err != nil {
+		return utils.SendError(c, utils.NewValidationError("トラックファイルを読み込めません").
+			WithDetail("file", "invalid_format", err.Error(), nil))
+	}
+
+	middleware.LogInfo(c, "Calling course generator for track annotation", map[string]interface{}{
+		"course_type": request.CourseType,
+		"distance_km": request.DistanceKm,
+		"points":      len(request.Geometry),
+		"route_shape": request.RouteShape,
+	})
+
+	generated, err := annotator.AnnotateTrack(usage.WithLabels(c.Context(), usageLabels(c, "tracks")), request)
+	if errors.Is(err, services.ErrTrackAnnotationUnsupported) {
+		return utils.SendError(c, utils.NewServiceUnavailableError("トラック解析"))
+	}
+	if err != nil {
+		middleware.LogError(c, err, "Failed to annotate track")
+		return utils.SendError(c, generationError(err))
+	}
+	// Every upload is stored as a course of its own
+	generated.Course.ID = courses.NewID()
+
+	warnings := services.ReviewCourseDetails(&generated.Course, services.DistanceRange{})
+	course := toSharedCourse(generated.Course)
+	h.estimateCourseTime(&course, nil)
+	h.saveCourse(c, course)
+
+	response := shared.DetailsResponse{
+		Course:      course,
+		RequestID:   services.GenerateRequestID(),
+		GeneratedAt: time.Now(),
+		Warnings:    toSharedWarnings(warnings),
+		Metadata:    toSharedMetadata(generated.Metadata),
+	}
+
+	middleware.LogInfo(c, "Track annotated successfully", map[string]interface{}{
+		"course_id":       course.ID,
+		"waypoints_count": len(course.Waypoints),
+		"warnings_count":  len(response.Warnings),
+		"has_elevation":   course.Elevation != nil,
+		"request_id":      response.RequestID,
+		"cache_hit":       response.Metadata.CacheHit,
+	})
+
+	return utils.SendSuccess(c, response)
+}
+
+// trackUpload returns the uploaded file: the multipart field "file", or the
+// request body for any other content type
+func trackUpload(c *fiber.Ctx) ([]byte, *utils.AppError) {
+	missing := utils.NewValidationError("トラックファイルが必要です").
+		WithDetail("file", "required", "GPXまたはTCXファイルを指定してください", nil)
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		if len(c.Body()) == 0 {
+			return nil, missing
+		}
+		return c.Body(), nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, missing
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, utils.NewProcessingError("アップロードされたファイルを読み込めません")
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, utils.NewProcessingError("アップロードされたファイルを読み込めません")
+	}
+	return data, nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+	"potarin-backend/courses"
+	"potarin-backend/polyline"
+	"potarin-backend/services"
+	shared "potarin-shared"
+)
+
+const testGPXTrack = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><name>皇居ラン</name><type>running</type><trkseg>
+    <trkpt lat="35.6812" lon="139.7528"/>
+    <trkpt lat="35.6918" lon="139.7544"/>
+    <trkpt lat="35.6880" lon="139.7620"/>
+    <trkpt lat="35.6790" lon="139.7600"/>
+    <trkpt lat="35.6812" lon="139.7528"/>
+  </trkseg></trk>
+</gpx>`
+
+func newTrackTestApp() *fiber.App {
+	handler := NewCourseHandler(services.NewFakeGenerator()).
+		WithCourseStore(courses.NewStore(cache.NewMemoryBackend(cache.Limits{}), 0))
+
+	app := fiber.New()
+	app.Post("/api/v1/tracks/annotate", handler.AnnotateTrack)
+	app.Get("/api/v1/courses/:id/export.:format", handler.ExportCourse)
+	return app
+}
+
+// postTrack sends req and returns the status and the decoded details, if any
+func postTrack(t *testing.T, app *fiber.App, req *http.Request) (int, shared.DetailsResponse) {
+	t.Helper()
+
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	var envelope struct {
+		Success bool                   `json:"success"`
+		Data    shared.DetailsResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	return resp.StatusCode, envelope.Data
+}
+
+func TestAnnotateTrack_Multipart(t *testing.T) {
+	app := newTrackTestApp()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "kokyo.gpx")
+	require.NoError(t, err)
+	_, err = io.WriteString(part, testGPXTrack)
+	require.NoError(t, err)
+	require.NoError(t, form.WriteField("courseType", "walking"))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest("POST", "/api/v1/tracks/annotate", &body)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	status, details := postTrack(t, app, req)
+	require.Equal(t, fiber.StatusOK, status)
+
+	course := details.Course
+	assert.Equal(t, "皇居ラン", course.Title)
+	assert.Equal(t, "walking", course.CourseType, "the form overrides the activity of the file")
+	assert.Equal(t, services.RouteShapeLoop, course.RouteShape)
+	require.NotNil(t, course.Polyline)
+	geometry, err := polyline.Decode(*course.Polyline, polyline.DefaultPrecision)
+	require.NoError(t, err)
+	assert.InDelta(t, polyline.LengthKm(geometry), course.Distance, 0.05)
+	assert.Equal(t, shared.Position{Latitude: 35.6812, Longitude: 139.7528}, geometry[0])
+	assert.Len(t, geometry, 5)
+	require.NotEmpty(t, course.Waypoints)
+	assert.Equal(t, "start", course.Waypoints[0].Type)
+	assert.Equal(t, geometry[0], course.Waypoints[0].Position)
+	assert.NotZero(t, course.EstimatedTime)
+
+	// The course is stored for export
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/courses/"+course.ID+"/export.gpx", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestAnnotateTrack_RawBody(t *testing.T) {
+	app := newTrackTestApp()
+
+	req := httptest.NewRequest("POST", "/api/v1/tracks/annotate", bytes.NewReader([]byte(testGPXTrack)))
+	req.Header.Set(fiber.HeaderContentType, "application/gpx+xml")
+	status, details := postTrack(t, app, req)
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "jogging", details.Course.CourseType, "from the track type")
+}
+
+func TestAnnotateTrack_ValidationErrors(t *testing.T) {
+	app := newTrackTestApp()
+
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		field string
+	}{
+		{"missing file", "/api/v1/tracks/annotate", "", "file"},
+		{"not a track", "/api/v1/tracks/annotate", `{"type":"FeatureCollection"}`, "file"},
+		{"invalid course type", "/api/v1/tracks/annotate?courseType=swimming", testGPXTrack, "courseType"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader([]byte(tt.body)))
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			var envelope struct {
+				Error struct {
+					Code    string `json:"error"`
+					Details []struct {
+						Field string `json:"field"`
+					} `json:"details"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, "validation_error", envelope.Error.Code)
+			require.NotEmpty(t, envelope.Error.Details)
+			assert.Equal(t, tt.field, envelope.Error.Details[0].Field)
+		})
+	}
+}
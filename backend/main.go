@@ -1,25 +1,239 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"potarin-backend/cache"
 	"potarin-backend/config"
+	"potarin-backend/courses"
+	"potarin-backend/elevation"
+	"potarin-backend/geo"
 	"potarin-backend/handlers"
+	"potarin-backend/middleware"
+	"potarin-backend/pace"
+	"potarin-backend/poi"
+	"potarin-backend/prompts"
+	"potarin-backend/routing"
 	"potarin-backend/services"
+	"potarin-backend/usage"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Load and validate prompt templates
+	promptStore, err := prompts.NewStore(cfg.PromptsDir)
+	if err != nil {
+		log.Fatalf("Failed to load prompt templates: %v", err)
+	}
+	log.Printf("Using prompt version: %s", promptStore.Current().Version)
+	if cfg.PromptsDir != "" && cfg.PromptsHotReload {
+		go promptStore.Watch(context.Background(), 2*time.Second)
+		log.Printf("Watching %s for prompt changes", cfg.PromptsDir)
+	}
+
+	// Track token usage and cost against the daily budget
+	prices, err := usage.ParsePriceTable(cfg.LLMPrices)
+	if err != nil {
+		log.Fatalf("Invalid LLM_PRICES: %v", err)
+	}
+	usageTracker := usage.NewTracker(prices, cfg.DailyBudgetUSD)
+	if cfg.DailyBudgetUSD > 0 {
+		log.Printf("Daily LLM budget: $%.2f", cfg.DailyBudgetUSD)
+	}
+
+	// Name the prefecture of request locations in prompts
+	prefectures := geo.JapanPrefectures()
+	if cfg.PrefecturesGeoJSON != "" {
+		prefectures, err = geo.LoadPrefectureIndex(cfg.PrefecturesGeoJSON, cfg.PrefecturesNameProperty)
+		if err != nil {
+			log.Fatalf("Failed to load prefecture boundaries: %v", err)
+		}
+		log.Printf("Prefecture boundaries: %s", cfg.PrefecturesGeoJSON)
+	}
+
+	// Name the city and neighbourhood of request locations when boundaries are available
+	var localities geo.Localities
+	for _, source := range []struct {
+		name, path, fields string
+		defaults           geo.LocalityFields
+	}{
+		{"MUNICIPALITIES", cfg.MunicipalitiesGeoJSON, cfg.MunicipalitiesFields, geo.N03Fields},
+		{"NEIGHBOURHOODS", cfg.NeighbourhoodsGeoJSON, cfg.NeighbourhoodsFields, geo.EStatFields},
+	} {
+		if source.path == "" {
+			continue
+		}
+		fields, err := geo.ParseLocalityFields(source.fields, source.defaults)
+		if err != nil {
+			log.Fatalf("Invalid %s_FIELDS: %v", source.name, err)
+		}
+		index, err := geo.LoadLocalityIndex(source.path, fields)
+		if err != nil {
+			log.Fatalf("Failed to load locality boundaries: %v", err)
+		}
+		localities = append(localities, index)
+		log.Printf("Locality boundaries: %s", source.path)
+	}
+
+	// Ground prompts on real places near the location
+	var pois *poi.Store
+	if len(cfg.POIFiles) > 0 {
+		pois, err = poi.LoadStore(cfg.POIFiles...)
+		if err != nil {
+			log.Fatalf("Failed to load POIs: %v", err)
+		}
+		log.Printf("POIs: %d places from %s", pois.Len(), strings.Join(cfg.POIFiles, ", "))
+	}
+
 	// Initialize services
-	openaiService := services.NewOpenAIService(cfg.OpenAIAPIKey)
+	retryPolicy := services.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = cfg.OpenAIMaxRetries + 1
+
+	generator, err := services.NewCourseGenerator(services.GeneratorConfig{
+		Provider: cfg.LLMProvider,
+		APIKey:   cfg.OpenAIAPIKey,
+		BaseURL:  cfg.LLMBaseURL,
+		Model:    cfg.LLMModel,
+
+		CassetteMode: cfg.OpenAICassetteMode,
+		CassetteDir:  cfg.OpenAICassetteDir,
+		RetryPolicy:  retryPolicy,
+		Prompts:      promptStore,
+		Usage:        usageTracker,
+		Prefectures:  prefectures,
+		Localities:   localities,
+		POIs:         pois,
+		POIRadiusKm:  cfg.POIRadiusKm,
+		POILimit:     cfg.POILimit,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize course generator: %v", err)
+	}
+	log.Printf("Using course generator provider: %s", cfg.LLMProvider)
+	if cfg.OpenAICassetteMode != "off" {
+		log.Printf("OpenAI cassette mode: %s (%s)", cfg.OpenAICassetteMode, cfg.OpenAICassetteDir)
+	}
+
+	// Remove suggestions longer or shorter than requested
+	generator = services.NewDistanceFilteringGenerator(generator)
+
+	// Remove suggestions that start far from the user or off land
+	if cfg.StartPointValidation != "off" {
+		land := geo.JapanLand()
+		if cfg.LandGeoJSON != "" {
+			land, err = geo.LoadRegion(cfg.LandGeoJSON)
+			if err != nil {
+				log.Fatalf("Failed to load land polygons: %v", err)
+			}
+		}
+		generator = services.NewValidatingGenerator(generator, services.StartPointPolicy{
+			MaxRadiusKm:     cfg.StartPointMaxRadiusKm,
+			Land:            land,
+			LandToleranceKm: cfg.LandToleranceKm,
+			Reprompt:        cfg.StartPointValidation == "reprompt",
+		})
+	}
+
+	// Replace the positions of waypoints naming known places with the real ones
+	if pois != nil && cfg.WaypointSnapping {
+		generator = services.NewSnappingGenerator(generator, pois, services.SnapOptions{
+			MaxDistanceKm: cfg.SnapMaxDistanceKm,
+			MinConfidence: cfg.SnapMinConfidence,
+		})
+		log.Printf("Waypoint snapping: within %.0fm, confidence %.2f", cfg.SnapMaxDistanceKm*1000, cfg.SnapMinConfidence)
+	}
+
+	// Follow roads and paths between the waypoints of course details
+	if cfg.RoutingEngine != "off" {
+		httpClient := &http.Client{Timeout: cfg.RoutingTimeout}
+		var router routing.Router
+		switch cfg.RoutingEngine {
+		case "osrm":
+			router = routing.NewOSRMClient(cfg.RoutingURL, httpClient)
+		case "valhalla":
+			router = routing.NewValhallaClient(cfg.RoutingURL, httpClient)
+		}
+		generator = services.NewRoutedGenerator(generator, router)
+		log.Printf("Routing engine: %s (%s)", cfg.RoutingEngine, cfg.RoutingURL)
+	}
+
+	// Sample elevation profiles along the courses from local DEM data
+	if cfg.ElevationSource != "off" {
+		var source elevation.Source
+		switch cfg.ElevationSource {
+		case "hgt":
+			source, err = elevation.NewHGTSource(cfg.ElevationDir)
+		case "gsi":
+			source, err = elevation.NewGSISource(cfg.ElevationDir, cfg.ElevationGSIZoom)
+		}
+		if err != nil {
+			log.Fatalf("Failed to initialize elevation data: %v", err)
+		}
+		generator = services.NewElevationGenerator(generator, source, cfg.ElevationIntervalKm)
+		log.Printf("Elevation data: %s (%s)", cfg.ElevationSource, cfg.ElevationDir)
+	}
+
+	// Stop new suggestions once the daily budget is spent
+	generator = services.NewBudgetedGenerator(generator, usageTracker)
+
+	// Share upstream calls between concurrent identical requests
+	generator = services.NewCoalescingGenerator(generator, cfg.CacheGeohashPrecision)
+
+	// Cache responses in front of the generator
+	cacheLimits := cache.Limits{MaxEntries: cfg.CacheMaxEntries, MaxBytes: int64(cfg.CacheMaxBytes)}
+	var cacheBackend cache.Backend
+	switch cfg.CacheBackend {
+	case "memory":
+		cacheBackend = cache.NewMemoryBackend(cacheLimits)
+	case "file":
+		cacheBackend, err = cache.NewFileBackend(cfg.CacheDir, cacheLimits)
+		if err != nil {
+			log.Fatalf("Failed to initialize response cache: %v", err)
+		}
+	}
+	if cacheBackend != nil {
+		generator = services.NewCachedGenerator(generator, cacheBackend, services.CacheOptions{
+			TTL:              cfg.CacheTTL,
+			GeohashPrecision: cfg.CacheGeohashPrecision,
+		})
+		log.Printf("Response cache: %s (ttl %s)", cfg.CacheBackend, cfg.CacheTTL)
+	}
+
+	// Estimate times from distance, course type, difficulty and climb
+	paceModel, err := pace.ParseModel(cfg.PaceParams, cfg.PaceDifficulty)
+	if err != nil {
+		log.Fatalf("Invalid PACE_PARAMS or PACE_DIFFICULTY: %v", err)
+	}
+
+	// Keep returned course details for exports by ID
+	var courseBackend cache.Backend
+	courseLimits := cache.Limits{MaxEntries: cfg.CourseStoreMaxEntries}
+	switch cfg.CourseStore {
+	case "memory":
+		courseBackend = cache.NewMemoryBackend(courseLimits)
+	case "file":
+		courseBackend, err = cache.NewFileBackend(cfg.CourseStoreDir, courseLimits)
+		if err != nil {
+			log.Fatalf("Failed to initialize course store: %v", err)
+		}
+	}
 
 	// Initialize handlers
-	courseHandler := handlers.NewCourseHandler(openaiService)
+	courseHandler := handlers.NewCourseHandler(generator).WithPaceModel(paceModel)
+	if courseBackend != nil {
+		courseHandler.WithCourseStore(courses.NewStore(courseBackend, cfg.CourseStoreTTL))
+		log.Printf("Course store: %s (ttl %s)", cfg.CourseStore, cfg.CourseStoreTTL)
+	}
+	adminHandler := handlers.NewAdminHandler(usageTracker)
 
 	app := fiber.New()
 
@@ -28,11 +242,16 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization,X-Client-ID",
 	}))
 
 	// Routes
 	setupRoutes(app, courseHandler)
+	if cfg.AdminToken != "" {
+		setupAdminRoutes(app, adminHandler, cfg.AdminToken)
+	} else {
+		log.Printf("ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	log.Fatal(app.Listen(":" + cfg.Port))
@@ -52,6 +271,28 @@ func setupRoutes(app *fiber.App, courseHandler *handlers.CourseHandler) {
 	// Course suggestions endpoint
 	api.Post("/suggestions", courseHandler.GetSuggestions)
 
+	// Streaming course suggestions endpoint (Server-Sent Events)
+	api.Get("/suggestions/stream", courseHandler.StreamSuggestions)
+	api.Post("/suggestions/stream", courseHandler.StreamSuggestions)
+
 	// Course details endpoint
 	api.Post("/details", courseHandler.GetDetails)
+
+	// Course details for an uploaded GPX or TCX track
+	api.Post("/tracks/annotate", courseHandler.AnnotateTrack)
+
+	// Course export endpoints
+	api.Get("/courses/:id/export", courseHandler.ExportCourse)
+	api.Get("/courses/:id/export.:format", courseHandler.ExportCourse)
+	api.Post("/courses/export", courseHandler.ExportCourseBody)
+	api.Post("/courses/export.:format", courseHandler.ExportCourseBody)
+	// After the export routes, whose paths it would also match
+	api.Get("/courses/:id.:format", courseHandler.ExportCourse)
+}
+
+func setupAdminRoutes(app *fiber.App, adminHandler *handlers.AdminHandler, token string) {
+	admin := app.Group("/api/v1/admin", middleware.AdminAuth(token))
+
+	// Token usage and cost per endpoint, API client and model
+	admin.Get("/usage", adminHandler.GetUsage)
 }
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"potarin-backend/utils"
+)
+
+// AdminAuth requires "Authorization: Bearer <token>" matching the admin token
+func AdminAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		provided, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			LogWarn(c, "Rejected admin request")
+			return utils.SendError(c, utils.NewAppError(utils.Unauthorized, utils.GetErrorMessage(utils.Unauthorized)))
+		}
+		return c.Next()
+	}
+}
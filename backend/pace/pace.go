@@ -0,0 +1,200 @@
+// Package pace estimates how long a course takes from its distance, type,
+// difficulty and elevation gain.
+package pace
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Params is the pace of one course type
+type Params struct {
+	// SpeedKmh is the speed on flat ground
+	SpeedKmh float64
+	// ClimbMinutesPer100m is added for every 100m of elevation gain
+	ClimbMinutesPer100m float64
+	// SustainKm is how far SpeedKmh is held before fatigue sets in
+	SustainKm float64
+	// FatiguePer10Km is the fraction of speed lost every 10km after SustainKm
+	FatiguePer10Km float64
+}
+
+// minSpeedFactor bounds how much fatigue can slow the speed down
+const minSpeedFactor = 0.6
+
+// integrationStepKm is the resolution at which fatigue is applied
+const integrationStepKm = 0.1
+
+// Model holds the pace of every course type and the time factor of every
+// difficulty
+type Model struct {
+	CourseTypes map[string]Params
+	Difficulty  map[string]float64
+}
+
+// DefaultModel follows Naismith's rule for walking (5km/h plus an hour per
+// 600m of climb) and uses recreational speeds for jogging and cycling
+var DefaultModel = Model{
+	CourseTypes: map[string]Params{
+		"walking": {SpeedKmh: 5, ClimbMinutesPer100m: 10},
+		"jogging": {SpeedKmh: 9, ClimbMinutesPer100m: 6, SustainKm: 5, FatiguePer10Km: 0.05},
+		"cycling": {SpeedKmh: 18, ClimbMinutesPer100m: 6, SustainKm: 20, FatiguePer10Km: 0.05},
+	},
+	Difficulty: map[string]float64{
+		"easy":     1.0,
+		"moderate": 1.1,
+		"hard":     1.2,
+	},
+}
+
+// Course describes what is estimated
+type Course struct {
+	CourseType string
+	Difficulty string
+	DistanceKm float64
+	GainM      float64
+	// PaceMinPerKm overrides the flat speed, fatigue and difficulty with the
+	// user's own pace; zero uses the model
+	PaceMinPerKm float64
+}
+
+// Minutes returns the estimated time of a course in whole minutes. Unknown
+// course types are estimated as walking.
+func (m Model) Minutes(course Course) int {
+	return int(math.Round(m.minutes(course)))
+}
+
+// maxBudgetKm bounds the distance searched by DistanceKm
+const maxBudgetKm = 1000
+
+// DistanceKm returns how far the course's type goes in minutes at its
+// difficulty and pace, the inverse of Minutes. Its distance is ignored; its
+// elevation gain, if any, is climbed on the way.
+func (m Model) DistanceKm(course Course, minutes float64) float64 {
+	at := func(distanceKm float64) float64 {
+		course.DistanceKm = distanceKm
+		return m.minutes(course)
+	}
+	if minutes <= 0 || at(0) >= minutes {
+		return 0
+	}
+	low, high := 0.0, 1.0
+	for at(high) < minutes {
+		if high >= maxBudgetKm {
+			return maxBudgetKm
+		}
+		low, high = high, high*2
+	}
+	// Minutes grows with the distance, so bisect to 10m
+	for high-low > 0.01 {
+		middle := (low + high) / 2
+		if at(middle) < minutes {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return low
+}
+
+func (m Model) minutes(course Course) float64 {
+	params, ok := m.CourseTypes[course.CourseType]
+	if !ok {
+		params = m.CourseTypes["walking"]
+	}
+
+	var minutes float64
+	if course.PaceMinPerKm > 0 {
+		minutes = course.DistanceKm * course.PaceMinPerKm
+	} else {
+		minutes = params.flatMinutes(course.DistanceKm)
+		if factor, ok := m.Difficulty[course.Difficulty]; ok {
+			minutes *= factor
+		}
+	}
+	minutes += math.Max(course.GainM, 0) / 100 * params.ClimbMinutesPer100m
+
+	return minutes
+}
+
+// flatMinutes integrates the time over distance with the speed slowing
+// down after SustainKm
+func (p Params) flatMinutes(distanceKm float64) float64 {
+	if p.SpeedKmh <= 0 || distanceKm <= 0 {
+		return 0
+	}
+	if p.FatiguePer10Km <= 0 || distanceKm <= p.SustainKm {
+		return distanceKm / p.SpeedKmh * 60
+	}
+
+	minutes := p.SustainKm / p.SpeedKmh * 60
+	for travelled := p.SustainKm; travelled < distanceKm; travelled += integrationStepKm {
+		step := math.Min(integrationStepKm, distanceKm-travelled)
+		midpoint := travelled + step/2 - p.SustainKm
+		factor := math.Max(1-p.FatiguePer10Km*midpoint/10, minSpeedFactor)
+		minutes += step / (p.SpeedKmh * factor) * 60
+	}
+	return minutes
+}
+
+// ParseModel applies overrides to DefaultModel. params holds
+// "type=speed/climb" pairs (km/h and minutes per 100m of climb), e.g.
+// "walking=4.5/10,cycling=15/8"; difficulty holds "difficulty=factor"
+// pairs, e.g. "hard=1.3".
+func ParseModel(params, difficulty string) (Model, error) {
+	model := Model{CourseTypes: map[string]Params{}, Difficulty: map[string]float64{}}
+	for courseType, p := range DefaultModel.CourseTypes {
+		model.CourseTypes[courseType] = p
+	}
+	for name, factor := range DefaultModel.Difficulty {
+		model.Difficulty[name] = factor
+	}
+
+	for _, pair := range splitPairs(params) {
+		courseType, values, ok := strings.Cut(pair, "=")
+		speed, climb, ok2 := strings.Cut(values, "/")
+		courseType = strings.TrimSpace(courseType)
+		if !ok || !ok2 || courseType == "" {
+			return Model{}, fmt.Errorf("invalid pace %q (expected type=speed/climb)", pair)
+		}
+		speedKmh, err := strconv.ParseFloat(strings.TrimSpace(speed), 64)
+		if err != nil || speedKmh <= 0 {
+			return Model{}, fmt.Errorf("invalid speed in %q", pair)
+		}
+		climbMinutes, err := strconv.ParseFloat(strings.TrimSpace(climb), 64)
+		if err != nil || climbMinutes < 0 {
+			return Model{}, fmt.Errorf("invalid climb minutes in %q", pair)
+		}
+
+		p := model.CourseTypes[courseType]
+		p.SpeedKmh, p.ClimbMinutesPer100m = speedKmh, climbMinutes
+		model.CourseTypes[courseType] = p
+	}
+
+	for _, pair := range splitPairs(difficulty) {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return Model{}, fmt.Errorf("invalid difficulty factor %q (expected difficulty=factor)", pair)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || factor <= 0 {
+			return Model{}, fmt.Errorf("invalid factor in %q", pair)
+		}
+		model.Difficulty[name] = factor
+	}
+
+	return model, nil
+}
+
+func splitPairs(spec string) []string {
+	var pairs []string
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
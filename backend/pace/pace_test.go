@@ -0,0 +1,93 @@
+package pace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_Minutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		course Course
+		want   int
+	}{
+		{"walking on the flat", Course{CourseType: "walking", Difficulty: "easy", DistanceKm: 5}, 60},
+		{"walking with Naismith climb", Course{CourseType: "walking", Difficulty: "easy", DistanceKm: 5, GainM: 600}, 120},
+		{"difficulty factor", Course{CourseType: "walking", Difficulty: "hard", DistanceKm: 5}, 72},
+		{"unknown difficulty", Course{CourseType: "walking", Difficulty: "extreme", DistanceKm: 5}, 60},
+		{"jogging before fatigue", Course{CourseType: "jogging", Difficulty: "easy", DistanceKm: 4.5}, 30},
+		{"jogging with fatigue", Course{CourseType: "jogging", Difficulty: "easy", DistanceKm: 15}, 102},
+		{"cycling", Course{CourseType: "cycling", Difficulty: "easy", DistanceKm: 18, GainM: 100}, 66},
+		{"user pace", Course{CourseType: "jogging", Difficulty: "hard", DistanceKm: 10, GainM: 100, PaceMinPerKm: 5.5}, 61},
+		{"unknown course type", Course{CourseType: "skating", DistanceKm: 5}, 60},
+		{"no distance", Course{CourseType: "cycling"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DefaultModel.Minutes(tt.course))
+		})
+	}
+}
+
+func TestModel_DistanceKm(t *testing.T) {
+	assert.InDelta(t, 5, DefaultModel.DistanceKm(Course{CourseType: "walking", Difficulty: "easy"}, 60), 0.01)
+	assert.InDelta(t, 2.5, DefaultModel.DistanceKm(Course{CourseType: "walking", Difficulty: "easy", GainM: 300}, 60), 0.01)
+	assert.InDelta(t, 12, DefaultModel.DistanceKm(Course{CourseType: "jogging", PaceMinPerKm: 5}, 60), 0.01)
+
+	// The inverse of Minutes, fatigue included
+	jogging := Course{CourseType: "jogging", Difficulty: "moderate"}
+	jogging.DistanceKm = DefaultModel.DistanceKm(jogging, 120)
+	assert.InDelta(t, 120, DefaultModel.Minutes(jogging), 1)
+
+	assert.Zero(t, DefaultModel.DistanceKm(Course{CourseType: "walking"}, 0))
+	assert.Zero(t, DefaultModel.DistanceKm(Course{CourseType: "walking", GainM: 600}, 30), "the climb alone takes longer")
+	assert.Equal(t, float64(maxBudgetKm), DefaultModel.DistanceKm(Course{CourseType: "cycling"}, 1e6))
+}
+
+func TestModel_FatigueIsBounded(t *testing.T) {
+	params := Params{SpeedKmh: 10, SustainKm: 0, FatiguePer10Km: 0.5}
+	// After 8km the speed stays at 60%
+	withinBound := params.flatMinutes(8)
+	assert.InDelta(t, withinBound+2/(10*minSpeedFactor)*60, params.flatMinutes(10), 1e-6)
+}
+
+func TestParseModel(t *testing.T) {
+	model, err := ParseModel("walking=4/12, cycling=15/8", "hard=1.5")
+	require.NoError(t, err)
+
+	assert.Equal(t, 4.0, model.CourseTypes["walking"].SpeedKmh)
+	assert.Equal(t, 12.0, model.CourseTypes["walking"].ClimbMinutesPer100m)
+	// Fatigue is kept from the default
+	assert.Equal(t, DefaultModel.CourseTypes["cycling"].SustainKm, model.CourseTypes["cycling"].SustainKm)
+	assert.Equal(t, DefaultModel.CourseTypes["jogging"], model.CourseTypes["jogging"])
+	assert.Equal(t, 1.5, model.Difficulty["hard"])
+	assert.Equal(t, 1.1, model.Difficulty["moderate"])
+
+	// Defaults are not modified
+	assert.Equal(t, 5.0, DefaultModel.CourseTypes["walking"].SpeedKmh)
+}
+
+func TestParseModel_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     string
+		difficulty string
+	}{
+		{"missing climb", "walking=5", ""},
+		{"zero speed", "walking=0/10", ""},
+		{"negative climb", "walking=5/-1", ""},
+		{"missing type", "=5/10", ""},
+		{"invalid factor", "", "hard=fast"},
+		{"zero factor", "", "hard=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseModel(tt.params, tt.difficulty)
+			assert.Error(t, err)
+		})
+	}
+}
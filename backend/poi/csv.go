@@ -0,0 +1,92 @@
+package poi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps header names, compared case-insensitively, to POI fields
+var csvColumns = map[string]string{
+	"name":      "name",
+	"名称":        "name",
+	"reading":   "reading",
+	"kana":      "reading",
+	"よみ":        "reading",
+	"読み":        "reading",
+	"category":  "category",
+	"カテゴリ":      "category",
+	"種別":        "category",
+	"latitude":  "latitude",
+	"lat":       "latitude",
+	"緯度":        "latitude",
+	"longitude": "longitude",
+	"lng":       "longitude",
+	"lon":       "longitude",
+	"経度":        "longitude",
+}
+
+// ParseCSV reads POIs from CSV with a header row naming the columns, in any
+// order: name, reading, category, latitude and longitude (or lat and
+// lng/lon, or 名称, よみ, 種別, 緯度 and 経度). Other columns are ignored;
+// reading and category are optional.
+func ParseCSV(r io.Reader) ([]POI, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := csvColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"name", "latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	value := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var pois []POI
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		latitude, err := strconv.ParseFloat(value(record, "latitude"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude %q", line, value(record, "latitude"))
+		}
+		longitude, err := strconv.ParseFloat(value(record, "longitude"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude %q", line, value(record, "longitude"))
+		}
+		pois = append(pois, POI{
+			Name:      value(record, "name"),
+			Reading:   value(record, "reading"),
+			Category:  value(record, "category"),
+			Latitude:  latitude,
+			Longitude: longitude,
+		})
+	}
+	return pois, nil
+}
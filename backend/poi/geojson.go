@@ -0,0 +1,105 @@
+package poi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// geoJSON covers the parts of GeoJSON needed to read features
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Features    []geoJSON       `json:"features"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Properties  map[string]any  `json:"properties"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ParseGeoJSON reads the named features of a GeoJSON FeatureCollection or
+// Feature. Points are used as is, other geometries by the center of their
+// bounding box. The name is read from "name" (or "name:ja", "名称"), the
+// reading from "reading" (or "name:ja-Hira", "name:ja_kana", "よみ") and the
+// category from "category" (or "種別"), falling back to OSM tags such as
+// tourism=viewpoint when the properties are OSM tags.
+func ParseGeoJSON(data []byte) ([]POI, error) {
+	var document geoJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	features := document.Features
+	switch document.Type {
+	case "FeatureCollection":
+	case "Feature":
+		features = []geoJSON{document}
+	default:
+		return nil, fmt.Errorf("expected a FeatureCollection or Feature, got %q", document.Type)
+	}
+
+	var pois []POI
+	for _, feature := range features {
+		if feature.Geometry == nil {
+			continue
+		}
+		latitude, longitude, ok := center(feature.Geometry.Coordinates)
+		if !ok {
+			continue
+		}
+
+		tags := make(map[string]string, len(feature.Properties))
+		for key, value := range feature.Properties {
+			if text, ok := value.(string); ok {
+				tags[key] = text
+			}
+		}
+		category := firstTag(tags, "category", "種別")
+		if category == "" {
+			category = osmCategory(tags)
+		}
+		pois = append(pois, POI{
+			Name:      firstTag(tags, "name", "name:ja", "名称"),
+			Reading:   firstTag(tags, append([]string{"reading", "よみ"}, readingTags...)...),
+			Category:  category,
+			Latitude:  latitude,
+			Longitude: longitude,
+		})
+	}
+	return pois, nil
+}
+
+// center returns the center of the bounding box of GeoJSON coordinates of
+// any depth
+func center(coordinates json.RawMessage) (latitude, longitude float64, ok bool) {
+	var nested any
+	if err := json.Unmarshal(coordinates, &nested); err != nil {
+		return 0, 0, false
+	}
+
+	minLat, minLng := math.Inf(1), math.Inf(1)
+	maxLat, maxLng := math.Inf(-1), math.Inf(-1)
+	var walk func(node any)
+	walk = func(node any) {
+		values, isArray := node.([]any)
+		if !isArray {
+			return
+		}
+		if len(values) >= 2 {
+			lng, lngOK := values[0].(float64)
+			lat, latOK := values[1].(float64)
+			if lngOK && latOK {
+				minLat, maxLat = math.Min(minLat, lat), math.Max(maxLat, lat)
+				minLng, maxLng = math.Min(minLng, lng), math.Max(maxLng, lng)
+				return
+			}
+		}
+		for _, value := range values {
+			walk(value)
+		}
+	}
+	walk(nested)
+
+	if math.IsInf(minLat, 1) {
+		return 0, 0, false
+	}
+	return (minLat + maxLat) / 2, (minLng + maxLng) / 2, true
+}
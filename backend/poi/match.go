@@ -0,0 +1,100 @@
+package poi
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is the POI best matching a name near a location
+type Match struct {
+	Nearby
+	// Similarity of the names, from 0 (unrelated) to 1 (equal)
+	Similarity float64
+	// Confidence combines the similarity with the distance, from 0 to 1
+	Confidence float64
+}
+
+// Match finds the POI within maxDistanceKm whose name or reading is most
+// similar to name, discounting candidates by up to half at maxDistanceKm.
+// It returns false when no POI within the distance shares anything with the
+// name.
+func (s *Store) Match(name string, latitude, longitude, maxDistanceKm float64) (Match, bool) {
+	var best Match
+	for _, candidate := range s.Nearby(latitude, longitude, maxDistanceKm, 0) {
+		similarity := NameSimilarity(name, candidate.Name)
+		if candidate.Reading != "" {
+			similarity = max(similarity, NameSimilarity(name, candidate.Reading))
+		}
+		confidence := similarity * (1 - 0.5*candidate.DistanceKm/maxDistanceKm)
+		if confidence > best.Confidence {
+			best = Match{Nearby: candidate, Similarity: similarity, Confidence: confidence}
+		}
+	}
+	return best, best.Confidence > 0
+}
+
+// NameSimilarity scores how likely two place names refer to the same place,
+// from 0 to 1. Names are compared after NFKC normalization without case,
+// spaces and punctuation. A name contained in the other, as in "吉祥寺駅" and
+// "スタート：吉祥寺駅北口", scores at least 0.7; otherwise the score is the
+// share of characters in their longest common subsequence, which tolerates
+// abbreviations such as "井の頭公園" for "井の頭恩賜公園".
+func NameSimilarity(a, b string) float64 {
+	x, y := normalizeName(a), normalizeName(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+	if string(x) == string(y) {
+		return 1
+	}
+
+	shorter, longer := x, y
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	score := 0.0
+	// A single shared character such as "の" says nothing
+	if common := longestCommonSubsequence(x, y); common >= 2 {
+		score = 2 * float64(common) / float64(len(x)+len(y))
+	}
+	// Single characters such as "駅" are contained in too many names
+	if len(shorter) >= 2 && strings.Contains(string(longer), string(shorter)) {
+		score = max(score, 0.7+0.3*float64(len(shorter))/float64(len(longer)))
+	}
+	return score
+}
+
+// normalizeName folds width and case and drops spaces, punctuation and
+// symbols such as "・" and "（）"
+func normalizeName(name string) []rune {
+	name = strings.ToLower(norm.NFKC.String(name))
+	runes := make([]rune, 0, utf8.RuneCountInString(name))
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func longestCommonSubsequence(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				current[j+1] = previous[j] + 1
+			case previous[j+1] >= current[j]:
+				current[j+1] = previous[j+1]
+			default:
+				current[j+1] = current[j]
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,213 @@
+package poi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readingTags are the OSM tags holding the kana reading of a name
+var readingTags = []string{"name:ja-Hira", "name:ja_kana", "name:ja-Kana"}
+
+// osmCategories lists the OSM tags kept as POIs, in order of precedence.
+// A nil set keeps every value of the key.
+var osmCategories = []struct {
+	key    string
+	values map[string]bool
+}{
+	{"tourism", map[string]bool{
+		"attraction": true, "viewpoint": true, "museum": true, "gallery": true, "artwork": true,
+		"zoo": true, "aquarium": true, "theme_park": true, "picnic_site": true, "camp_site": true,
+	}},
+	{"historic", nil},
+	{"leisure", map[string]bool{"park": true, "garden": true, "nature_reserve": true}},
+	{"natural", map[string]bool{
+		"peak": true, "waterfall": true, "spring": true, "beach": true, "cape": true, "cave_entrance": true,
+	}},
+	{"amenity", map[string]bool{"place_of_worship": true, "cafe": true, "drinking_water": true, "fountain": true}},
+	{"railway", map[string]bool{"station": true}},
+	{"bridge", map[string]bool{"yes": true}},
+}
+
+// osmCategory returns the category of a place from its OSM tags, or "" when
+// it is not a kind of place worth visiting. Places of worship are told apart
+// into shrines and temples.
+func osmCategory(tags map[string]string) string {
+	for _, category := range osmCategories {
+		value, ok := tags[category.key]
+		if !ok || (category.values != nil && !category.values[value]) {
+			continue
+		}
+		switch {
+		case value == "place_of_worship" && tags["religion"] == "shinto":
+			return "shrine"
+		case value == "place_of_worship" && tags["religion"] == "buddhist":
+			return "temple"
+		case category.key == "bridge":
+			return "bridge"
+		case value == "yes":
+			return category.key
+		}
+		return value
+	}
+	return ""
+}
+
+// osmPOI builds a POI from OSM tags, or returns false when the place is
+// unnamed or of no listed category
+func osmPOI(tags map[string]string, latitude, longitude float64) (POI, bool) {
+	name := firstTag(tags, "name:ja", "name")
+	category := osmCategory(tags)
+	if name == "" || category == "" {
+		return POI{}, false
+	}
+	return POI{
+		Name:      name,
+		Reading:   firstTag(tags, readingTags...),
+		Category:  category,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}, true
+}
+
+func firstTag(tags map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(tags[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type osmTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type osmNode struct {
+	ID        int64    `xml:"id,attr"`
+	Latitude  float64  `xml:"lat,attr"`
+	Longitude float64  `xml:"lon,attr"`
+	Tags      []osmTag `xml:"tag"`
+}
+
+type osmWay struct {
+	Nodes []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []osmTag `xml:"tag"`
+}
+
+func tagMap(tags []osmTag) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		m[tag.Key] = tag.Value
+	}
+	return m
+}
+
+// ParseOSM reads POIs from an OpenStreetMap XML extract, e.g. exported from
+// openstreetmap.org or by osmium. Tagged nodes are used as is and ways, such
+// as parks, by the average of their nodes; relations are skipped. Only named
+// places of the categories in osmCategories are kept.
+func ParseOSM(r io.Reader) ([]POI, error) {
+	decoder := xml.NewDecoder(r)
+	coordinates := make(map[int64][2]float64)
+	var pois []POI
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OSM XML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "node":
+			var node osmNode
+			if err := decoder.DecodeElement(&node, &start); err != nil {
+				return nil, fmt.Errorf("invalid OSM node: %w", err)
+			}
+			coordinates[node.ID] = [2]float64{node.Latitude, node.Longitude}
+			if p, ok := osmPOI(tagMap(node.Tags), node.Latitude, node.Longitude); ok {
+				pois = append(pois, p)
+			}
+		case "way":
+			var way osmWay
+			if err := decoder.DecodeElement(&way, &start); err != nil {
+				return nil, fmt.Errorf("invalid OSM way: %w", err)
+			}
+			var latitude, longitude float64
+			count := 0
+			for _, node := range way.Nodes {
+				if at, ok := coordinates[node.Ref]; ok {
+					latitude += at[0]
+					longitude += at[1]
+					count++
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			if p, ok := osmPOI(tagMap(way.Tags), latitude/float64(count), longitude/float64(count)); ok {
+				pois = append(pois, p)
+			}
+		}
+	}
+	return pois, nil
+}
+
+// overpassResponse is the JSON output of the Overpass API
+type overpassResponse struct {
+	Elements []struct {
+		Type      string            `json:"type"`
+		Latitude  *float64          `json:"lat"`
+		Longitude *float64          `json:"lon"`
+		Center    *overpassCenter   `json:"center"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"elements"`
+}
+
+type overpassCenter struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+func isOverpassJSON(data []byte) bool {
+	return bytes.Contains(data, []byte(`"elements"`)) && !bytes.Contains(data, []byte(`"features"`))
+}
+
+// ParseOverpassJSON reads POIs from an Overpass API response in JSON. Ways
+// and relations need a center, i.e. a query ending in "out center;". Only
+// named places of the categories in osmCategories are kept.
+func ParseOverpassJSON(data []byte) ([]POI, error) {
+	var response overpassResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("invalid Overpass JSON: %w", err)
+	}
+
+	var pois []POI
+	for _, element := range response.Elements {
+		var latitude, longitude float64
+		switch {
+		case element.Latitude != nil && element.Longitude != nil:
+			latitude, longitude = *element.Latitude, *element.Longitude
+		case element.Center != nil:
+			latitude, longitude = element.Center.Latitude, element.Center.Longitude
+		default:
+			continue
+		}
+		if p, ok := osmPOI(element.Tags, latitude, longitude); ok {
+			pois = append(pois, p)
+		}
+	}
+	return pois, nil
+}
@@ -0,0 +1,181 @@
+// Package poi stores real points of interest, such as parks, shrines and
+// viewpoints, and finds the ones near a location so that prompts can ground
+// courses on places that exist.
+package poi
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"potarin-backend/geo"
+)
+
+// POI is a named place
+type POI struct {
+	Name      string  // e.g. "井の頭恩賜公園"
+	Reading   string  // Kana reading, e.g. "いのかしらおんしこうえん"; may be empty
+	Category  string  // e.g. "park", "shrine", "viewpoint"; may be empty
+	Latitude  float64 // WGS84
+	Longitude float64
+}
+
+// Nearby is a POI found around a location
+type Nearby struct {
+	POI
+	DistanceKm float64
+}
+
+// cellDegrees is the size of the grid cells indexing POIs, about 1km
+const cellDegrees = 0.01
+
+// duplicateKm is the distance within which POIs of the same name are one
+// place, e.g. an OSM node and the area around it
+const duplicateKm = 0.1
+
+// Store indexes POIs for radius queries. It is read-only once built and safe
+// for concurrent use.
+type Store struct {
+	pois  []POI
+	cells map[[2]int][]int
+}
+
+// NewStore indexes pois, dropping nameless ones, ones with invalid
+// coordinates and duplicates of an earlier POI with the same name
+func NewStore(pois []POI) *Store {
+	store := &Store{cells: make(map[[2]int][]int)}
+	for _, p := range pois {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" || !validCoordinate(p.Latitude, p.Longitude) || store.contains(p) {
+			continue
+		}
+		id := len(store.pois)
+		store.pois = append(store.pois, p)
+		key := cell(p.Latitude, p.Longitude)
+		store.cells[key] = append(store.cells[key], id)
+	}
+	return store
+}
+
+// Len returns the number of stored POIs
+func (s *Store) Len() int {
+	return len(s.pois)
+}
+
+// Nearby returns up to limit POIs within radiusKm of a coordinate, nearest
+// first. A limit of zero or less returns all of them.
+func (s *Store) Nearby(latitude, longitude, radiusKm float64, limit int) []Nearby {
+	if s == nil || radiusKm <= 0 {
+		return nil
+	}
+
+	var results []Nearby
+	s.visit(latitude, longitude, radiusKm, func(p POI) {
+		distance := geo.HaversineKm(latitude, longitude, p.Latitude, p.Longitude)
+		if distance <= radiusKm {
+			results = append(results, Nearby{POI: p, DistanceKm: distance})
+		}
+	})
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DistanceKm < results[j].DistanceKm
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// visit calls fn for every POI in the cells overlapping the bounding box of
+// a circle
+func (s *Store) visit(latitude, longitude, radiusKm float64, fn func(POI)) {
+	kmPerDegree := geo.EarthRadiusKm * math.Pi / 180
+	latSpan := radiusKm / kmPerDegree
+	lngSpan := radiusKm / (kmPerDegree * math.Max(math.Cos(latitude*math.Pi/180), 0.01))
+
+	minCell := cell(latitude-latSpan, longitude-lngSpan)
+	maxCell := cell(latitude+latSpan, longitude+lngSpan)
+	for row := minCell[0]; row <= maxCell[0]; row++ {
+		for col := minCell[1]; col <= maxCell[1]; col++ {
+			for _, id := range s.cells[[2]int{row, col}] {
+				fn(s.pois[id])
+			}
+		}
+	}
+}
+
+func (s *Store) contains(p POI) bool {
+	found := false
+	s.visit(p.Latitude, p.Longitude, duplicateKm, func(other POI) {
+		if !found && other.Name == p.Name &&
+			geo.HaversineKm(p.Latitude, p.Longitude, other.Latitude, other.Longitude) <= duplicateKm {
+			found = true
+		}
+	})
+	return found
+}
+
+func cell(latitude, longitude float64) [2]int {
+	return [2]int{int(math.Floor(latitude / cellDegrees)), int(math.Floor(longitude / cellDegrees))}
+}
+
+func validCoordinate(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180 &&
+		!(latitude == 0 && longitude == 0)
+}
+
+// Load reads the POIs of a file, choosing the format by its extension:
+// ".csv" (see ParseCSV), ".geojson" or ".json" (GeoJSON, or Overpass API
+// JSON when it has "elements"; see ParseGeoJSON and ParseOverpassJSON) and
+// ".osm" (OpenStreetMap XML; see ParseOSM)
+func Load(path string) ([]POI, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("poi: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var pois []POI
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		pois, err = ParseCSV(file)
+	case ".geojson", ".json":
+		var data []byte
+		if data, err = io.ReadAll(file); err == nil {
+			if isOverpassJSON(data) {
+				pois, err = ParseOverpassJSON(data)
+			} else {
+				pois, err = ParseGeoJSON(data)
+			}
+		}
+	case ".osm", ".xml":
+		pois, err = ParseOSM(file)
+	default:
+		return nil, fmt.Errorf("poi: unsupported file %s (expected .csv, .geojson, .json or .osm)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("poi: failed to parse %s: %w", path, err)
+	}
+	return pois, nil
+}
+
+// LoadStore reads and indexes the POIs of several files. Earlier files win
+// over later ones for duplicates.
+func LoadStore(paths ...string) (*Store, error) {
+	var pois []POI
+	for _, path := range paths {
+		loaded, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		pois = append(pois, loaded...)
+	}
+	store := NewStore(pois)
+	if store.Len() == 0 {
+		return nil, fmt.Errorf("poi: no named points in %s", strings.Join(paths, ", "))
+	}
+	return store, nil
+}
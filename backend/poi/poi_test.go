@@ -0,0 +1,187 @@
+package poi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreNearby(t *testing.T) {
+	store := NewStore([]POI{
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+		{Name: "吉祥寺駅", Category: "station", Latitude: 35.7031, Longitude: 139.5798},
+		{Name: "三鷹の森ジブリ美術館", Category: "museum", Latitude: 35.6962, Longitude: 139.5704},
+		{Name: "高尾山", Category: "peak", Latitude: 35.6251, Longitude: 139.2437},
+		// Duplicate of the park, e.g. its OSM area next to its node
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.7000, Longitude: 139.5735},
+		{Name: "  ", Latitude: 35.70, Longitude: 139.57},
+		{Name: "Null Island", Latitude: 0, Longitude: 0},
+	})
+	assert.Equal(t, 4, store.Len())
+
+	nearby := store.Nearby(35.7031, 139.5798, 1.5, 0)
+	names := make([]string, len(nearby))
+	for i, n := range nearby {
+		names[i] = n.Name
+	}
+	assert.Equal(t, []string{"吉祥寺駅", "井の頭恩賜公園", "三鷹の森ジブリ美術館"}, names)
+	assert.Zero(t, nearby[0].DistanceKm)
+	assert.InDelta(t, 0.7, nearby[1].DistanceKm, 0.1)
+
+	assert.Len(t, store.Nearby(35.7031, 139.5798, 1.5, 2), 2)
+	assert.Len(t, store.Nearby(35.7031, 139.5798, 40, 0), 4, "the radius spans many grid cells")
+	assert.Empty(t, store.Nearby(35.7031, 139.5798, 0, 0))
+	assert.Empty(t, (*Store)(nil).Nearby(35.7031, 139.5798, 1, 0))
+}
+
+func TestParseCSV(t *testing.T) {
+	pois, err := ParseCSV(strings.NewReader("\ufeff名称,よみ,種別,緯度,経度,備考\n" +
+		"井の頭恩賜公園,いのかしらおんしこうえん,park,35.6997,139.5731,池\n" +
+		"吉祥寺駅,,station,35.7031,139.5798\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []POI{
+		{Name: "井の頭恩賜公園", Reading: "いのかしらおんしこうえん", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+		{Name: "吉祥寺駅", Category: "station", Latitude: 35.7031, Longitude: 139.5798},
+	}, pois)
+
+	pois, err = ParseCSV(strings.NewReader("lng,lat,Name\n139.2437,35.6251,高尾山\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []POI{{Name: "高尾山", Latitude: 35.6251, Longitude: 139.2437}}, pois)
+
+	_, err = ParseCSV(strings.NewReader("name,lat\n高尾山,35.6251\n"))
+	assert.ErrorContains(t, err, "longitude")
+	_, err = ParseCSV(strings.NewReader("name,lat,lng\n高尾山,north,139.2437\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	pois, err := ParseGeoJSON([]byte(`{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"name":"高尾山","reading":"たかおさん","category":"peak"},"geometry":{"type":"Point","coordinates":[139.2437,35.6251]}},
+		{"type":"Feature","properties":{"name":"井の頭恩賜公園","leisure":"park","name:ja-Hira":"いのかしらおんしこうえん"},"geometry":{"type":"Polygon","coordinates":[[[139.56,35.69],[139.58,35.69],[139.58,35.71],[139.56,35.71],[139.56,35.69]]]}},
+		{"type":"Feature","properties":{"name":"地図外"},"geometry":null}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, pois, 2)
+	assert.Equal(t, POI{Name: "高尾山", Reading: "たかおさん", Category: "peak", Latitude: 35.6251, Longitude: 139.2437}, pois[0])
+	assert.Equal(t, "park", pois[1].Category)
+	assert.Equal(t, "いのかしらおんしこうえん", pois[1].Reading)
+	assert.InDelta(t, 35.70, pois[1].Latitude, 1e-9)
+	assert.InDelta(t, 139.57, pois[1].Longitude, 1e-9)
+
+	_, err = ParseGeoJSON([]byte(`{"type":"Point","coordinates":[139,35]}`))
+	assert.Error(t, err)
+}
+
+func TestParseOSM(t *testing.T) {
+	pois, err := ParseOSM(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="1" lat="35.6930" lon="139.7720">
+    <tag k="amenity" v="place_of_worship"/><tag k="religion" v="shinto"/><tag k="name" v="神田明神"/><tag k="name:ja-Hira" v="かんだみょうじん"/>
+  </node>
+  <node id="2" lat="35.7148" lon="139.7967">
+    <tag k="amenity" v="place_of_worship"/><tag k="religion" v="buddhist"/><tag k="name" v="浅草寺"/>
+  </node>
+  <node id="3" lat="35.6900" lon="139.7000"><tag k="shop" v="convenience"/><tag k="name" v="コンビニ"/></node>
+  <node id="4" lat="35.6800" lon="139.7500"><tag k="tourism" v="viewpoint"/></node>
+  <node id="10" lat="35.700" lon="139.560"/>
+  <node id="11" lat="35.700" lon="139.580"/>
+  <node id="12" lat="35.690" lon="139.580"/>
+  <node id="13" lat="35.690" lon="139.560"/>
+  <way id="100">
+    <nd ref="10"/><nd ref="11"/><nd ref="12"/><nd ref="13"/>
+    <tag k="leisure" v="park"/><tag k="name" v="井の頭恩賜公園"/>
+  </way>
+  <relation id="1000"><tag k="leisure" v="park"/><tag k="name" v="関係"/></relation>
+</osm>`))
+	require.NoError(t, err)
+	assert.Equal(t, []POI{
+		{Name: "神田明神", Reading: "かんだみょうじん", Category: "shrine", Latitude: 35.6930, Longitude: 139.7720},
+		{Name: "浅草寺", Category: "temple", Latitude: 35.7148, Longitude: 139.7967},
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.695, Longitude: 139.57},
+	}, pois)
+}
+
+func TestParseOverpassJSON(t *testing.T) {
+	pois, err := ParseOverpassJSON([]byte(`{"version":0.6,"elements":[
+		{"type":"node","id":1,"lat":35.6251,"lon":139.2437,"tags":{"natural":"peak","name":"高尾山"}},
+		{"type":"way","id":2,"center":{"lat":35.6997,"lon":139.5731},"tags":{"leisure":"park","name":"井の頭恩賜公園"}},
+		{"type":"way","id":3,"tags":{"leisure":"park","name":"中心なし"}},
+		{"type":"node","id":4,"lat":35.0,"lon":139.0,"tags":{"amenity":"parking","name":"駐車場"}}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []POI{
+		{Name: "高尾山", Category: "peak", Latitude: 35.6251, Longitude: 139.2437},
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+	}, pois)
+}
+
+func TestLoadStore(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "curated.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("name,category,lat,lng\n高尾山,peak,35.6251,139.2437\n"), 0o644))
+	overpassPath := filepath.Join(dir, "overpass.json")
+	require.NoError(t, os.WriteFile(overpassPath, []byte(`{"elements":[
+		{"type":"node","lat":35.6252,"lon":139.2436,"tags":{"natural":"peak","name":"高尾山"}},
+		{"type":"node","lat":35.6322,"lon":139.2699,"tags":{"railway":"station","name":"高尾山口駅"}}
+	]}`), 0o644))
+
+	store, err := LoadStore(csvPath, overpassPath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.Len(), "the Overpass peak duplicates the curated one")
+
+	_, err = LoadStore(filepath.Join(dir, "missing.csv"))
+	assert.Error(t, err)
+	_, err = LoadStore(filepath.Join(dir, "pois.txt"))
+	assert.Error(t, err)
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, NameSimilarity("高尾山", "高尾山"))
+	assert.Equal(t, 1.0, NameSimilarity("ＪＲ 吉祥寺駅", "jr吉祥寺駅"), "width, case and spaces are ignored")
+	assert.Equal(t, 1.0, NameSimilarity("井の頭恩賜公園（弁財天）", "井の頭恩賜公園弁財天"))
+
+	// Contained names
+	assert.GreaterOrEqual(t, NameSimilarity("スタート：吉祥寺駅", "吉祥寺駅"), 0.7)
+	assert.GreaterOrEqual(t, NameSimilarity("吉祥寺駅", "吉祥寺駅北口"), 0.7)
+	// Abbreviations
+	assert.InDelta(t, 0.83, NameSimilarity("井の頭公園", "井の頭恩賜公園"), 0.01)
+	// Different places of the same kind
+	assert.Less(t, NameSimilarity("善福寺公園", "井の頭恩賜公園"), 0.5)
+	assert.Less(t, NameSimilarity("駅", "吉祥寺駅"), 0.5)
+
+	assert.Zero(t, NameSimilarity("高尾山", "明治神宮"))
+	assert.Zero(t, NameSimilarity("", "高尾山"))
+	assert.Zero(t, NameSimilarity("・", "高尾山"))
+}
+
+func TestStoreMatch(t *testing.T) {
+	store := NewStore([]POI{
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+		{Name: "吉祥寺駅", Category: "station", Latitude: 35.7031, Longitude: 139.5798},
+		{Name: "高尾山", Reading: "たかおさん", Category: "peak", Latitude: 35.6251, Longitude: 139.2437},
+	})
+
+	// A hallucinated position 300m from the real station
+	match, ok := store.Match("吉祥寺駅", 35.7058, 139.5798, 1)
+	require.True(t, ok)
+	assert.Equal(t, "吉祥寺駅", match.Name)
+	assert.Equal(t, 1.0, match.Similarity)
+	assert.InDelta(t, 0.85, match.Confidence, 0.01)
+
+	match, ok = store.Match("井の頭公園", 35.7031, 139.5798, 1)
+	require.True(t, ok)
+	assert.Equal(t, "井の頭恩賜公園", match.Name)
+
+	match, ok = store.Match("たかおさん山頂", 35.6260, 139.2440, 1)
+	require.True(t, ok, "readings are matched too")
+	assert.Equal(t, "高尾山", match.Name)
+
+	_, ok = store.Match("高尾山", 35.7031, 139.5798, 1)
+	assert.False(t, ok, "too far away")
+	_, ok = store.Match("明治神宮", 35.7031, 139.5798, 1)
+	assert.False(t, ok, "no similar name")
+}
@@ -0,0 +1,338 @@
+// Package polyline implements the Google encoded polyline algorithm for
+// course geometry, with helpers to measure and resample decoded lines.
+package polyline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"potarin-backend/geo"
+	shared "potarin-shared"
+)
+
+// Supported precisions: 5 decimal digits is the Google default, 6 is used by
+// OSRM and Valhalla and for CourseDetails.Polyline
+const (
+	Precision5 = 5
+	Precision6 = 6
+
+	DefaultPrecision = Precision6
+)
+
+// ErrInvalid is returned when an encoded polyline cannot be decoded
+var ErrInvalid = errors.New("polyline: invalid encoding")
+
+func factor(precision int) (float64, error) {
+	if precision != Precision5 && precision != Precision6 {
+		return 0, fmt.Errorf("polyline: unsupported precision %d", precision)
+	}
+	return math.Pow10(precision), nil
+}
+
+// Encode encodes points with the given precision (5 or 6)
+func Encode(points []shared.Position, precision int) (string, error) {
+	scale, err := factor(precision)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var lastLat, lastLng int64
+	for _, point := range points {
+		lat := int64(math.Round(point.Latitude * scale))
+		lng := int64(math.Round(point.Longitude * scale))
+		writeValue(&b, lat-lastLat)
+		writeValue(&b, lng-lastLng)
+		lastLat, lastLng = lat, lng
+	}
+	return b.String(), nil
+}
+
+func writeValue(b *strings.Builder, value int64) {
+	shifted := uint64(value) << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+	for shifted >= 0x20 {
+		b.WriteByte(byte(0x20|shifted&0x1f) + 63)
+		shifted >>= 5
+	}
+	b.WriteByte(byte(shifted) + 63)
+}
+
+// Decode decodes an encoded polyline with the given precision (5 or 6). It
+// fails on truncated input, characters outside the alphabet and coordinates
+// out of range, so it also validates polylines received from clients.
+func Decode(encoded string, precision int) ([]shared.Position, error) {
+	scale, err := factor(precision)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []shared.Position
+	var lat, lng int64
+	for pos := 0; pos < len(encoded); {
+		var dLat, dLng int64
+		if dLat, pos, err = readValue(encoded, pos); err != nil {
+			return nil, err
+		}
+		if dLng, pos, err = readValue(encoded, pos); err != nil {
+			return nil, err
+		}
+		lat += dLat
+		lng += dLng
+
+		point := shared.Position{Latitude: float64(lat) / scale, Longitude: float64(lng) / scale}
+		if math.Abs(point.Latitude) > 90 || math.Abs(point.Longitude) > 180 {
+			return nil, fmt.Errorf("%w: coordinate %d out of range (%f, %f)", ErrInvalid, len(points), point.Latitude, point.Longitude)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// readValue reads one variable-length value starting at pos and returns it
+// with the position after it
+func readValue(encoded string, pos int) (int64, int, error) {
+	var result uint64
+	for shift := uint(0); ; shift += 5 {
+		if pos >= len(encoded) {
+			return 0, pos, fmt.Errorf("%w: truncated at offset %d", ErrInvalid, pos)
+		}
+		if shift > 60 {
+			return 0, pos, fmt.Errorf("%w: value too long at offset %d", ErrInvalid, pos)
+		}
+		c := encoded[pos]
+		if c < 63 || c > 126 {
+			return 0, pos, fmt.Errorf("%w: unexpected character %q at offset %d", ErrInvalid, c, pos)
+		}
+		pos++
+
+		chunk := uint64(c - 63)
+		result |= (chunk & 0x1f) << shift
+		if chunk < 0x20 {
+			break
+		}
+	}
+
+	value := int64(result >> 1)
+	if result&1 != 0 {
+		value = ^value
+	}
+	return value, pos, nil
+}
+
+// LengthKm returns the length of the line through points in km
+func LengthKm(points []shared.Position) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += segmentKm(points[i-1], points[i])
+	}
+	return total
+}
+
+// CumulativeKm returns the distance along the line at each point, starting with 0
+func CumulativeKm(points []shared.Position) []float64 {
+	distances := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		distances[i] = distances[i-1] + segmentKm(points[i-1], points[i])
+	}
+	return distances
+}
+
+// Bounds is the bounding box of a line
+type Bounds struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Contains reports whether the point lies in the bounding box
+func (b Bounds) Contains(point shared.Position) bool {
+	return point.Latitude >= b.MinLatitude && point.Latitude <= b.MaxLatitude &&
+		point.Longitude >= b.MinLongitude && point.Longitude <= b.MaxLongitude
+}
+
+// BoundsOf returns the bounding box of points; ok is false when there are none
+func BoundsOf(points []shared.Position) (bounds Bounds, ok bool) {
+	if len(points) == 0 {
+		return Bounds{}, false
+	}
+	bounds = Bounds{
+		MinLatitude: points[0].Latitude, MinLongitude: points[0].Longitude,
+		MaxLatitude: points[0].Latitude, MaxLongitude: points[0].Longitude,
+	}
+	for _, point := range points[1:] {
+		bounds.MinLatitude = math.Min(bounds.MinLatitude, point.Latitude)
+		bounds.MinLongitude = math.Min(bounds.MinLongitude, point.Longitude)
+		bounds.MaxLatitude = math.Max(bounds.MaxLatitude, point.Latitude)
+		bounds.MaxLongitude = math.Max(bounds.MaxLongitude, point.Longitude)
+	}
+	return bounds, true
+}
+
+// Resample returns points every intervalKm along the line, interpolated
+// linearly between the original points. The first and last points are
+// always included, so the last interval may be shorter.
+func Resample(points []shared.Position, intervalKm float64) []shared.Position {
+	if len(points) < 2 || intervalKm <= 0 {
+		return append([]shared.Position(nil), points...)
+	}
+
+	resampled := []shared.Position{points[0]}
+	next := intervalKm
+	travelled := 0.0
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		length := segmentKm(from, to)
+		for length > 0 && next <= travelled+length {
+			resampled = append(resampled, interpolate(from, to, (next-travelled)/length))
+			next += intervalKm
+		}
+		travelled += length
+	}
+
+	last := points[len(points)-1]
+	if tail := resampled[len(resampled)-1]; segmentKm(tail, last) > 1e-6 {
+		resampled = append(resampled, last)
+	}
+	return resampled
+}
+
+// PointAt returns the point distanceKm along the line, clamped to its ends
+func PointAt(points []shared.Position, distanceKm float64) (shared.Position, bool) {
+	if len(points) == 0 {
+		return shared.Position{}, false
+	}
+	if distanceKm <= 0 {
+		return points[0], true
+	}
+
+	travelled := 0.0
+	for i := 1; i < len(points); i++ {
+		length := segmentKm(points[i-1], points[i])
+		if length > 0 && distanceKm <= travelled+length {
+			return interpolate(points[i-1], points[i], (distanceKm-travelled)/length), true
+		}
+		travelled += length
+	}
+	return points[len(points)-1], true
+}
+
+// Simplify removes points that are within toleranceKm of the line through
+// the points kept around them (Douglas-Peucker). The first and last points
+// are always kept.
+func Simplify(points []shared.Position, toleranceKm float64) []shared.Position {
+	if len(points) < 3 || toleranceKm <= 0 {
+		return append([]shared.Position(nil), points...)
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	plane := newPlane(points[0])
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		farthest, farthestKm := -1, toleranceKm
+		for i := first + 1; i < last; i++ {
+			if d := plane.segmentDistanceKm(points[i], points[first], points[last]); d > farthestKm {
+				farthest, farthestKm = i, d
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+
+	simplified := make([]shared.Position, 0, len(points))
+	for i, point := range points {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// Nearest returns the point of the line closest to point, its distance from
+// point and its distance along the line, all in km
+func Nearest(points []shared.Position, point shared.Position) (nearest shared.Position, distanceKm, alongKm float64, ok bool) {
+	if len(points) == 0 {
+		return shared.Position{}, 0, 0, false
+	}
+	if len(points) == 1 {
+		return points[0], segmentKm(points[0], point), 0, true
+	}
+
+	plane := newPlane(point)
+	distanceKm = math.Inf(1)
+	travelled := 0.0
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		length := segmentKm(from, to)
+		t := plane.project(point, from, to)
+		candidate := interpolate(from, to, t)
+		if d := segmentKm(candidate, point); d < distanceKm {
+			nearest, distanceKm, alongKm = candidate, d, travelled+length*t
+		}
+		travelled += length
+	}
+	return nearest, distanceKm, alongKm, true
+}
+
+// plane is a local equirectangular projection in km, accurate enough to
+// compare distances within a course
+type plane struct {
+	kmPerDegreeLongitude float64
+}
+
+// kmPerDegreeLatitude is the length of a degree of latitude
+const kmPerDegreeLatitude = 111.195
+
+func newPlane(origin shared.Position) plane {
+	return plane{kmPerDegreeLongitude: kmPerDegreeLatitude * math.Cos(origin.Latitude*math.Pi/180)}
+}
+
+func (p plane) xy(point shared.Position) (float64, float64) {
+	return point.Longitude * p.kmPerDegreeLongitude, point.Latitude * kmPerDegreeLatitude
+}
+
+// project returns the fraction, clamped to [0, 1], of the way from from to
+// to of the segment point closest to point
+func (p plane) project(point, from, to shared.Position) float64 {
+	px, py := p.xy(point)
+	ax, ay := p.xy(from)
+	bx, by := p.xy(to)
+	dx, dy := bx-ax, by-ay
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	t := ((px-ax)*dx + (py-ay)*dy) / (dx*dx + dy*dy)
+	return math.Max(0, math.Min(1, t))
+}
+
+// segmentDistanceKm returns the distance of point from the segment from-to
+func (p plane) segmentDistanceKm(point, from, to shared.Position) float64 {
+	t := p.project(point, from, to)
+	px, py := p.xy(point)
+	cx, cy := p.xy(interpolate(from, to, t))
+	return math.Hypot(px-cx, py-cy)
+}
+
+func segmentKm(from, to shared.Position) float64 {
+	return geo.HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+}
+
+// interpolate returns the point at fraction t between from and to. Linear
+// interpolation of degrees is accurate enough for route segments.
+func interpolate(from, to shared.Position, t float64) shared.Position {
+	return shared.Position{
+		Latitude:  from.Latitude + (to.Latitude-from.Latitude)*t,
+		Longitude: from.Longitude + (to.Longitude-from.Longitude)*t,
+	}
+}
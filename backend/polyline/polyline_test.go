@@ -0,0 +1,188 @@
+package polyline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "potarin-shared"
+)
+
+// googleExample is the example from Google's polyline algorithm documentation
+var googleExample = []shared.Position{
+	{Latitude: 38.5, Longitude: -120.2},
+	{Latitude: 40.7, Longitude: -120.95},
+	{Latitude: 43.252, Longitude: -126.453},
+}
+
+func TestEncode(t *testing.T) {
+	encoded, err := Encode(googleExample, Precision5)
+	require.NoError(t, err)
+	assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", encoded)
+
+	empty, err := Encode(nil, Precision6)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = Encode(googleExample, 7)
+	assert.Error(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	points, err := Decode("_p~iF~ps|U_ulLnnqC_mqNvxq`@", Precision5)
+	require.NoError(t, err)
+	require.Len(t, points, len(googleExample))
+	for i := range points {
+		assert.InDelta(t, googleExample[i].Latitude, points[i].Latitude, 1e-9)
+		assert.InDelta(t, googleExample[i].Longitude, points[i].Longitude, 1e-9)
+	}
+}
+
+func TestRoundTripPrecision6(t *testing.T) {
+	points := []shared.Position{
+		{Latitude: 35.681236, Longitude: 139.767125},
+		{Latitude: 35.6736, Longitude: 139.756},
+		{Latitude: -33.856784, Longitude: 151.215297},
+		{Latitude: 0, Longitude: 0},
+	}
+	encoded, err := Encode(points, Precision6)
+	require.NoError(t, err)
+
+	decoded, err := Decode(encoded, Precision6)
+	require.NoError(t, err)
+	require.Len(t, decoded, len(points))
+	for i := range points {
+		assert.InDelta(t, points[i].Latitude, decoded[i].Latitude, 1e-9)
+		assert.InDelta(t, points[i].Longitude, decoded[i].Longitude, 1e-9)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"truncated value", "_p~iF~ps|"},
+		{"missing longitude", "_p~iF"},
+		{"invalid character", "_p~iF ps|U"},
+		{"out of range", "_p~iF~ps|U_p~iF~ps|U_p~iF~ps|U"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.encoded, Precision5)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestLengthAndCumulative(t *testing.T) {
+	// 0.01 degrees of latitude is about 1.112km
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.01, Longitude: 139.0},
+		{Latitude: 35.02, Longitude: 139.0},
+	}
+
+	assert.InDelta(t, 2.224, LengthKm(points), 0.001)
+	cumulative := CumulativeKm(points)
+	require.Len(t, cumulative, 3)
+	assert.Equal(t, 0.0, cumulative[0])
+	assert.InDelta(t, 1.112, cumulative[1], 0.001)
+	assert.InDelta(t, LengthKm(points), cumulative[2], 1e-9)
+	assert.Equal(t, 0.0, LengthKm(points[:1]))
+}
+
+func TestBoundsOf(t *testing.T) {
+	bounds, ok := BoundsOf(googleExample)
+	require.True(t, ok)
+	assert.Equal(t, Bounds{MinLatitude: 38.5, MinLongitude: -126.453, MaxLatitude: 43.252, MaxLongitude: -120.2}, bounds)
+	assert.True(t, bounds.Contains(shared.Position{Latitude: 40, Longitude: -122}))
+	assert.False(t, bounds.Contains(shared.Position{Latitude: 37, Longitude: -122}))
+
+	_, ok = BoundsOf(nil)
+	assert.False(t, ok)
+}
+
+func TestResample(t *testing.T) {
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.01, Longitude: 139.0},
+		{Latitude: 35.02, Longitude: 139.0},
+	}
+
+	resampled := Resample(points, 0.5)
+	// 2.224km every 0.5km: 0, 0.5, 1.0, 1.5, 2.0 and the end
+	require.Len(t, resampled, 6)
+	assert.Equal(t, points[0], resampled[0])
+	assert.Equal(t, points[2], resampled[5])
+	cumulative := CumulativeKm(resampled)
+	for i := 1; i < 5; i++ {
+		assert.InDelta(t, 0.5*float64(i), cumulative[i], 1e-3)
+	}
+
+	// An interval dividing the length evenly does not duplicate the end
+	assert.Len(t, Resample(points, LengthKm(points)/2), 3)
+	assert.Equal(t, points, Resample(points, 0))
+}
+
+func TestPointAt(t *testing.T) {
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.02, Longitude: 139.0},
+	}
+
+	middle, ok := PointAt(points, LengthKm(points)/2)
+	require.True(t, ok)
+	assert.InDelta(t, 35.01, middle.Latitude, 1e-9)
+
+	end, _ := PointAt(points, 100)
+	assert.Equal(t, points[1], end)
+	start, _ := PointAt(points, -1)
+	assert.Equal(t, points[0], start)
+
+	_, ok = PointAt(nil, 1)
+	assert.False(t, ok)
+}
+
+func TestSimplify(t *testing.T) {
+	// A straight line north with a 1m wiggle and a 100m detour east
+	points := []shared.Position{
+		{Latitude: 35.000, Longitude: 139.0},
+		{Latitude: 35.001, Longitude: 139.00001},
+		{Latitude: 35.002, Longitude: 139.0},
+		{Latitude: 35.003, Longitude: 139.0011},
+		{Latitude: 35.004, Longitude: 139.0},
+		{Latitude: 35.005, Longitude: 139.0},
+	}
+
+	simplified := Simplify(points, 0.01)
+	assert.Equal(t, []shared.Position{points[0], points[2], points[3], points[4], points[5]}, simplified)
+	assert.Equal(t, []shared.Position{points[0], points[5]}, Simplify(points, 0.2))
+	assert.Equal(t, points, Simplify(points, 0))
+	assert.Equal(t, points[:2], Simplify(points[:2], 1))
+}
+
+func TestNearest(t *testing.T) {
+	points := []shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.01, Longitude: 139.0},
+		{Latitude: 35.01, Longitude: 139.01},
+	}
+
+	// 100m west of the middle of the first segment
+	nearest, distanceKm, alongKm, ok := Nearest(points, shared.Position{Latitude: 35.005, Longitude: 138.9989})
+	require.True(t, ok)
+	assert.InDelta(t, 35.005, nearest.Latitude, 1e-9)
+	assert.InDelta(t, 139.0, nearest.Longitude, 1e-9)
+	assert.InDelta(t, 0.1, distanceKm, 0.005)
+	assert.InDelta(t, LengthKm(points[:2])/2, alongKm, 1e-6)
+
+	// Beyond the end of the line
+	nearest, _, alongKm, _ = Nearest(points, shared.Position{Latitude: 35.01, Longitude: 139.02})
+	assert.Equal(t, points[2], nearest)
+	assert.InDelta(t, LengthKm(points), alongKm, 1e-6)
+
+	_, _, _, ok = Nearest(nil, points[0])
+	assert.False(t, ok)
+}
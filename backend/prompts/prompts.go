@@ -0,0 +1,332 @@
+// Package prompts loads the text/template prompts used for course
+// generation. The templates are embedded in the binary and can be replaced by
+// a directory with the same files, which may be reloaded while running.
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+//go:embed templates
+var embedded embed.FS
+
+// Template names every prompt set must provide
+const (
+	SuggestionsSystem = "suggestions_system.tmpl"
+	SuggestionsUser   = "suggestions_user.tmpl"
+	DetailsSystem     = "details_system.tmpl"
+	DetailsUser       = "details_user.tmpl"
+	TrackSystem       = "track_system.tmpl"
+	TrackUser         = "track_user.tmpl"
+)
+
+// versionFile holds the identifier of a prompt set
+const versionFile = "VERSION"
+
+// Coordinates is a position passed to the prompt templates
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Landmark is a real place near the location, given to the model so that
+// courses are built from places that exist
+type Landmark struct {
+	Name       string
+	Reading    string // Kana reading; may be empty
+	Category   string // e.g. "park", "shrine"; may be empty
+	Latitude   float64
+	Longitude  float64
+	DistanceKm float64 // from the location
+}
+
+// SuggestionsData is the data of the suggestions templates
+type SuggestionsData struct {
+	Area                string
+	Prefecture          string
+	LocationDescription string
+	Locality            string // e.g. "武蔵野市吉祥寺本町一丁目"; empty when unknown
+	CourseType          string
+	Distance            string  // short, medium or long; may be empty
+	DistanceMinKm       float64 // zero when open
+	DistanceMaxKm       float64 // zero when open
+	TimeBudgetMinutes   int     // zero when none
+	Location            *Coordinates
+	RouteShape          string       // loop, one_way or out_and_back; empty for any
+	EndLocation         *Coordinates // requested end of a one_way course
+	Scenery             string
+	Difficulty          string
+	AvoidHills          bool
+	Landmarks           []Landmark
+}
+
+// DetailsData is the data of the details templates
+type DetailsData struct {
+	Area        string
+	Prefecture  string
+	Locality    string // City and neighbourhood of the start point; empty when unknown
+	Title       string
+	Description string
+	RouteShape  string       // loop, one_way or out_and_back; may be empty
+	EndPoint    *Coordinates // end of the suggested course; may be nil
+	Landmarks   []Landmark
+}
+
+// TrackPoint is a point of an uploaded track
+type TrackPoint struct {
+	Latitude   float64
+	Longitude  float64
+	DistanceKm float64 // along the track from its start
+}
+
+// TrackData is the data of the track templates
+type TrackData struct {
+	Area       string
+	Prefecture string
+	Locality   string // City and neighbourhood of the start; empty when unknown
+	Name       string // from the uploaded file; may be empty
+	CourseType string
+	DistanceKm float64
+	RouteShape string // loop, one_way or out_and_back
+	// SouthWest and NorthEast are the corners of the track's bounding box
+	SouthWest Coordinates
+	NorthEast Coordinates
+	Points    []TrackPoint
+	// Landmarks near the track; DistanceKm is the distance from the track
+	Landmarks []Landmark
+}
+
+// Set is a parsed and validated set of prompt templates
+type Set struct {
+	Version   string
+	templates *template.Template
+}
+
+// Load parses the templates in dir, or the embedded templates when dir is
+// empty, and validates them by rendering every prompt with sample data
+func Load(dir string) (*Set, error) {
+	var fsys fs.FS
+	if dir == "" {
+		sub, err := fs.Sub(embedded, "templates")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	} else {
+		fsys = os.DirFS(dir)
+	}
+	return parse(fsys)
+}
+
+func parse(fsys fs.FS) (*Set, error) {
+	version, err := fs.ReadFile(fsys, versionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt version: %w", err)
+	}
+
+	templates, err := template.New("").Option("missingkey=error").ParseFS(fsys, "*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt templates: %w", err)
+	}
+
+	set := &Set{
+		Version:   strings.TrimSpace(string(version)),
+		templates: templates,
+	}
+	if set.Version == "" {
+		return nil, fmt.Errorf("prompt version is empty")
+	}
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// sampleLandmarks fill the optional landmark sections when validating
+var sampleLandmarks = []Landmark{
+	{Name: "皇居外苑", Reading: "こうきょがいえん", Category: "park", Latitude: 35.680, Longitude: 139.757, DistanceKm: 0.9},
+	{Name: "東京駅", Latitude: 35.681, Longitude: 139.767},
+}
+
+// validate renders each required template with sample data that exercises
+// every optional section
+func (s *Set) validate() error {
+	samples := map[string]any{
+		SuggestionsSystem: SuggestionsData{Area: "東京"},
+		SuggestionsUser: SuggestionsData{
+			Area:                "東京",
+			Prefecture:          "東京都",
+			LocationDescription: "東京都内",
+			Locality:            "千代田区丸の内一丁目",
+			CourseType:          "walking",
+			Distance:            "short",
+			DistanceMinKm:       1,
+			DistanceMaxKm:       3,
+			TimeBudgetMinutes:   45,
+			Location:            &Coordinates{Latitude: 35.681236, Longitude: 139.767125},
+			RouteShape:          "one_way",
+			EndLocation:         &Coordinates{Latitude: 35.685175, Longitude: 139.752799},
+			Scenery:             "nature",
+			Difficulty:          "easy",
+			AvoidHills:          true,
+			Landmarks:           sampleLandmarks,
+		},
+		DetailsSystem: DetailsData{Area: "東京"},
+		DetailsUser: DetailsData{
+			Area:        "東京",
+			Prefecture:  "東京都",
+			Locality:    "千代田区丸の内一丁目",
+			Title:       "サンプル",
+			Description: "サンプル",
+			RouteShape:  "one_way",
+			EndPoint:    &Coordinates{Latitude: 35.685175, Longitude: 139.752799},
+			Landmarks:   sampleLandmarks,
+		},
+		TrackSystem: TrackData{Area: "東京"},
+		TrackUser: TrackData{
+			Area:       "東京",
+			Prefecture: "東京都",
+			Locality:   "千代田区丸の内一丁目",
+			Name:       "サンプル",
+			CourseType: "jogging",
+			DistanceKm: 1.6,
+			RouteShape: "one_way",
+			SouthWest:  Coordinates{Latitude: 35.680, Longitude: 139.752},
+			NorthEast:  Coordinates{Latitude: 35.686, Longitude: 139.767},
+			Points: []TrackPoint{
+				{Latitude: 35.681236, Longitude: 139.767125},
+				{Latitude: 35.685175, Longitude: 139.752799, DistanceKm: 1.6},
+			},
+			Landmarks: sampleLandmarks,
+		},
+	}
+
+	for name, data := range samples {
+		rendered, err := s.Render(name, data)
+		if err != nil {
+			return err
+		}
+		if rendered == "" {
+			return fmt.Errorf("prompt template %s renders empty", name)
+		}
+	}
+	return nil
+}
+
+// Render executes the named template. Surrounding whitespace is trimmed so
+// template files may end with a newline.
+func (s *Set) Render(name string, data any) (string, error) {
+	tmpl := s.templates.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("prompt template %s not found", name)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// Store holds the current prompt set and can reload it from its directory
+type Store struct {
+	dir     string
+	current atomic.Pointer[Set]
+}
+
+// NewStore loads the prompts from dir, or the embedded prompts when dir is empty
+func NewStore(dir string) (*Store, error) {
+	set, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	store := &Store{dir: dir}
+	store.current.Store(set)
+	return store, nil
+}
+
+// Default returns a store with the embedded prompts, which are validated by tests
+func Default() *Store {
+	store, err := NewStore("")
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompts are invalid: %v", err))
+	}
+	return store
+}
+
+// Current returns the prompt set in use
+func (s *Store) Current() *Set {
+	return s.current.Load()
+}
+
+// Reload loads the prompts again. On failure the current set is kept.
+func (s *Store) Reload() error {
+	set, err := Load(s.dir)
+	if err != nil {
+		return err
+	}
+	s.current.Store(set)
+	return nil
+}
+
+// Watch polls the prompt directory and reloads it whenever a file changes,
+// until ctx is done. It is meant for development and does nothing for the
+// embedded prompts.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if s.dir == "" {
+		return
+	}
+
+	last := s.fingerprint()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := s.fingerprint()
+		if current == last {
+			continue
+		}
+		last = current
+
+		if err := s.Reload(); err != nil {
+			log.Printf("Keeping prompt version %s, reload failed: %v", s.Current().Version, err)
+			continue
+		}
+		log.Printf("Reloaded prompts, version %s", s.Current().Version)
+	}
+}
+
+// fingerprint summarizes the names, sizes and modification times of the
+// files in the prompt directory
+func (s *Store) fingerprint() string {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
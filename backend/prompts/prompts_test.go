@@ -0,0 +1,311 @@
+package prompts
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyEmbedded writes the embedded templates to a temporary directory
+func copyEmbedded(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := fs.WalkDir(embedded, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := embedded.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, d.Name()), data, 0o644)
+	})
+	require.NoError(t, err)
+	return dir
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+	assert.NotEmpty(t, set.Version)
+}
+
+func TestRender_SuggestionsUser(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	rendered, err := set.Render(SuggestionsUser, SuggestionsData{
+		Area:                "東京",
+		Prefecture:          "東京都",
+		LocationDescription: "東京都内または近郊",
+		CourseType:          "walking",
+		Distance:            "medium",
+		DistanceMinKm:       3,
+		DistanceMaxKm:       10,
+		Location:            &Coordinates{Latitude: 35.681236, Longitude: 139.767125},
+		Scenery:             "nature",
+		AvoidHills:          true,
+	})
+	require.NoError(t, err)
+
+	expected := `東京周辺で3-10kmのwalkingコースを3つ提案してください。
+
+要求詳細:
+- コースタイプ: walking
+- 希望距離: 3-10km
+- 場所: 東京都内または近郊
+- 現在地周辺: 緯度35.681236, 経度139.767125
+- 景観の希望: 自然豊か
+- 坂道を避ける
+
+各コースには以下を含めてください:
+- 魅力的なタイトル（日本語）
+- コースの特徴と見どころの説明（日本語）
+- 正確な距離（km）
+- 推定所要時間（分）
+- 難易度レベル
+- 出発地点の緯度経度
+- コースの形状（loop: 周回、one_way: 片道、out_and_back: 往復）
+- ゴール地点の緯度経度（loopとout_and_backでは出発地点と同じ）
+- ハイライト（見どころ）リスト（日本語）
+- コースの概要（日本語）
+
+実在する東京都の場所を基にして、具体的で実用的なコースを提案してください。
+**重要**: すべてのテキスト内容は必ず日本語で記述してください。英語は使用しないでください。`
+	assert.Equal(t, expected, rendered)
+}
+
+func TestRender_DistanceRange(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		data     SuggestionsData
+		expected string
+	}{
+		{SuggestionsData{DistanceMinKm: 15, DistanceMaxKm: 40}, "東京周辺で15-40kmのcyclingコース"},
+		{SuggestionsData{DistanceMinKm: 40}, "東京周辺で40km以上のcyclingコース"},
+		{SuggestionsData{DistanceMaxKm: 7.5, TimeBudgetMinutes: 30}, "- 希望距離: 7.5km以下\n- 所要時間: 30分以内\n"},
+	}
+	for _, tt := range tests {
+		data := tt.data
+		data.Area, data.Prefecture, data.CourseType = "東京", "東京都", "cycling"
+		rendered, err := set.Render(SuggestionsUser, data)
+		require.NoError(t, err)
+		assert.Contains(t, rendered, tt.expected)
+	}
+}
+
+func TestRender_Locality(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	rendered, err := set.Render(SuggestionsUser, SuggestionsData{
+		Area:                "東京",
+		Prefecture:          "東京都",
+		LocationDescription: "東京都内",
+		Locality:            "武蔵野市吉祥寺本町一丁目",
+		CourseType:          "walking",
+		Distance:            "short",
+		DistanceMinKm:       1,
+		DistanceMaxKm:       3,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rendered, "武蔵野市吉祥寺本町一丁目周辺で1-3kmのwalkingコースを3つ提案してください。"), rendered)
+	assert.Contains(t, rendered, "- 場所: 東京都内\n- 地区: 武蔵野市吉祥寺本町一丁目（")
+
+	rendered, err = set.Render(DetailsUser, DetailsData{
+		Area:        "東京",
+		Prefecture:  "東京都",
+		Locality:    "八王子市高尾町",
+		Title:       "高尾山ハイキング",
+		Description: "高尾山口駅から山頂を目指します。",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "高尾山口駅から山頂を目指します。\n\n出発地点は八王子市高尾町です。")
+
+	rendered, err = set.Render(DetailsUser, DetailsData{Area: "東京", Prefecture: "東京都", Title: "皇居ラン", Description: "皇居を一周します。"})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "皇居を一周します。\n\n以下の詳細情報を含めてください:")
+	assert.NotContains(t, rendered, "出発地点は")
+}
+
+func TestRender_Landmarks(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	landmarks := []Landmark{
+		{Name: "井の頭恩賜公園", Reading: "いのかしらおんしこうえん", Category: "park", Latitude: 35.6997, Longitude: 139.5731, DistanceKm: 0.7},
+		{Name: "吉祥寺駅", Latitude: 35.7031, Longitude: 139.5798},
+	}
+	expected := `
+- 井の頭恩賜公園（いのかしらおんしこうえん） [park]: 緯度35.699700, 経度139.573100, 距離0.7km
+- 吉祥寺駅: 緯度35.703100, 経度139.579800
+`
+
+	rendered, err := set.Render(SuggestionsUser, SuggestionsData{
+		Area:       "東京",
+		Prefecture: "東京都",
+		CourseType: "walking",
+		Distance:   "short",
+		AvoidHills: true,
+		Landmarks:  landmarks,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "- 坂道を避ける\n\n周辺に実在するスポット")
+	assert.Contains(t, rendered, expected)
+
+	rendered, err = set.Render(DetailsUser, DetailsData{
+		Area:        "東京",
+		Prefecture:  "東京都",
+		Title:       "吉祥寺散歩",
+		Description: "井の頭公園を巡ります。",
+		Landmarks:   landmarks,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "井の頭公園を巡ります。\n\n周辺に実在するスポット")
+	assert.Contains(t, rendered, expected)
+}
+
+func TestRender_RouteShape(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	rendered, err := set.Render(SuggestionsUser, SuggestionsData{
+		Area:          "東京",
+		Prefecture:    "東京都",
+		CourseType:    "cycling",
+		Distance:      "long",
+		DistanceMinKm: 40,
+		Location:      &Coordinates{Latitude: 35.681236, Longitude: 139.767125},
+		RouteShape:    "one_way",
+		EndLocation:   &Coordinates{Latitude: 35.6251, Longitude: 139.2437},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "- コースの形状: 片道コース（出発地点とは別の場所がゴール）\n- ゴール地点: 緯度35.625100, 経度139.243700\n")
+
+	rendered, err = set.Render(DetailsUser, DetailsData{
+		Area:        "東京",
+		Prefecture:  "東京都",
+		Title:       "高尾山へ",
+		Description: "都心から高尾山まで走ります。",
+		RouteShape:  "one_way",
+		EndPoint:    &Coordinates{Latitude: 35.6251, Longitude: 139.2437},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "このコースは片道コース（出発地点とは別の場所がゴール）です。最後のwaypoint（ゴール地点）は緯度35.625100, 経度139.243700にしてください。")
+
+	rendered, err = set.Render(DetailsUser, DetailsData{Area: "東京", Prefecture: "東京都", Title: "皇居ラン", Description: "皇居を一周します。", RouteShape: "loop"})
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "皇居を一周します。\n\nこのコースは周回コース（別の道を通って出発地点に戻る）です。最後のwaypoint（ゴール地点）は出発地点と同じ座標にしてください。\n")
+}
+
+func TestLoad_Directory(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(t *testing.T, dir string)
+		version string
+		wantErr bool
+	}{
+		{
+			name: "override version",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, versionFile), []byte("test-2\n"), 0o644))
+			},
+			version: "test-2",
+		},
+		{
+			name: "missing version",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, versionFile)))
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing template",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, DetailsUser)))
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown field",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, DetailsSystem), []byte("{{.Station}}"), 0o644))
+			},
+			wantErr: true,
+		},
+		{
+			name: "syntax error",
+			modify: func(t *testing.T, dir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, SuggestionsSystem), []byte("{{.Area"), 0o644))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := copyEmbedded(t)
+			tt.modify(t, dir)
+
+			set, err := Load(dir)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, set.Version)
+		})
+	}
+}
+
+func TestStore_Reload(t *testing.T) {
+	dir := copyEmbedded(t)
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	original := store.Current().Version
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, versionFile), []byte("reloaded"), 0o644))
+	require.NoError(t, store.Reload())
+	assert.Equal(t, "reloaded", store.Current().Version)
+
+	// A broken edit keeps the last good prompts
+	require.NoError(t, os.WriteFile(filepath.Join(dir, SuggestionsUser), []byte("{{"), 0o644))
+	assert.Error(t, store.Reload())
+	assert.Equal(t, "reloaded", store.Current().Version)
+	assert.NotEqual(t, original, store.Current().Version)
+}
+
+func TestRender_Track(t *testing.T) {
+	set, err := Load("")
+	require.NoError(t, err)
+
+	rendered, err := set.Render(TrackUser, TrackData{
+		Area:       "東京",
+		Prefecture: "東京都",
+		Name:       "皇居ラン",
+		CourseType: "jogging",
+		DistanceKm: 4.96,
+		RouteShape: "loop",
+		SouthWest:  Coordinates{Latitude: 35.675, Longitude: 139.745},
+		NorthEast:  Coordinates{Latitude: 35.692, Longitude: 139.762},
+		Points: []TrackPoint{
+			{Latitude: 35.6812, Longitude: 139.7528},
+			{Latitude: 35.6918, Longitude: 139.7544, DistanceKm: 1.24},
+		},
+		Landmarks: []Landmark{{Name: "桜田門", Latitude: 35.6779, Longitude: 139.7527, DistanceKm: 0.05}},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rendered, "以下のルート「皇居ラン」をjoggingコースとして紹介する"), rendered)
+	assert.Contains(t, rendered, "- 距離: 5.0km\n- コースの形状: 周回コース（別の道を通って出発地点に戻る）\n- 範囲: 緯度35.675000〜35.692000, 経度139.745000〜139.762000\n")
+	assert.Contains(t, rendered, "- 0.0km: 緯度35.681200, 経度139.752800\n- 1.2km: 緯度35.691800, 経度139.754400\n")
+	assert.Contains(t, rendered, "ルート沿いに実在するスポット")
+	assert.Contains(t, rendered, "- 桜田門: 緯度35.677900, 経度139.752700")
+}
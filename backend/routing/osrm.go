@@ -0,0 +1,213 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a routing request when the client has no timeout
+const DefaultTimeout = 10 * time.Second
+
+// maxErrorBody limits how much of an error response is kept in EngineError
+const maxErrorBody = 512
+
+// valhallaNoPath is the Valhalla error code for unroutable locations
+const valhallaNoPath = 442
+
+// osrmResponse is the subset of an OSRM route response that is used. Valhalla
+// returns the same structure when asked for format "osrm", and its own error
+// fields on failure.
+type osrmResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	ErrorCode int    `json:"error_code"`
+	Error     string `json:"error"`
+
+	Routes []struct {
+		Geometry string  `json:"geometry"`
+		Distance float64 `json:"distance"`
+		Duration float64 `json:"duration"`
+	} `json:"routes"`
+
+	Waypoints []struct {
+		Location [2]float64 `json:"location"`
+	} `json:"waypoints"`
+}
+
+// OSRMClient routes with the OSRM HTTP API
+// (GET /route/v1/{profile}/{lng},{lat};...)
+type OSRMClient struct {
+	baseURL    string
+	httpClient *http.Client
+	// Profiles maps routing profiles to the server's profile names
+	Profiles map[Profile]string
+}
+
+// NewOSRMClient creates a client for the OSRM server at baseURL. A nil
+// httpClient uses a client with DefaultTimeout.
+func NewOSRMClient(baseURL string, httpClient *http.Client) *OSRMClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &OSRMClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+		Profiles:   map[Profile]string{Foot: "foot", Bicycle: "bicycle"},
+	}
+}
+
+// Route implements Router
+func (c *OSRMClient) Route(ctx context.Context, profile Profile, points []Point) (*Route, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("routing: at least 2 points are required, got %d", len(points))
+	}
+
+	coordinates := make([]string, len(points))
+	for i, point := range points {
+		coordinates[i] = strconv.FormatFloat(point.Longitude, 'f', 6, 64) + "," +
+			strconv.FormatFloat(point.Latitude, 'f', 6, 64)
+	}
+	url := fmt.Sprintf("%s/route/v1/%s/%s?overview=full&geometries=polyline6&steps=false",
+		c.baseURL, c.profileName(profile), strings.Join(coordinates, ";"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doRoute(c.httpClient, req)
+}
+
+func (c *OSRMClient) profileName(profile Profile) string {
+	if name, ok := c.Profiles[profile]; ok {
+		return name
+	}
+	return string(profile)
+}
+
+// ValhallaClient routes with the Valhalla /route API, requesting
+// OSRM-compatible output
+type ValhallaClient struct {
+	baseURL    string
+	httpClient *http.Client
+	// Costings maps routing profiles to Valhalla costing models
+	Costings map[Profile]string
+}
+
+// NewValhallaClient creates a client for the Valhalla server at baseURL. A
+// nil httpClient uses a client with DefaultTimeout.
+func NewValhallaClient(baseURL string, httpClient *http.Client) *ValhallaClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &ValhallaClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+		Costings:   map[Profile]string{Foot: "pedestrian", Bicycle: "bicycle"},
+	}
+}
+
+type valhallaLocation struct {
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+	Type string  `json:"type"`
+}
+
+type valhallaRequest struct {
+	Locations []valhallaLocation `json:"locations"`
+	Costing   string             `json:"costing"`
+	Format    string             `json:"format"`
+}
+
+// Route implements Router
+func (c *ValhallaClient) Route(ctx context.Context, profile Profile, points []Point) (*Route, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("routing: at least 2 points are required, got %d", len(points))
+	}
+
+	costing, ok := c.Costings[profile]
+	if !ok {
+		costing = string(profile)
+	}
+	body := valhallaRequest{Costing: costing, Format: "osrm"}
+	for _, point := range points {
+		// "through" locations keep the route from stopping at waypoints
+		body.Locations = append(body.Locations, valhallaLocation{Lat: point.Latitude, Lon: point.Longitude, Type: "through"})
+	}
+	body.Locations[0].Type = "break"
+	body.Locations[len(body.Locations)-1].Type = "break"
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/route", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doRoute(c.httpClient, req)
+}
+
+// doRoute sends a route request and parses the OSRM-format response
+func doRoute(httpClient *http.Client, req *http.Request) (*Route, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("routing request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing response: %w", err)
+	}
+
+	var parsed osrmResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, &EngineError{StatusCode: resp.StatusCode, Message: truncate(string(data), maxErrorBody)}
+		}
+		return nil, fmt.Errorf("failed to parse routing response: %w", err)
+	}
+
+	switch {
+	case parsed.Code == "NoRoute" || parsed.ErrorCode == valhallaNoPath:
+		return nil, ErrNoRoute
+	case parsed.ErrorCode != 0:
+		return nil, &EngineError{StatusCode: resp.StatusCode, Code: strconv.Itoa(parsed.ErrorCode), Message: parsed.Error}
+	case resp.StatusCode != http.StatusOK || (parsed.Code != "" && parsed.Code != "Ok"):
+		return nil, &EngineError{StatusCode: resp.StatusCode, Code: parsed.Code, Message: parsed.Message}
+	case len(parsed.Routes) == 0:
+		return nil, ErrNoRoute
+	}
+
+	route := parsed.Routes[0]
+	result := &Route{
+		Polyline:        route.Geometry,
+		DistanceKm:      route.Distance / 1000,
+		DurationSeconds: route.Duration,
+	}
+	for _, waypoint := range parsed.Waypoints {
+		result.Snapped = append(result.Snapped, Point{Latitude: waypoint.Location[1], Longitude: waypoint.Location[0]})
+	}
+	return result, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+var (
+	_ Router = (*OSRMClient)(nil)
+	_ Router = (*ValhallaClient)(nil)
+)
@@ -0,0 +1,64 @@
+// Package routing computes road and path geometry between waypoints with an
+// external routing engine.
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoRoute is returned when the engine finds no route through the points
+var ErrNoRoute = errors.New("routing: no route found")
+
+// Profile selects the network and speeds a route is computed for
+type Profile string
+
+const (
+	Foot    Profile = "foot"
+	Bicycle Profile = "bicycle"
+)
+
+// ProfileFor returns the routing profile of a course type
+func ProfileFor(courseType string) Profile {
+	if courseType == "cycling" {
+		return Bicycle
+	}
+	return Foot
+}
+
+// Point is a coordinate to route through
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Route is the result of routing through a list of points
+type Route struct {
+	// Polyline is the geometry as a Google encoded polyline with precision 6
+	Polyline        string
+	DistanceKm      float64
+	DurationSeconds float64
+	// Snapped are the points moved onto the network by the engine, in the
+	// order they were given; nil when the engine does not report them
+	Snapped []Point
+}
+
+// Router computes a route visiting points in order
+type Router interface {
+	Route(ctx context.Context, profile Profile, points []Point) (*Route, error)
+}
+
+// EngineError is returned when the routing engine rejects a request
+type EngineError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *EngineError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("routing engine error (%d %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("routing engine error (%d): %s", e.StatusCode, e.Message)
+}
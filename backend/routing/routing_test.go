@@ -0,0 +1,146 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPoints = []Point{
+	{Latitude: 35.681236, Longitude: 139.767125},
+	{Latitude: 35.6736, Longitude: 139.756},
+}
+
+func TestProfileFor(t *testing.T) {
+	assert.Equal(t, Foot, ProfileFor("walking"))
+	assert.Equal(t, Foot, ProfileFor("jogging"))
+	assert.Equal(t, Bicycle, ProfileFor("cycling"))
+}
+
+func TestOSRMClient_Route(t *testing.T) {
+	var requestedPath, requestedQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath, requestedQuery = r.URL.Path, r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"code":"Ok","routes":[{"geometry":"_p~iF~ps|U_ulLnnqC","distance":2345.6,"duration":1800}],
+			"waypoints":[{"location":[139.7672,35.6813],"distance":3.1},{"location":[139.7561,35.6735],"distance":12.4}]}`))
+	}))
+	defer server.Close()
+
+	route, err := NewOSRMClient(server.URL+"/", nil).Route(context.Background(), Bicycle, testPoints)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/route/v1/bicycle/139.767125,35.681236;139.756000,35.673600", requestedPath)
+	assert.Contains(t, requestedQuery, "geometries=polyline6")
+	assert.Contains(t, requestedQuery, "overview=full")
+	assert.Equal(t, &Route{
+		Polyline:        "_p~iF~ps|U_ulLnnqC",
+		DistanceKm:      2.3456,
+		DurationSeconds: 1800,
+		Snapped:         []Point{{Latitude: 35.6813, Longitude: 139.7672}, {Latitude: 35.6735, Longitude: 139.7561}},
+	}, route)
+}
+
+func TestOSRMClient_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "no route",
+			status: http.StatusBadRequest,
+			body:   `{"code":"NoRoute","message":"Impossible route between points"}`,
+			check: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrNoRoute)
+			},
+		},
+		{
+			name:   "invalid request",
+			status: http.StatusBadRequest,
+			body:   `{"code":"InvalidQuery","message":"Query string malformed"}`,
+			check: func(t *testing.T, err error) {
+				var engineErr *EngineError
+				require.True(t, errors.As(err, &engineErr))
+				assert.Equal(t, "InvalidQuery", engineErr.Code)
+				assert.Equal(t, http.StatusBadRequest, engineErr.StatusCode)
+			},
+		},
+		{
+			name:   "server error without JSON",
+			status: http.StatusBadGateway,
+			body:   `bad gateway`,
+			check: func(t *testing.T, err error) {
+				var engineErr *EngineError
+				require.True(t, errors.As(err, &engineErr))
+				assert.Equal(t, "bad gateway", engineErr.Message)
+			},
+		},
+		{
+			name:   "no routes in response",
+			status: http.StatusOK,
+			body:   `{"code":"Ok","routes":[]}`,
+			check: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrNoRoute)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewOSRMClient(server.URL, nil).Route(context.Background(), Foot, testPoints)
+			require.Error(t, err)
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestOSRMClient_RequiresTwoPoints(t *testing.T) {
+	_, err := NewOSRMClient("http://localhost", nil).Route(context.Background(), Foot, testPoints[:1])
+	assert.Error(t, err)
+}
+
+func TestValhallaClient_Route(t *testing.T) {
+	var received valhallaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/route", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"code":"Ok","routes":[{"geometry":"abc","distance":1500,"duration":1200}]}`))
+	}))
+	defer server.Close()
+
+	route, err := NewValhallaClient(server.URL, nil).Route(context.Background(), Foot, testPoints)
+	require.NoError(t, err)
+
+	assert.Equal(t, "pedestrian", received.Costing)
+	assert.Equal(t, "osrm", received.Format)
+	require.Len(t, received.Locations, 2)
+	assert.Equal(t, 35.681236, received.Locations[0].Lat)
+	assert.Equal(t, 139.767125, received.Locations[0].Lon)
+	assert.Equal(t, 1.5, route.DistanceKm)
+	assert.Equal(t, "abc", route.Polyline)
+}
+
+func TestValhallaClient_NoPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error_code":442,"error":"No path could be found for input","status_code":400}`))
+	}))
+	defer server.Close()
+
+	_, err := NewValhallaClient(server.URL, nil).Route(context.Background(), Bicycle, testPoints)
+	assert.ErrorIs(t, err, ErrNoRoute)
+}
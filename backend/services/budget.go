@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"potarin-backend/usage"
+)
+
+// BudgetedGenerator stops generating suggestions and annotating tracks once
+// the daily budget of the usage tracker is spent. Details are still
+// generated so courses that were already suggested can be opened.
+type BudgetedGenerator struct {
+	next    CourseGenerator
+	tracker *usage.Tracker
+}
+
+// NewBudgetedGenerator wraps next with daily budget enforcement
+func NewBudgetedGenerator(next CourseGenerator, tracker *usage.Tracker) *BudgetedGenerator {
+	return &BudgetedGenerator{next: next, tracker: tracker}
+}
+
+// GenerateCourseSuggestions fails with ErrKindBudgetExceeded once the budget is spent
+func (g *BudgetedGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	if err := g.checkBudget(); err != nil {
+		return nil, err
+	}
+	return g.next.GenerateCourseSuggestions(ctx, request)
+}
+
+// GenerateCourseDetails is not subject to the budget
+func (g *BudgetedGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	return g.next.GenerateCourseDetails(ctx, suggestion)
+}
+
+// AnnotateTrack fails with ErrKindBudgetExceeded once the budget is spent
+func (g *BudgetedGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	if err := g.checkBudget(); err != nil {
+		return nil, err
+	}
+	return annotateTrack(ctx, g.next, track)
+}
+
+// StreamCourseSuggestions fails with ErrKindBudgetExceeded once the budget is spent
+func (g *BudgetedGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	if err := g.checkBudget(); err != nil {
+		return err
+	}
+	return streamSuggestions(ctx, g.next, request, emit)
+}
+
+func (g *BudgetedGenerator) checkBudget() error {
+	if !g.tracker.BudgetExceeded() {
+		return nil
+	}
+	return &GenerationError{
+		Kind:       ErrKindBudgetExceeded,
+		RetryAfter: g.tracker.UntilNextDay(),
+		Err:        fmt.Errorf("daily budget of $%.2f exceeded", g.tracker.Budget()),
+	}
+}
+
+var (
+	_ CourseGenerator    = (*BudgetedGenerator)(nil)
+	_ SuggestionStreamer = (*BudgetedGenerator)(nil)
+	_ TrackAnnotator     = (*BudgetedGenerator)(nil)
+)
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/usage"
+)
+
+func TestOpenAIService_RecordsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"suggestions\":[]}"}}],"usage":{"prompt_tokens":1200,"completion_tokens":800,"total_tokens":2000}}`))
+	}))
+	defer server.Close()
+
+	tracker := usage.NewTracker(usage.DefaultPrices, 0)
+	service := newRetryTestService(server.URL).WithUsageTracker(tracker)
+
+	ctx := usage.WithLabels(context.Background(), usage.Labels{Endpoint: "suggestions", Client: "test"})
+	_, err := service.GenerateCourseSuggestions(ctx, CourseRequest{CourseType: "walking", Distance: "short"})
+	require.NoError(t, err)
+
+	entries := tracker.Entries(tracker.Today())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "suggestions", entries[0].Endpoint)
+	assert.Equal(t, "test", entries[0].Client)
+	assert.Equal(t, 1200, entries[0].PromptTokens)
+	assert.Equal(t, 800, entries[0].CompletionTokens)
+	assert.InDelta(t, 0.011, entries[0].Cost, 1e-9)
+}
+
+func TestBudgetedGenerator(t *testing.T) {
+	tracker := usage.NewTracker(usage.PriceTable{"gpt-4o": {Input: 1_000_000, Output: 0}}, 1)
+	generator := NewBudgetedGenerator(NewFakeGenerator(), tracker)
+	ctx := context.Background()
+	request := CourseRequest{CourseType: "walking", Distance: "short"}
+
+	_, err := generator.GenerateCourseSuggestions(ctx, request)
+	require.NoError(t, err)
+
+	tracker.Record(ctx, "gpt-4o", 1, 0)
+
+	_, err = generator.GenerateCourseSuggestions(ctx, request)
+	require.Error(t, err)
+	genErr := AsGenerationError(err)
+	assert.Equal(t, ErrKindBudgetExceeded, genErr.Kind)
+	assert.Greater(t, genErr.RetryAfter, time.Duration(0))
+
+	err = generator.StreamCourseSuggestions(ctx, request, func(CourseSuggestion) error { return nil })
+	assert.Equal(t, ErrKindBudgetExceeded, AsGenerationError(err).Kind)
+
+	// Details of already suggested courses are still available
+	_, err = generator.GenerateCourseDetails(ctx, CourseSuggestion{ID: "course-1", Title: "テスト", CourseType: "walking"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"potarin-backend/cache"
+	"potarin-backend/geo"
+)
+
+// DefaultGeohashPrecision buckets locations into cells of roughly 1.2km x 0.6km
+const DefaultGeohashPrecision = 6
+
+// CacheOptions configures a CachedGenerator
+type CacheOptions struct {
+	// TTL of cached responses; entries never expire when zero
+	TTL time.Duration
+	// GeohashPrecision is the geohash length used to bucket request locations
+	GeohashPrecision int
+}
+
+// CachedGenerator caches suggestions by normalized request and details by
+// suggestion in front of another CourseGenerator. Backend failures are
+// logged and treated as cache misses so they never fail a request.
+type CachedGenerator struct {
+	next    CourseGenerator
+	backend cache.Backend
+	options CacheOptions
+}
+
+// NewCachedGenerator wraps next with a response cache stored in backend
+func NewCachedGenerator(next CourseGenerator, backend cache.Backend, options CacheOptions) *CachedGenerator {
+	if options.GeohashPrecision <= 0 {
+		options.GeohashPrecision = DefaultGeohashPrecision
+	}
+	return &CachedGenerator{next: next, backend: backend, options: options}
+}
+
+// GenerateCourseSuggestions returns cached suggestions for an equivalent request or generates and stores them
+func (g *CachedGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	key := suggestionsCacheKey(request, g.options.GeohashPrecision)
+
+	var cached CourseSuggestionsResponse
+	if entry := g.load(key, &cached); entry != nil {
+		cached.Metadata = cacheHitMetadata(cached.Metadata, entry)
+		return &cached, nil
+	}
+
+	result, err := g.next.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	g.store(key, result)
+	return result, nil
+}
+
+// GenerateCourseDetails returns cached details for the same suggestion or generates and stores them
+func (g *CachedGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	key := detailsCacheKey(suggestion)
+
+	var cached CourseDetailsResponse
+	if entry := g.load(key, &cached); entry != nil {
+		cached.Metadata = cacheHitMetadata(cached.Metadata, entry)
+		return &cached, nil
+	}
+
+	result, err := g.next.GenerateCourseDetails(ctx, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	g.store(key, result)
+	return result, nil
+}
+
+// AnnotateTrack returns cached details for the same track or annotates and stores them
+func (g *CachedGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	key := trackCacheKey(track)
+
+	var cached CourseDetailsResponse
+	if entry := g.load(key, &cached); entry != nil {
+		cached.Metadata = cacheHitMetadata(cached.Metadata, entry)
+		return &cached, nil
+	}
+
+	result, err := annotateTrack(ctx, g.next, track)
+	if err != nil {
+		return nil, err
+	}
+	g.store(key, result)
+	return result, nil
+}
+
+// StreamCourseSuggestions emits cached suggestions at once on a hit. On a miss
+// it streams from the wrapped generator when possible and caches the
+// complete result afterwards.
+func (g *CachedGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	key := suggestionsCacheKey(request, g.options.GeohashPrecision)
+
+	var cached CourseSuggestionsResponse
+	if g.load(key, &cached) != nil {
+		return emitSuggestions(cached.Suggestions, emit)
+	}
+
+	streamer, ok := g.next.(SuggestionStreamer)
+	if !ok {
+		result, err := g.next.GenerateCourseSuggestions(ctx, request)
+		if err != nil {
+			return err
+		}
+		g.store(key, result)
+		return emitSuggestions(result.Suggestions, emit)
+	}
+
+	collected := &CourseSuggestionsResponse{}
+	err := streamer.StreamCourseSuggestions(ctx, request, func(suggestion CourseSuggestion) error {
+		collected.Suggestions = append(collected.Suggestions, suggestion)
+		return emit(suggestion)
+	})
+	if err != nil {
+		return err
+	}
+	g.store(key, collected)
+	return nil
+}
+
+// load decodes the entry for key into out and returns the entry, or nil on a miss
+func (g *CachedGenerator) load(key string, out any) *cache.Entry {
+	entry, err := g.backend.Get(key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			log.Printf("Response cache read failed: %v", err)
+		}
+		return nil
+	}
+	if err := json.Unmarshal(entry.Value, out); err != nil {
+		log.Printf("Discarding undecodable response cache entry: %v", err)
+		_ = g.backend.Delete(key)
+		return nil
+	}
+
+	return entry
+}
+
+// cacheHitMetadata marks the metadata stored with a response as a cache hit
+func cacheHitMetadata(stored *GenerationMetadata, entry *cache.Entry) *GenerationMetadata {
+	metadata := GenerationMetadata{}
+	if stored != nil {
+		metadata = *stored
+	}
+	storedAt := entry.StoredAt
+	metadata.CacheHit = true
+	metadata.CachedAt = &storedAt
+	return &metadata
+}
+
+// store encodes value into the cache under key
+func (g *CachedGenerator) store(key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to encode response for cache: %v", err)
+		return
+	}
+	if err := g.backend.Set(key, data, g.options.TTL); err != nil {
+		log.Printf("Response cache write failed: %v", err)
+	}
+}
+
+// suggestionsCacheKey normalizes a course request into a cache key. Text
+// fields are case and space insensitive, a missing preference equals its
+// zero value and the location is reduced to its geohash cell, so nearby
+// requests for the same kind of course share an entry.
+func suggestionsCacheKey(request CourseRequest, geohashPrecision int) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+
+	var scenery, difficulty string
+	avoidHills := false
+	if request.Preferences != nil {
+		if request.Preferences.Scenery != nil {
+			scenery = normalize(*request.Preferences.Scenery)
+		}
+		if request.Preferences.Difficulty != nil {
+			difficulty = normalize(*request.Preferences.Difficulty)
+		}
+		if request.Preferences.AvoidHills != nil {
+			avoidHills = *request.Preferences.AvoidHills
+		}
+	}
+
+	distanceRange := "-"
+	if request.DistanceRange != nil {
+		distanceRange = fmt.Sprintf("%g-%g", request.DistanceRange.MinKm, request.DistanceRange.MaxKm)
+	}
+
+	cell, endCell := "-", "-"
+	if request.Location != nil {
+		cell = geo.EncodeGeohash(request.Location.Latitude, request.Location.Longitude, geohashPrecision)
+	}
+	if request.EndLocation != nil {
+		endCell = geo.EncodeGeohash(request.EndLocation.Latitude, request.EndLocation.Longitude, geohashPrecision)
+	}
+
+	return strings.Join([]string{
+		"suggestions",
+		normalize(request.CourseType),
+		normalize(request.Distance),
+		cell,
+		scenery,
+		difficulty,
+		strconv.FormatBool(avoidHills),
+		normalize(request.RouteShape),
+		endCell,
+		distanceRange,
+		strconv.Itoa(request.TimeBudgetMinutes),
+	}, "|")
+}
+
+// detailsCacheKey keys details on the suggestion ID. Model generated IDs such
+// as "course-1" repeat across unrelated responses, so a fingerprint of the
+// suggestion's identifying fields is appended.
+func detailsCacheKey(suggestion CourseSuggestion) string {
+	end := "-"
+	if suggestion.EndPoint != nil {
+		end = fmt.Sprintf("%.6f,%.6f", suggestion.EndPoint.Latitude, suggestion.EndPoint.Longitude)
+	}
+	fingerprint := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%.6f,%.6f\x00%.3f\x00%s\x00%s",
+		suggestion.Title,
+		suggestion.CourseType,
+		suggestion.Difficulty,
+		suggestion.StartPoint.Latitude,
+		suggestion.StartPoint.Longitude,
+		suggestion.Distance,
+		suggestion.RouteShape,
+		end,
+	)))
+	return "details|" + suggestion.ID + "|" + hex.EncodeToString(fingerprint[:8])
+}
+
+// trackCacheKey keys annotated tracks on the course ID, which covers the
+// geometry and course type, and the name, which the title is based on
+func trackCacheKey(track TrackRequest) string {
+	return "track|" + track.ID() + "|" + track.Name
+}
+
+var (
+	_ CourseGenerator    = (*CachedGenerator)(nil)
+	_ SuggestionStreamer = (*CachedGenerator)(nil)
+	_ TrackAnnotator     = (*CachedGenerator)(nil)
+)
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+)
+
+// countingGenerator counts calls to the wrapped generator
+type countingGenerator struct {
+	CourseGenerator
+	suggestions int32
+	details     int32
+}
+
+func (g *countingGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	atomic.AddInt32(&g.suggestions, 1)
+	return g.CourseGenerator.GenerateCourseSuggestions(ctx, request)
+}
+
+func (g *countingGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	atomic.AddInt32(&g.details, 1)
+	return g.CourseGenerator.GenerateCourseDetails(ctx, suggestion)
+}
+
+func newTestCachedGenerator() (*CachedGenerator, *countingGenerator) {
+	counter := &countingGenerator{CourseGenerator: NewFakeGenerator()}
+	return NewCachedGenerator(counter, cache.NewMemoryBackend(cache.Limits{}), CacheOptions{}), counter
+}
+
+func TestCachedGenerator_Suggestions(t *testing.T) {
+	generator, counter := newTestCachedGenerator()
+	ctx := context.Background()
+	request := CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.681236, Longitude: 139.767125},
+	}
+
+	first, err := generator.GenerateCourseSuggestions(ctx, request)
+	require.NoError(t, err)
+	assert.Nil(t, first.Metadata)
+
+	// A few meters away is the same geohash cell
+	nearby := request
+	nearby.Location = &Position{Latitude: 35.681300, Longitude: 139.767200}
+	second, err := generator.GenerateCourseSuggestions(ctx, nearby)
+	require.NoError(t, err)
+
+	require.NotNil(t, second.Metadata)
+	assert.True(t, second.Metadata.CacheHit)
+	assert.NotNil(t, second.Metadata.CachedAt)
+	assert.Equal(t, first.Suggestions, second.Suggestions)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter.suggestions))
+
+	// Osaka is a different cell
+	far := request
+	far.Location = &Position{Latitude: 34.702485, Longitude: 135.495951}
+	third, err := generator.GenerateCourseSuggestions(ctx, far)
+	require.NoError(t, err)
+	assert.Nil(t, third.Metadata)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&counter.suggestions))
+}
+
+func TestCachedGenerator_Details(t *testing.T) {
+	generator, counter := newTestCachedGenerator()
+	ctx := context.Background()
+	suggestion := CourseSuggestion{
+		ID:         "course-1",
+		Title:      "皇居ラン",
+		Distance:   5,
+		Difficulty: "easy",
+		CourseType: "jogging",
+		StartPoint: Position{Latitude: 35.681236, Longitude: 139.767125},
+	}
+
+	_, err := generator.GenerateCourseDetails(ctx, suggestion)
+	require.NoError(t, err)
+	cached, err := generator.GenerateCourseDetails(ctx, suggestion)
+	require.NoError(t, err)
+	require.NotNil(t, cached.Metadata)
+	assert.True(t, cached.Metadata.CacheHit)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter.details))
+
+	// The same model generated ID for a different course is not a hit
+	other := suggestion
+	other.Title = "隅田川ウォーク"
+	_, err = generator.GenerateCourseDetails(ctx, other)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&counter.details))
+}
+
+func TestCachedGenerator_Stream(t *testing.T) {
+	generator := NewCachedGenerator(NewFakeGenerator(), cache.NewMemoryBackend(cache.Limits{}), CacheOptions{})
+	ctx := context.Background()
+	request := CourseRequest{CourseType: "cycling", Distance: "long"}
+
+	var streamed []CourseSuggestion
+	require.NoError(t, generator.StreamCourseSuggestions(ctx, request, func(suggestion CourseSuggestion) error {
+		streamed = append(streamed, suggestion)
+		return nil
+	}))
+
+	cached, err := generator.GenerateCourseSuggestions(ctx, request)
+	require.NoError(t, err)
+	require.NotNil(t, cached.Metadata)
+	assert.True(t, cached.Metadata.CacheHit)
+	assert.Equal(t, streamed, cached.Suggestions)
+}
+
+func TestSuggestionsCacheKey(t *testing.T) {
+	nature, natureUpper := "nature", " Nature "
+	noHills := false
+
+	base := CourseRequest{CourseType: "walking", Distance: "short"}
+
+	tests := []struct {
+		name  string
+		a, b  CourseRequest
+		equal bool
+	}{
+		{
+			name:  "missing preferences equal zero values",
+			a:     base,
+			b:     CourseRequest{CourseType: "walking", Distance: "short", Preferences: &CoursePreferences{AvoidHills: &noHills}},
+			equal: true,
+		},
+		{
+			name:  "text fields are normalized",
+			a:     CourseRequest{CourseType: "walking", Distance: "short", Preferences: &CoursePreferences{Scenery: &nature}},
+			b:     CourseRequest{CourseType: "Walking", Distance: "short", Preferences: &CoursePreferences{Scenery: &natureUpper}},
+			equal: true,
+		},
+		{
+			name:  "distance differs",
+			a:     base,
+			b:     CourseRequest{CourseType: "walking", Distance: "long"},
+			equal: false,
+		},
+		{
+			name:  "location differs from no location",
+			a:     base,
+			b:     CourseRequest{CourseType: "walking", Distance: "short", Location: &Position{Latitude: 35.68, Longitude: 139.76}},
+			equal: false,
+		},
+		{
+			name:  "route shape differs",
+			a:     CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeLoop},
+			b:     CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeOutAndBack},
+			equal: false,
+		},
+		{
+			name:  "distance range differs",
+			a:     base,
+			b:     CourseRequest{CourseType: "walking", Distance: "short", DistanceRange: &DistanceRange{MinKm: 1, MaxKm: 2}},
+			equal: false,
+		},
+		{
+			name:  "end location differs",
+			a:     CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeOneWay},
+			b:     CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeOneWay, EndLocation: &Position{Latitude: 35.68, Longitude: 139.76}},
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := suggestionsCacheKey(tt.a, DefaultGeohashPrecision)
+			b := suggestionsCacheKey(tt.b, DefaultGeohashPrecision)
+			if tt.equal {
+				assert.Equal(t, a, b)
+			} else {
+				assert.NotEqual(t, a, b)
+			}
+		})
+	}
+}
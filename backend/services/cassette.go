@@ -0,0 +1,225 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cassette modes for recording and replaying chat completion calls
+const (
+	CassetteModeOff    = "off"
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+)
+
+// ErrCassetteNotFound is returned in replay mode when no recording matches a request
+var ErrCassetteNotFound = errors.New("cassette not found")
+
+// CassetteTransport is an http.RoundTripper that records chat completion
+// request/response pairs to disk, or replays previously recorded ones.
+// Recordings are keyed by a hash of the prompt messages and response schema.
+type CassetteTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+	mu   sync.Mutex
+}
+
+// CassetteInteraction is a single recorded request/response pair
+type CassetteInteraction struct {
+	Key        string           `json:"key"`
+	RecordedAt time.Time        `json:"recordedAt"`
+	Request    CassetteRequest  `json:"request"`
+	Response   CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body"`
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// NewCassetteTransport creates a transport for the given mode. In record mode
+// requests are forwarded to next (http.DefaultTransport when nil).
+func NewCassetteTransport(mode, dir string, next http.RoundTripper) (*CassetteTransport, error) {
+	switch mode {
+	case CassetteModeRecord, CassetteModeReplay:
+	default:
+		return nil, fmt.Errorf("invalid cassette mode: %q", mode)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("cassette directory is required")
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if mode == CassetteModeRecord {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+
+	return &CassetteTransport{
+		mode: mode,
+		dir:  dir,
+		next: next,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		if t.mode == CassetteModeReplay {
+			return nil, fmt.Errorf("%w: unsupported path %s in replay mode", ErrCassetteNotFound, req.URL.Path)
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	key, err := CassetteKey(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == CassetteModeReplay {
+		return t.replay(req, key)
+	}
+	return t.record(req, key, body)
+}
+
+func (t *CassetteTransport) replay(req *http.Request, key string) (*http.Response, error) {
+	data, err := os.ReadFile(t.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: key %s", ErrCassetteNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var interaction CassetteInteraction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", key, err)
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *CassetteTransport) record(req *http.Request, key string, body []byte) (*http.Response, error) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := CassetteInteraction{
+		Key:        key,
+		RecordedAt: time.Now(),
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Body:   string(body),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     recordableHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.WriteFile(t.path(key), data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (t *CassetteTransport) path(key string) string {
+	return filepath.Join(t.dir, key+".json")
+}
+
+// CassetteKey computes the recording key for a chat completion request body
+// from its messages and response format only, so that changes to unrelated
+// parameters such as the model do not invalidate recordings.
+func CassetteKey(body []byte) (string, error) {
+	var request struct {
+		Messages       json.RawMessage `json:"messages"`
+		ResponseFormat json.RawMessage `json:"response_format"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return "", fmt.Errorf("failed to parse chat completion request: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(request.Messages)
+	h.Write([]byte{0})
+	h.Write(request.ResponseFormat)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordableHeader keeps only the response headers worth replaying
+func recordableHeader(header http.Header) http.Header {
+	kept := make(http.Header)
+	for _, name := range []string{"Content-Type", "Retry-After"} {
+		if values := header.Values(name); len(values) > 0 {
+			kept[name] = values
+		}
+	}
+	return kept
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cassetteTestCompletion = `{
+  "id": "chatcmpl-test",
+  "object": "chat.completion",
+  "model": "gpt-4o",
+  "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "{\"suggestions\":[]}"}}]
+}`
+
+func newCassetteTestClient(t *testing.T, mode, dir, baseURL string) *openai.Client {
+	t.Helper()
+
+	transport, err := NewCassetteTransport(mode, dir, nil)
+	require.NoError(t, err)
+
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = baseURL
+	clientConfig.HTTPClient = &http.Client{Transport: transport}
+	return openai.NewClientWithConfig(clientConfig)
+}
+
+func TestCassetteTransport_RecordThenReplay(t *testing.T) {
+	var upstreamCalls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&upstreamCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(cassetteTestCompletion))
+	}))
+
+	dir := t.TempDir()
+	request := openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "テスト"},
+		},
+	}
+
+	recorder := newCassetteTestClient(t, CassetteModeRecord, dir, upstream.URL+"/v1")
+	recorded, err := recorder.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstreamCalls))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Replay must work without the upstream server
+	upstream.Close()
+
+	player := newCassetteTestClient(t, CassetteModeReplay, dir, "http://127.0.0.1:0/v1")
+	replayed, err := player.CreateChatCompletion(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, recorded.Choices[0].Message.Content, replayed.Choices[0].Message.Content)
+
+	// A different prompt has no recording
+	request.Messages[0].Content = "別のプロンプト"
+	_, err = player.CreateChatCompletion(context.Background(), request)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCassetteNotFound)
+}
+
+func TestCassetteKey(t *testing.T) {
+	base := `{"model":"gpt-4o","messages":[{"role":"user","content":"a"}],"response_format":{"type":"json_object"}}`
+	otherModel := `{"model":"llama3","messages":[{"role":"user","content":"a"}],"response_format":{"type":"json_object"}}`
+	otherPrompt := `{"model":"gpt-4o","messages":[{"role":"user","content":"b"}],"response_format":{"type":"json_object"}}`
+
+	baseKey, err := CassetteKey([]byte(base))
+	require.NoError(t, err)
+
+	modelKey, err := CassetteKey([]byte(otherModel))
+	require.NoError(t, err)
+	assert.Equal(t, baseKey, modelKey, "model should not affect the key")
+
+	promptKey, err := CassetteKey([]byte(otherPrompt))
+	require.NoError(t, err)
+	assert.NotEqual(t, baseKey, promptKey)
+
+	_, err = CassetteKey([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNewCassetteTransport_InvalidMode(t *testing.T) {
+	_, err := NewCassetteTransport("rewind", t.TempDir(), nil)
+	assert.Error(t, err)
+
+	_, err = NewCassetteTransport(CassetteModeReplay, "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// CoalescingGenerator shares a single upstream call between concurrent
+// identical requests. Requests are matched with the same normalization as
+// the response cache. The upstream call keeps running while at least one
+// caller is still waiting and is cancelled once every caller has given up.
+type CoalescingGenerator struct {
+	next             CourseGenerator
+	geohashPrecision int
+
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// inflightCall is an upstream call shared by one or more callers
+type inflightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	result  any
+	err     error
+}
+
+// NewCoalescingGenerator wraps next so identical in-flight requests are coalesced
+func NewCoalescingGenerator(next CourseGenerator, geohashPrecision int) *CoalescingGenerator {
+	if geohashPrecision <= 0 {
+		geohashPrecision = DefaultGeohashPrecision
+	}
+	return &CoalescingGenerator{
+		next:             next,
+		geohashPrecision: geohashPrecision,
+		calls:            make(map[string]*inflightCall),
+	}
+}
+
+// GenerateCourseSuggestions joins an identical in-flight request or starts a new upstream call
+func (g *CoalescingGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	result, err := g.do(ctx, suggestionsCacheKey(request, g.geohashPrecision), func(ctx context.Context) (any, error) {
+		return g.next.GenerateCourseSuggestions(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers get their own copy so none can modify another's response
+	response := *result.(*CourseSuggestionsResponse)
+	response.Suggestions = append([]CourseSuggestion(nil), response.Suggestions...)
+	return &response, nil
+}
+
+// GenerateCourseDetails joins an identical in-flight request or starts a new upstream call
+func (g *CoalescingGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	result, err := g.do(ctx, detailsCacheKey(suggestion), func(ctx context.Context) (any, error) {
+		return g.next.GenerateCourseDetails(ctx, suggestion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := *result.(*CourseDetailsResponse)
+	response.Course.Waypoints = append([]Waypoint(nil), response.Course.Waypoints...)
+	return &response, nil
+}
+
+// AnnotateTrack joins an identical in-flight request or starts a new upstream call
+func (g *CoalescingGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	result, err := g.do(ctx, trackCacheKey(track), func(ctx context.Context) (any, error) {
+		return annotateTrack(ctx, g.next, track)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := *result.(*CourseDetailsResponse)
+	response.Course.Waypoints = append([]Waypoint(nil), response.Course.Waypoints...)
+	return &response, nil
+}
+
+// StreamCourseSuggestions is not coalesced: every stream is tied to its own
+// client. Generators without streaming support fall back to the coalesced call.
+func (g *CoalescingGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	if streamer, ok := g.next.(SuggestionStreamer); ok {
+		return streamer.StreamCourseSuggestions(ctx, request, emit)
+	}
+
+	result, err := g.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return err
+	}
+	return emitSuggestions(result.Suggestions, emit)
+}
+
+// do runs fn once per key among concurrent callers. The upstream context
+// keeps the first caller's values but not its cancellation; it is cancelled
+// when the last waiting caller's context is done.
+func (g *CoalescingGenerator) do(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		upstreamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &inflightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			result, err := fn(upstreamCtx)
+
+			g.mu.Lock()
+			call.result, call.err = result, err
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+
+			cancel()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is left to receive the result; later callers start afresh
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+var (
+	_ CourseGenerator    = (*CoalescingGenerator)(nil)
+	_ SuggestionStreamer = (*CoalescingGenerator)(nil)
+	_ TrackAnnotator     = (*CoalescingGenerator)(nil)
+)
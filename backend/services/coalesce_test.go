@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingGenerator blocks every call until release is closed or its context is done
+type blockingGenerator struct {
+	FakeGenerator
+	calls     int32
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newBlockingGenerator() *blockingGenerator {
+	return &blockingGenerator{
+		started:   make(chan struct{}, 10),
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}, 10),
+	}
+}
+
+func (g *blockingGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	atomic.AddInt32(&g.calls, 1)
+	g.started <- struct{}{}
+	select {
+	case <-g.release:
+		return g.FakeGenerator.GenerateCourseSuggestions(ctx, request)
+	case <-ctx.Done():
+		g.cancelled <- struct{}{}
+		return nil, ctx.Err()
+	}
+}
+
+var coalesceTestRequest = CourseRequest{
+	CourseType: "walking",
+	Distance:   "short",
+	Location:   &Position{Latitude: 35.681236, Longitude: 139.767125},
+}
+
+func TestCoalescingGenerator_SharesUpstreamCall(t *testing.T) {
+	upstream := newBlockingGenerator()
+	generator := NewCoalescingGenerator(upstream, 0)
+
+	const callers = 5
+	results := make([]*CourseSuggestionsResponse, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := generator.GenerateCourseSuggestions(context.Background(), coalesceTestRequest)
+			assert.NoError(t, err)
+			results[i] = result
+		}(i)
+	}
+
+	<-upstream.started
+	// Give the remaining callers time to join the in-flight call
+	require.Eventually(t, func() bool {
+		generator.mu.Lock()
+		defer generator.mu.Unlock()
+		for _, call := range generator.calls {
+			return call.waiters == callers
+		}
+		return false
+	}, time.Second, time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstream.calls))
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, results[0].Suggestions, result.Suggestions)
+	}
+	assert.NotSame(t, results[0], results[1])
+}
+
+func TestCoalescingGenerator_CallerCancellation(t *testing.T) {
+	upstream := newBlockingGenerator()
+	generator := NewCoalescingGenerator(upstream, 0)
+
+	impatientCtx, cancelImpatient := context.WithCancel(context.Background())
+	impatientErr := make(chan error, 1)
+	go func() {
+		_, err := generator.GenerateCourseSuggestions(impatientCtx, coalesceTestRequest)
+		impatientErr <- err
+	}()
+	<-upstream.started
+
+	patientResult := make(chan *CourseSuggestionsResponse, 1)
+	go func() {
+		result, err := generator.GenerateCourseSuggestions(context.Background(), coalesceTestRequest)
+		assert.NoError(t, err)
+		patientResult <- result
+	}()
+	require.Eventually(t, func() bool {
+		generator.mu.Lock()
+		defer generator.mu.Unlock()
+		for _, call := range generator.calls {
+			return call.waiters == 2
+		}
+		return false
+	}, time.Second, time.Millisecond)
+
+	// The first caller leaving does not cancel the shared call
+	cancelImpatient()
+	assert.ErrorIs(t, <-impatientErr, context.Canceled)
+
+	close(upstream.release)
+	assert.NotNil(t, <-patientResult)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upstream.calls))
+	assert.Empty(t, upstream.cancelled)
+}
+
+func TestCoalescingGenerator_AllCallersCancelled(t *testing.T) {
+	upstream := newBlockingGenerator()
+	generator := NewCoalescingGenerator(upstream, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := generator.GenerateCourseSuggestions(ctx, coalesceTestRequest)
+		errs <- err
+	}()
+	<-upstream.started
+
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	// The upstream call is cancelled and a new caller starts a fresh call
+	select {
+	case <-upstream.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream call was not cancelled")
+	}
+
+	close(upstream.release)
+	result, err := generator.GenerateCourseSuggestions(context.Background(), coalesceTestRequest)
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&upstream.calls))
+}
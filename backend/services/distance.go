@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+)
+
+// WarnTimeBudgetExceeded reports a course estimated to take longer than the
+// time budget of its request
+const WarnTimeBudgetExceeded = "time_budget_exceeded"
+
+// suggestionDistanceTolerance is how far, as a fraction of the bound, a
+// suggested distance may lie outside the requested range. Suggested
+// distances are the model's round estimates.
+const suggestionDistanceTolerance = 0.1
+
+// DistanceRange is a course length in km. A zero bound is open.
+type DistanceRange struct {
+	MinKm float64 `json:"minKm,omitempty"`
+	MaxKm float64 `json:"maxKm,omitempty"`
+}
+
+// Contains reports whether distanceKm is within the range, allowing
+// tolerance as a fraction of the bounds, e.g. 0.1 for 10%
+func (r DistanceRange) Contains(distanceKm, tolerance float64) bool {
+	if r.MinKm > 0 && distanceKm < r.MinKm*(1-tolerance) {
+		return false
+	}
+	if r.MaxKm > 0 && distanceKm > r.MaxKm*(1+tolerance) {
+		return false
+	}
+	return true
+}
+
+// IsZero reports whether the range has no bound
+func (r DistanceRange) IsZero() bool {
+	return r.MinKm <= 0 && r.MaxKm <= 0
+}
+
+// Clamp moves distanceKm into the range
+func (r DistanceRange) Clamp(distanceKm float64) float64 {
+	if r.MaxKm > 0 {
+		distanceKm = math.Min(distanceKm, r.MaxKm)
+	}
+	return math.Max(distanceKm, r.MinKm)
+}
+
+// DistanceBuckets are the ranges of the short, medium and long distance
+// values of a request per course type. A long cycling course covers more
+// ground than a long walk.
+var DistanceBuckets = map[string]map[string]DistanceRange{
+	"walking": {
+		"short":  {MinKm: 1, MaxKm: 3},
+		"medium": {MinKm: 3, MaxKm: 10},
+		"long":   {MinKm: 10},
+	},
+	"jogging": {
+		"short":  {MinKm: 2, MaxKm: 5},
+		"medium": {MinKm: 5, MaxKm: 12},
+		"long":   {MinKm: 12},
+	},
+	"cycling": {
+		"short":  {MinKm: 5, MaxKm: 15},
+		"medium": {MinKm: 15, MaxKm: 40},
+		"long":   {MinKm: 40},
+	},
+}
+
+// BucketRange returns the range of a distance value for a course type.
+// Unknown course types use the walking ranges.
+func BucketRange(courseType, distance string) (DistanceRange, bool) {
+	buckets, ok := DistanceBuckets[courseType]
+	if !ok {
+		buckets = DistanceBuckets["walking"]
+	}
+	r, ok := buckets[distance]
+	return r, ok
+}
+
+// DistanceLimits returns the length asked for by the request: DistanceRange
+// when set, otherwise the range of Distance for the course type
+func (r CourseRequest) DistanceLimits() (DistanceRange, bool) {
+	if r.DistanceRange != nil && !r.DistanceRange.IsZero() {
+		return *r.DistanceRange, true
+	}
+	return BucketRange(r.CourseType, r.Distance)
+}
+
+// checkDistance returns a warning when the suggested distance is outside the
+// length the request asks for
+func checkDistance(request CourseRequest, suggestion CourseSuggestion) *ValidationWarning {
+	limits, ok := request.DistanceLimits()
+	if !ok || limits.Contains(suggestion.Distance, suggestionDistanceTolerance) {
+		return nil
+	}
+	return &ValidationWarning{
+		ID:      suggestion.ID,
+		Code:    WarnDistanceOutsideRange,
+		Message: fmt.Sprintf("「%s」の距離%.1fkmが希望の距離の範囲外のため除外しました", suggestion.Title, suggestion.Distance),
+	}
+}
+
+// DistanceFilteringGenerator removes suggestions whose distance is outside
+// the length the request asks for, and reports each removal as a warning on
+// the response
+type DistanceFilteringGenerator struct {
+	next CourseGenerator
+}
+
+// NewDistanceFilteringGenerator wraps next with the distance check
+func NewDistanceFilteringGenerator(next CourseGenerator) *DistanceFilteringGenerator {
+	return &DistanceFilteringGenerator{next: next}
+}
+
+// GenerateCourseSuggestions removes the suggestions of the wrong length
+func (g *DistanceFilteringGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	result, err := g.next.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, warnings := filterSuggestions(request, result.Suggestions, checkDistance)
+	filtered := *result
+	filtered.Suggestions = valid
+	filtered.Warnings = append(append([]ValidationWarning(nil), result.Warnings...), warnings...)
+	return &filtered, nil
+}
+
+// GenerateCourseDetails is passed through unchanged
+func (g *DistanceFilteringGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	return g.next.GenerateCourseDetails(ctx, suggestion)
+}
+
+// AnnotateTrack is passed through unchanged
+func (g *DistanceFilteringGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	return annotateTrack(ctx, g.next, track)
+}
+
+// StreamCourseSuggestions drops suggestions of the wrong length from the
+// stream. A stream has no place for warnings, so they are only logged.
+func (g *DistanceFilteringGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	return streamSuggestions(ctx, g.next, request, func(suggestion CourseSuggestion) error {
+		if warning := checkDistance(request, suggestion); warning != nil {
+			log.Printf("Dropped streamed suggestion %s: %s", suggestion.ID, warning.Code)
+			return nil
+		}
+		return emit(suggestion)
+	})
+}
+
+var (
+	_ CourseGenerator    = (*DistanceFilteringGenerator)(nil)
+	_ SuggestionStreamer = (*DistanceFilteringGenerator)(nil)
+	_ TrackAnnotator     = (*DistanceFilteringGenerator)(nil)
+)
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistanceFilteringGenerator(t *testing.T) {
+	suggestions := []CourseSuggestion{
+		{ID: "course-1", Distance: 4},
+		{ID: "course-2", Distance: 14},
+		{ID: "course-3", Distance: 30},
+		{ID: "course-4", Distance: 45},
+	}
+	upstream := &scriptedGenerator{responses: [][]CourseSuggestion{suggestions}}
+
+	tests := []struct {
+		name    string
+		request CourseRequest
+		kept    []string
+	}{
+		{
+			name:    "bucket of the course type",
+			request: CourseRequest{CourseType: "cycling", Distance: "medium"},
+			kept:    []string{"course-2", "course-3"},
+		},
+		{
+			name:    "range overrides the bucket",
+			request: CourseRequest{CourseType: "cycling", Distance: "medium", DistanceRange: &DistanceRange{MaxKm: 4.2}},
+			kept:    []string{"course-1"},
+		},
+		{
+			name:    "open range",
+			request: CourseRequest{CourseType: "cycling", DistanceRange: &DistanceRange{MinKm: 25}},
+			kept:    []string{"course-3", "course-4"},
+		},
+		{
+			name:    "no distance asked for",
+			request: CourseRequest{CourseType: "cycling"},
+			kept:    []string{"course-1", "course-2", "course-3", "course-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewDistanceFilteringGenerator(upstream).GenerateCourseSuggestions(context.Background(), tt.request)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, suggestion := range result.Suggestions {
+				ids = append(ids, suggestion.ID)
+			}
+			assert.Equal(t, tt.kept, ids)
+			assert.Len(t, result.Warnings, len(suggestions)-len(tt.kept))
+			for _, warning := range result.Warnings {
+				assert.Equal(t, WarnDistanceOutsideRange, warning.Code)
+			}
+		})
+	}
+}
+
+func TestDistanceFilteringGenerator_Stream(t *testing.T) {
+	// Without streaming support the complete result is filtered
+	upstream := plainGenerator{&scriptedGenerator{responses: [][]CourseSuggestion{{
+		{ID: "course-1", Distance: 2},
+		{ID: "course-2", Distance: 8},
+	}}}}
+
+	var ids []string
+	err := NewDistanceFilteringGenerator(upstream).StreamCourseSuggestions(context.Background(), CourseRequest{CourseType: "walking", Distance: "short"}, func(suggestion CourseSuggestion) error {
+		ids = append(ids, suggestion.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"course-1"}, ids)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"potarin-backend/elevation"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// DefaultElevationIntervalKm is the spacing of elevation profile samples
+const DefaultElevationIntervalKm = 0.1
+
+// ElevationGenerator adds an elevation profile to generated course details,
+// sampled along the routed polyline or, without one, along the straight
+// lines between waypoints. Courses outside the DEM coverage are returned
+// without elevation.
+type ElevationGenerator struct {
+	next       CourseGenerator
+	source     elevation.Source
+	intervalKm float64
+}
+
+// NewElevationGenerator wraps next with elevation profiles sampled every
+// intervalKm (DefaultElevationIntervalKm when zero)
+func NewElevationGenerator(next CourseGenerator, source elevation.Source, intervalKm float64) *ElevationGenerator {
+	if intervalKm <= 0 {
+		intervalKm = DefaultElevationIntervalKm
+	}
+	return &ElevationGenerator{next: next, source: source, intervalKm: intervalKm}
+}
+
+// GenerateCourseSuggestions is passed through unchanged
+func (g *ElevationGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	return g.next.GenerateCourseSuggestions(ctx, request)
+}
+
+// GenerateCourseDetails generates the details and adds their elevation profile
+func (g *ElevationGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	result, err := g.next.GenerateCourseDetails(ctx, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.addElevation(&result.Course); err != nil {
+		log.Printf("Elevation of course %s unavailable: %v", result.Course.ID, err)
+	}
+	return result, nil
+}
+
+// AnnotateTrack annotates the track and adds its elevation profile
+func (g *ElevationGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	result, err := annotateTrack(ctx, g.next, track)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.addElevation(&result.Course); err != nil {
+		log.Printf("Elevation of course %s unavailable: %v", result.Course.ID, err)
+	}
+	return result, nil
+}
+
+// StreamCourseSuggestions is passed through unchanged
+func (g *ElevationGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	return streamSuggestions(ctx, g.next, request, emit)
+}
+
+func (g *ElevationGenerator) addElevation(course *CourseDetails) error {
+	geometry, err := courseGeometry(course)
+	if err != nil {
+		return err
+	}
+
+	profile, err := elevation.BuildProfile(g.source, geometry, g.intervalKm)
+	if err != nil {
+		return err
+	}
+
+	samples := make([]ElevationProfile, len(profile.Samples))
+	for i, sample := range profile.Samples {
+		samples[i] = ElevationProfile{
+			Distance:  math.Round(sample.DistanceKm*100) / 100,
+			Elevation: math.Round(sample.ElevationM*10) / 10,
+		}
+	}
+	course.Elevation = &Elevation{Gain: math.Round(profile.GainM), Profile: samples}
+	return nil
+}
+
+// courseGeometry returns the routed line of a course, or the line through
+// its waypoints when it has not been routed
+func courseGeometry(course *CourseDetails) ([]shared.Position, error) {
+	if course.Polyline != nil {
+		geometry, err := polyline.Decode(*course.Polyline, polyline.DefaultPrecision)
+		if err != nil {
+			return nil, fmt.Errorf("invalid course polyline: %w", err)
+		}
+		return geometry, nil
+	}
+
+	geometry := make([]shared.Position, len(course.Waypoints))
+	for i, waypoint := range course.Waypoints {
+		geometry[i] = shared.Position{Latitude: waypoint.Position.Latitude, Longitude: waypoint.Position.Longitude}
+	}
+	return geometry, nil
+}
+
+var (
+	_ CourseGenerator    = (*ElevationGenerator)(nil)
+	_ SuggestionStreamer = (*ElevationGenerator)(nil)
+	_ TrackAnnotator     = (*ElevationGenerator)(nil)
+)
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/elevation"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// slopeSource rises 1m per 0.0001 degrees north of latitude 35
+type slopeSource struct{}
+
+func (slopeSource) ElevationAt(lat, lng float64) (float64, error) {
+	if lat < 35 {
+		return 0, elevation.ErrNoData
+	}
+	return (lat - 35) * 10000, nil
+}
+
+// routedGenerator returns fake details with a fixed polyline
+type routedGenerator struct {
+	FakeGenerator
+	polyline string
+}
+
+func (g *routedGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	result, err := g.FakeGenerator.GenerateCourseDetails(ctx, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	result.Course.Polyline = &g.polyline
+	return result, nil
+}
+
+func TestElevationGenerator_Polyline(t *testing.T) {
+	// 1.112km due north climbing 100m
+	geometry, err := polyline.Encode([]shared.Position{
+		{Latitude: 35.00, Longitude: 139.0},
+		{Latitude: 35.01, Longitude: 139.0},
+	}, polyline.Precision6)
+	require.NoError(t, err)
+
+	generator := NewElevationGenerator(&routedGenerator{polyline: geometry}, slopeSource{}, 0.5)
+	result, err := generator.GenerateCourseDetails(context.Background(), CourseSuggestion{ID: "course-1", Title: "坂道", CourseType: "walking",
+		StartPoint: Position{Latitude: 35.0, Longitude: 139.0}})
+	require.NoError(t, err)
+
+	require.NotNil(t, result.Course.Elevation)
+	assert.Equal(t, 100.0, result.Course.Elevation.Gain)
+	assert.Equal(t, []ElevationProfile{
+		{Distance: 0, Elevation: 0},
+		{Distance: 0.5, Elevation: 45},
+		{Distance: 1, Elevation: 89.9},
+		{Distance: 1.11, Elevation: 100},
+	}, result.Course.Elevation.Profile)
+}
+
+func TestElevationGenerator_Waypoints(t *testing.T) {
+	suggestion := CourseSuggestion{ID: "course-1", Title: "皇居ラン", CourseType: "jogging",
+		StartPoint: Position{Latitude: 35.681236, Longitude: 139.767125}}
+
+	result, err := NewElevationGenerator(NewFakeGenerator(), slopeSource{}, 0).GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+	require.NotNil(t, result.Course.Elevation)
+	assert.Greater(t, len(result.Course.Elevation.Profile), len(result.Course.Waypoints))
+
+	// Outside the DEM coverage
+	suggestion.StartPoint = Position{Latitude: 34.5, Longitude: 135.5}
+	result, err = NewElevationGenerator(NewFakeGenerator(), slopeSource{}, 0).GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+	assert.Nil(t, result.Course.Elevation)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// GenerationErrorKind classifies failures of a course generator
+type GenerationErrorKind string
+
+const (
+	ErrKindRateLimited     GenerationErrorKind = "rate_limited"
+	ErrKindQuotaExceeded   GenerationErrorKind = "quota_exceeded"
+	ErrKindContextLength   GenerationErrorKind = "context_length_exceeded"
+	ErrKindRefusal         GenerationErrorKind = "refusal"
+	ErrKindInvalidAPIKey   GenerationErrorKind = "invalid_api_key"
+	ErrKindTimeout         GenerationErrorKind = "timeout"
+	ErrKindUpstream        GenerationErrorKind = "upstream_error"
+	ErrKindInvalidResponse GenerationErrorKind = "invalid_response"
+	ErrKindBudgetExceeded  GenerationErrorKind = "budget_exceeded"
+	ErrKindUnknown         GenerationErrorKind = "unknown"
+)
+
+// GenerationError is a classified course generator failure
+type GenerationError struct {
+	Kind GenerationErrorKind
+	// RetryAfter is the upstream hint for when to try again, if any
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *GenerationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *GenerationError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether retrying the same request may succeed
+func (e *GenerationError) Retryable() bool {
+	switch e.Kind {
+	case ErrKindRateLimited, ErrKindTimeout, ErrKindUpstream:
+		return true
+	default:
+		return false
+	}
+}
+
+// AsGenerationError extracts a GenerationError from err, classifying it as
+// ErrKindUnknown when it is not one
+func AsGenerationError(err error) *GenerationError {
+	var genErr *GenerationError
+	if errors.As(err, &genErr) {
+		return genErr
+	}
+	return &GenerationError{Kind: ErrKindUnknown, Err: err}
+}
+
+// classifyOpenAIError maps an error from the OpenAI client to a GenerationError
+func classifyOpenAIError(err error, retryAfter time.Duration) *GenerationError {
+	var genErr *GenerationError
+	if errors.As(err, &genErr) {
+		return genErr
+	}
+
+	kind := ErrKindUnknown
+
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		kind = classifyAPIError(apiErr)
+	case errors.As(err, &reqErr):
+		kind = classifyStatusCode(reqErr.HTTPStatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = ErrKindTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		kind = ErrKindTimeout
+	}
+
+	return &GenerationError{Kind: kind, RetryAfter: retryAfter, Err: err}
+}
+
+func classifyAPIError(apiErr *openai.APIError) GenerationErrorKind {
+	code := ""
+	if apiErr.Code != nil {
+		code = fmt.Sprint(apiErr.Code)
+	}
+
+	switch {
+	case code == "invalid_api_key" || apiErr.HTTPStatusCode == http.StatusUnauthorized:
+		return ErrKindInvalidAPIKey
+	case code == "insufficient_quota" || apiErr.Type == "insufficient_quota":
+		return ErrKindQuotaExceeded
+	case code == "context_length_exceeded" || strings.Contains(apiErr.Message, "maximum context length"):
+		return ErrKindContextLength
+	}
+	return classifyStatusCode(apiErr.HTTPStatusCode)
+}
+
+func classifyStatusCode(status int) GenerationErrorKind {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrKindRateLimited
+	case status == http.StatusUnauthorized:
+		return ErrKindInvalidAPIKey
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrKindTimeout
+	case status >= 500:
+		return ErrKindUpstream
+	default:
+		return ErrKindUnknown
+	}
+}
+
+// retryAfterKey is the context key under which a *retryAfterSink is stored
+type retryAfterKey struct{}
+
+// retryAfterSink receives the Retry-After header of the last upstream response
+type retryAfterSink struct {
+	value time.Duration
+}
+
+func withRetryAfterSink(ctx context.Context, sink *retryAfterSink) context.Context {
+	return context.WithValue(ctx, retryAfterKey{}, sink)
+}
+
+// retryAfterTransport captures Retry-After headers from upstream responses into
+// the sink carried by the request context, since the OpenAI client does not
+// expose response headers on errors
+type retryAfterTransport struct {
+	next http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if sink, ok := req.Context().Value(retryAfterKey{}).(*retryAfterSink); ok {
+		sink.value = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return resp, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
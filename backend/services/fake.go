@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"potarin-backend/geo"
+	"potarin-backend/polyline"
+)
+
+// FakeGenerator is a deterministic in-process CourseGenerator used for
+// offline development and tests. The same input always yields the same output.
+type FakeGenerator struct {
+	prefectures *geo.PrefectureIndex
+}
+
+func NewFakeGenerator() *FakeGenerator {
+	return &FakeGenerator{prefectures: geo.JapanPrefectures()}
+}
+
+// WithPrefectures replaces the embedded prefecture boundaries used to name
+// the fake courses
+func (g *FakeGenerator) WithPrefectures(index *geo.PrefectureIndex) *FakeGenerator {
+	g.prefectures = index
+	return g
+}
+
+// defaultStartPoint is used when a request carries no location (Tokyo Station)
+var defaultStartPoint = Position{Latitude: 35.681236, Longitude: 139.767125}
+
+// fakeBaseDistance is the distance of the middle suggestion, within limits
+func fakeBaseDistance(limits DistanceRange) float64 {
+	switch {
+	case limits.MinKm > 0 && limits.MaxKm > 0:
+		return (limits.MinKm + limits.MaxKm) / 2
+	case limits.MaxKm > 0:
+		return limits.MaxKm * 0.7
+	case limits.MinKm > 0:
+		return limits.MinKm * 1.25
+	}
+	return 5
+}
+
+// fakeMinutesPerKm is the pace used to derive estimated times
+var fakeMinutesPerKm = map[string]float64{
+	"walking": 12,
+	"jogging": 6,
+	"cycling": 3,
+}
+
+var fakeCourseTypeText = map[string]string{
+	"walking": "散歩",
+	"jogging": "ジョギング",
+	"cycling": "サイクリング",
+}
+
+// GenerateCourseSuggestions returns three fixed suggestions derived from the request
+func (g *FakeGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := defaultStartPoint
+	if request.Location != nil {
+		start = *request.Location
+	}
+
+	difficulty := "easy"
+	if request.Preferences != nil && request.Preferences.Difficulty != nil {
+		difficulty = *request.Preferences.Difficulty
+	}
+
+	routeShape := request.RouteShape
+	if routeShape == "" {
+		routeShape = RouteShapeLoop
+	}
+
+	limits, _ := request.DistanceLimits()
+	baseDistance := fakeBaseDistance(limits)
+
+	locationInfo := getLocationInfo(g.prefectures, nil, start.Latitude, start.Longitude)
+	typeText := fakeCourseTypeText[request.CourseType]
+	seed := fakeSeed(request.CourseType, request.Distance, start.Latitude, start.Longitude, difficulty)
+
+	labels := []string{"A", "B", "C"}
+	factors := []float64{0.8, 1.0, 1.2}
+	suggestions := make([]CourseSuggestion, len(labels))
+	for i, label := range labels {
+		distance := math.Round(limits.Clamp(baseDistance*factors[i])*10) / 10
+		// Spread the start points a few hundred meters around the requested location
+		angle := float64(i) * 2 * math.Pi / float64(len(labels))
+		startPoint := offsetPosition(start, 0.3*math.Cos(angle), 0.3*math.Sin(angle))
+		endPoint := startPoint
+		if routeShape == RouteShapeOneWay {
+			endPoint = offsetPosition(startPoint, distance/2, distance/2)
+			if request.EndLocation != nil {
+				endPoint = *request.EndLocation
+			}
+		}
+		suggestions[i] = CourseSuggestion{
+			ID:            fmt.Sprintf("fake-%08x-%d", seed, i+1),
+			Title:         fmt.Sprintf("%s%sコース%s", locationInfo.Area, typeText, label),
+			Description:   fmt.Sprintf("%sを巡る%.1fkmの%sコースです。", locationInfo.Description, distance, typeText),
+			Distance:      distance,
+			EstimatedTime: fakeEstimatedTime(request.CourseType, distance),
+			Difficulty:    difficulty,
+			CourseType:    request.CourseType,
+			StartPoint:    startPoint,
+			Highlights:    []string{"公園", "川沿いの道", "展望スポット"},
+			Summary:       fmt.Sprintf("%s周辺の%sコース%s", locationInfo.Area, typeText, label),
+			RouteShape:    routeShape,
+			EndPoint:      &endPoint,
+		}
+	}
+
+	return &CourseSuggestionsResponse{Suggestions: suggestions}, nil
+}
+
+// GenerateCourseDetails returns a square loop through four waypoints around
+// the start point, a straight line to the end point of one way courses or a
+// turn to the north and back for out and back courses
+func (g *FakeGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	distance := suggestion.Distance
+	if distance <= 0 {
+		distance = fakeBaseDistance(DistanceRange{})
+	}
+	side := distance / 4
+	start := suggestion.StartPoint
+
+	waypoints := []Waypoint{
+		{
+			ID:          "wp-1",
+			Title:       "スタート地点",
+			Description: "コースの出発地点です。",
+			Position:    start,
+			Type:        "start",
+		},
+		{
+			ID:          "wp-2",
+			Title:       "チェックポイント1",
+			Description: "北に進んだ先のチェックポイントです。",
+			Position:    offsetPosition(start, side, 0),
+			Type:        "checkpoint",
+		},
+		{
+			ID:          "wp-3",
+			Title:       "展望スポット",
+			Description: "景色を楽しめるランドマークです。",
+			Position:    offsetPosition(start, side, side),
+			Type:        "landmark",
+		},
+		{
+			ID:          "wp-4",
+			Title:       "チェックポイント2",
+			Description: "出発地点へ戻る途中のチェックポイントです。",
+			Position:    offsetPosition(start, 0, side),
+			Type:        "checkpoint",
+		},
+		{
+			ID:          "wp-5",
+			Title:       "ゴール地点",
+			Description: "出発地点に戻ってきました。",
+			Position:    start,
+			Type:        "end",
+		},
+	}
+	switch suggestion.RouteShape {
+	case RouteShapeOneWay:
+		end := offsetPosition(start, distance/2, distance/2)
+		if suggestion.EndPoint != nil {
+			end = *suggestion.EndPoint
+		}
+		waypoints = fakeOneWayWaypoints(waypoints, start, end)
+	case RouteShapeOutAndBack:
+		waypoints[2].Description = "折り返し地点のランドマークです。"
+		waypoints[2].Position = offsetPosition(start, 2*side, 0)
+		waypoints[3].Position = waypoints[1].Position
+	}
+
+	return &CourseDetailsResponse{
+		Course: CourseDetails{
+			ID:            suggestion.ID,
+			Title:         suggestion.Title,
+			Description:   suggestion.Description,
+			Distance:      distance,
+			EstimatedTime: fakeEstimatedTime(suggestion.CourseType, distance),
+			Difficulty:    suggestion.Difficulty,
+			CourseType:    suggestion.CourseType,
+			Waypoints:     waypoints,
+			RouteShape:    suggestion.RouteShape,
+		},
+	}, nil
+}
+
+// AnnotateTrack places a start, two checkpoints at a third and two thirds of
+// the track, a landmark halfway and an end along the track
+func (g *FakeGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	locationInfo := getLocationInfo(g.prefectures, nil, track.Geometry[0].Latitude, track.Geometry[0].Longitude)
+	typeText := fakeCourseTypeText[track.CourseType]
+	title := track.Name
+	if title == "" {
+		title = fmt.Sprintf("%s%sコース", locationInfo.Area, typeText)
+	}
+
+	along := func(fraction float64) Position {
+		point, _ := polyline.PointAt(track.Geometry, track.DistanceKm*fraction)
+		return Position{Latitude: math.Round(point.Latitude*1e6) / 1e6, Longitude: math.Round(point.Longitude*1e6) / 1e6}
+	}
+	waypoints := []Waypoint{
+		{ID: "wp-1", Title: "スタート地点", Description: "コースの出発地点です。", Position: along(0), Type: "start"},
+		{ID: "wp-2", Title: "チェックポイント1", Description: "コースの3分の1の地点です。", Position: along(1.0 / 3), Type: "checkpoint"},
+		{ID: "wp-3", Title: "展望スポット", Description: "景色を楽しめるランドマークです。", Position: along(0.5), Type: "landmark"},
+		{ID: "wp-4", Title: "チェックポイント2", Description: "コースの3分の2の地点です。", Position: along(2.0 / 3), Type: "checkpoint"},
+		{ID: "wp-5", Title: "ゴール地点", Description: "コースの終点です。", Position: along(1), Type: "end"},
+	}
+
+	result := &CourseDetailsResponse{
+		Course: CourseDetails{
+			Title:         title,
+			Description:   fmt.Sprintf("%sを巡る%.1fkmの%sコースです。", locationInfo.Description, track.DistanceKm, typeText),
+			EstimatedTime: fakeEstimatedTime(track.CourseType, track.DistanceKm),
+			Difficulty:    "easy",
+			Waypoints:     waypoints,
+		},
+	}
+	fitToTrack(&result.Course, track)
+	return result, nil
+}
+
+// fakeOneWayWaypoints spreads the waypoints of a loop evenly along the line
+// from start to end
+func fakeOneWayWaypoints(waypoints []Waypoint, start, end Position) []Waypoint {
+	last := len(waypoints) - 1
+	for i := range waypoints {
+		fraction := float64(i) / float64(last)
+		waypoints[i].Position = Position{
+			Latitude:  math.Round((start.Latitude+(end.Latitude-start.Latitude)*fraction)*1e6) / 1e6,
+			Longitude: math.Round((start.Longitude+(end.Longitude-start.Longitude)*fraction)*1e6) / 1e6,
+		}
+	}
+	waypoints[last].Description = "コースの終点です。"
+	return waypoints
+}
+
+func fakeSeed(parts ...interface{}) uint32 {
+	h := fnv.New32a()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%v|", part)
+	}
+	return h.Sum32()
+}
+
+func fakeEstimatedTime(courseType string, distance float64) int {
+	pace, ok := fakeMinutesPerKm[courseType]
+	if !ok {
+		pace = fakeMinutesPerKm["walking"]
+	}
+	return int(math.Round(distance * pace))
+}
+
+// offsetPosition moves a position by the given north and east offsets in kilometers
+func offsetPosition(p Position, northKm, eastKm float64) Position {
+	const kmPerDegree = 111.32
+	lat := p.Latitude + northKm/kmPerDegree
+	lng := p.Longitude + eastKm/(kmPerDegree*math.Cos(p.Latitude*math.Pi/180))
+	return Position{
+		Latitude:  math.Round(lat*1e6) / 1e6,
+		Longitude: math.Round(lng*1e6) / 1e6,
+	}
+}
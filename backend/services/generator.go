@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+	"potarin-backend/geo"
+	"potarin-backend/poi"
+	"potarin-backend/prompts"
+	"potarin-backend/usage"
+)
+
+// CourseGenerator generates course suggestions and details
+type CourseGenerator interface {
+	GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error)
+	GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error)
+}
+
+// Supported course generator providers
+const (
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local"
+	ProviderFake   = "fake"
+)
+
+// GeneratorConfig selects and configures a CourseGenerator implementation
+type GeneratorConfig struct {
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
+
+	// Optional record/replay of chat completion calls (see CassetteTransport)
+	CassetteMode string
+	CassetteDir  string
+
+	// RetryPolicy for transient failures; DefaultRetryPolicy when zero
+	RetryPolicy RetryPolicy
+
+	// Prompts overrides the embedded prompt templates when set
+	Prompts *prompts.Store
+
+	// Usage records token usage and cost of every completion when set
+	Usage *usage.Tracker
+
+	// Prefectures overrides the embedded prefecture boundaries when set
+	Prefectures *geo.PrefectureIndex
+
+	// Localities names the city and neighbourhood of locations in prompts
+	Localities geo.Localities
+
+	// POIs lists up to POILimit real places within POIRadiusKm of locations
+	// in prompts when set
+	POIs        *poi.Store
+	POIRadiusKm float64
+	POILimit    int
+}
+
+// NewCourseGenerator creates the CourseGenerator for the configured provider
+func NewCourseGenerator(config GeneratorConfig) (CourseGenerator, error) {
+	switch config.Provider {
+	case ProviderOpenAI, "":
+		if config.APIKey == "" && config.CassetteMode != CassetteModeReplay {
+			return nil, fmt.Errorf("API key is required for provider %q", ProviderOpenAI)
+		}
+		model := config.Model
+		if model == "" {
+			model = openai.GPT4o
+		}
+		return newOpenAIGenerator(config, model)
+	case ProviderLocal:
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("base URL is required for provider %q", ProviderLocal)
+		}
+		if config.Model == "" {
+			return nil, fmt.Errorf("model is required for provider %q", ProviderLocal)
+		}
+		return newOpenAIGenerator(config, config.Model)
+	case ProviderFake:
+		generator := NewFakeGenerator()
+		if config.Prefectures != nil {
+			generator.WithPrefectures(config.Prefectures)
+		}
+		return generator, nil
+	default:
+		return nil, fmt.Errorf("unknown course generator provider: %q", config.Provider)
+	}
+}
+
+func newOpenAIGenerator(config GeneratorConfig, model string) (*OpenAIService, error) {
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = config.BaseURL
+	}
+
+	if config.CassetteMode != "" && config.CassetteMode != CassetteModeOff {
+		transport, err := NewCassetteTransport(config.CassetteMode, config.CassetteDir, nil)
+		if err != nil {
+			return nil, err
+		}
+		clientConfig.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	service := NewOpenAIServiceWithConfig(clientConfig, model)
+	if config.RetryPolicy.MaxAttempts > 0 {
+		service.WithRetryPolicy(config.RetryPolicy)
+	}
+	if config.Prompts != nil {
+		service.WithPrompts(config.Prompts)
+	}
+	if config.Usage != nil {
+		service.WithUsageTracker(config.Usage)
+	}
+	if config.Prefectures != nil {
+		service.WithPrefectures(config.Prefectures)
+	}
+	if len(config.Localities) > 0 {
+		service.WithLocalities(config.Localities)
+	}
+	if config.POIs != nil {
+		service.WithPOIs(config.POIs, config.POIRadiusKm, config.POILimit)
+	}
+	return service, nil
+}
+
+var (
+	_ CourseGenerator = (*OpenAIService)(nil)
+	_ CourseGenerator = (*FakeGenerator)(nil)
+	_ TrackAnnotator  = (*OpenAIService)(nil)
+	_ TrackAnnotator  = (*FakeGenerator)(nil)
+)
@@ -2,91 +2,304 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
+	"sort"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/jsonschema"
+	"potarin-backend/geo"
+	"potarin-backend/poi"
+	"potarin-backend/polyline"
+	"potarin-backend/prompts"
+	"potarin-backend/usage"
+	shared "potarin-shared"
 )
 
 type OpenAIService struct {
-	client *openai.Client
+	client      *openai.Client
+	model       string
+	retryPolicy RetryPolicy
+	prompts     *prompts.Store
+	usage       *usage.Tracker
+	prefectures *geo.PrefectureIndex
+	localities  geo.Localities
+	pois        *poi.Store
+	poiRadiusKm float64
+	poiLimit    int
 }
 
 func NewOpenAIService(apiKey string) *OpenAIService {
+	return NewOpenAIServiceWithConfig(openai.DefaultConfig(apiKey), openai.GPT4o)
+}
+
+// NewOpenAICompatibleService creates a service talking to an OpenAI-compatible
+// endpoint such as an Ollama or llama.cpp server
+func NewOpenAICompatibleService(baseURL, apiKey, model string) *OpenAIService {
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = baseURL
+
+	return NewOpenAIServiceWithConfig(clientConfig, model)
+}
+
+// NewOpenAIServiceWithConfig creates a service from a full client configuration,
+// e.g. to use a custom HTTP client
+func NewOpenAIServiceWithConfig(clientConfig openai.ClientConfig, model string) *OpenAIService {
+	// Capture Retry-After headers, which the client drops on errors
+	if httpClient, ok := clientConfig.HTTPClient.(*http.Client); ok {
+		wrapped := *httpClient
+		next := wrapped.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		wrapped.Transport = &retryAfterTransport{next: next}
+		clientConfig.HTTPClient = &wrapped
+	}
+
 	return &OpenAIService{
-		client: openai.NewClient(apiKey),
+		client:      openai.NewClientWithConfig(clientConfig),
+		model:       model,
+		retryPolicy: DefaultRetryPolicy,
+		prompts:     prompts.Default(),
+		prefectures: geo.JapanPrefectures(),
+	}
+}
+
+// WithRetryPolicy overrides the retry policy for failed chat completions
+func (s *OpenAIService) WithRetryPolicy(policy RetryPolicy) *OpenAIService {
+	s.retryPolicy = policy
+	return s
+}
+
+// WithPrompts replaces the embedded prompt templates
+func (s *OpenAIService) WithPrompts(store *prompts.Store) *OpenAIService {
+	s.prompts = store
+	return s
+}
+
+// WithUsageTracker records the token usage of every completion in tracker
+func (s *OpenAIService) WithUsageTracker(tracker *usage.Tracker) *OpenAIService {
+	s.usage = tracker
+	return s
+}
+
+// WithPrefectures replaces the embedded prefecture boundaries used to
+// describe the area of a location in prompts
+func (s *OpenAIService) WithPrefectures(index *geo.PrefectureIndex) *OpenAIService {
+	s.prefectures = index
+	return s
+}
+
+// WithLocalities adds municipal and neighbourhood boundaries so that prompts
+// name the city and neighbourhood of a location, not only its prefecture
+func (s *OpenAIService) WithLocalities(localities geo.Localities) *OpenAIService {
+	s.localities = localities
+	return s
+}
+
+// WithPOIs lists up to limit real places within radiusKm of the location in
+// prompts, so that the model picks existing landmarks and their coordinates
+func (s *OpenAIService) WithPOIs(store *poi.Store, radiusKm float64, limit int) *OpenAIService {
+	s.pois = store
+	s.poiRadiusKm = radiusKm
+	s.poiLimit = limit
+	return s
+}
+
+// landmarks returns the stored POIs near a coordinate for the prompts
+func (s *OpenAIService) landmarks(lat, lng float64) []prompts.Landmark {
+	nearby := s.pois.Nearby(lat, lng, s.poiRadiusKm, s.poiLimit)
+	if len(nearby) == 0 {
+		return nil
+	}
+	landmarks := make([]prompts.Landmark, len(nearby))
+	for i, place := range nearby {
+		landmarks[i] = prompts.Landmark{
+			Name:       place.Name,
+			Reading:    place.Reading,
+			Category:   place.Category,
+			Latitude:   place.Latitude,
+			Longitude:  place.Longitude,
+			DistanceKm: place.DistanceKm,
+		}
+	}
+	return landmarks
+}
+
+// recordUsage adds the tokens of a completion to the usage tracker, if any
+func (s *OpenAIService) recordUsage(ctx context.Context, tokens openai.Usage) {
+	if s.usage != nil {
+		s.usage.Record(ctx, s.model, tokens.PromptTokens, tokens.CompletionTokens)
 	}
 }
 
 // LocationInfo represents area information based on coordinates
 type LocationInfo struct {
-	Area        string // e.g., "東京", "神奈川", "大阪"
-	Prefecture  string // e.g., "東京都", "神奈川県", "大阪府"
-	Description string // e.g., "東京都内", "神奈川県内", "大阪府内"
-	AreaType    string // e.g., "都内", "県内", "府内"
+	Area         string // e.g., "東京", "神奈川", "大阪"
+	Prefecture   string // e.g., "東京都", "神奈川県", "大阪府"
+	Description  string // e.g., "東京都内", "神奈川県内", "大阪府内"
+	AreaType     string // e.g., "都内", "県内", "府内"
+	Region       string // e.g., "関東", "近畿"; empty outside Japan
+	OutsideJapan bool
+
+	// Resolved only from the loaded locality boundaries, empty without them
+	City          string // e.g., "武蔵野市", "八王子市", "横浜市"
+	Ward          string // e.g., "港北区" in a designated city
+	Neighbourhood string // e.g., "吉祥寺本町一丁目"
+}
+
+// Locality names the city, ward and neighbourhood, e.g. "武蔵野市吉祥寺本町一丁目",
+// or returns "" when they are unknown
+func (l LocationInfo) Locality() string {
+	return geo.Locality{City: l.City, Ward: l.Ward, Neighbourhood: l.Neighbourhood}.Name()
 }
 
-// getLocationInfo determines the area based on coordinates
-func getLocationInfo(lat, lng float64) LocationInfo {
-	// Tokyo (東京都)
-	if lat >= 35.5 && lat <= 35.9 && lng >= 139.3 && lng <= 139.9 {
-		return LocationInfo{
-			Area:        "東京",
-			Prefecture:  "東京都",
-			Description: "東京都内または近郊",
-			AreaType:    "都内",
+// outsideJapanLocation is used for coordinates that are in no prefecture
+var outsideJapanLocation = LocationInfo{
+	Area:         "指定地点",
+	Prefecture:   "指定地点周辺",
+	Description:  "日本国外の指定された地域",
+	AreaType:     "国外",
+	OutsideJapan: true,
+}
+
+// getLocationInfo determines the prefecture containing the coordinates, using
+// the embedded boundaries when index is nil, and the city and neighbourhood
+// when localities cover the coordinates
+func getLocationInfo(index *geo.PrefectureIndex, localities geo.Localities, lat, lng float64) LocationInfo {
+	if index == nil {
+		index = geo.JapanPrefectures()
+	}
+	locality, hasLocality := localities.Locate(lat, lng)
+	prefecture, ok := index.Locate(lat, lng)
+	if hasLocality {
+		// Municipal boundaries are more precise than the prefecture index
+		if named, found := geo.PrefectureByName(locality.Prefecture); found {
+			prefecture, ok = named, true
 		}
 	}
+	if !ok {
+		return outsideJapanLocation
+	}
+	return LocationInfo{
+		Area:          prefecture.Area,
+		Prefecture:    prefecture.Name,
+		Description:   prefecture.Name + "内",
+		AreaType:      prefecture.AreaType(),
+		Region:        prefecture.Region,
+		City:          locality.City,
+		Ward:          locality.Ward,
+		Neighbourhood: locality.Neighbourhood,
+	}
+}
 
-	// Kanagawa (神奈川県) - including Fujisawa/Shonan area
-	if lat >= 35.1 && lat <= 35.7 && lng >= 139.0 && lng <= 139.8 {
-		return LocationInfo{
-			Area:        "神奈川",
-			Prefecture:  "神奈川県",
-			Description: "神奈川県内",
-			AreaType:    "県内",
-		}
+// CourseSuggestionPrompt generates a prompt for course suggestions
+func (s *OpenAIService) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	promptSet := s.prompts.Current()
+	chatRequest, err := s.buildSuggestionsChatRequest(promptSet, request)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.createChatCompletion(ctx, chatRequest)
+	if err != nil {
+		return nil, err
 	}
 
-	// Osaka (大阪府)
-	if lat >= 34.3 && lat <= 34.8 && lng >= 135.2 && lng <= 135.8 {
-		return LocationInfo{
-			Area:        "大阪",
-			Prefecture:  "大阪府",
-			Description: "大阪府内",
-			AreaType:    "府内",
+	var result CourseSuggestionsResponse
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		log.Printf("Failed to parse OpenAI response: %s", content)
+		return nil, &GenerationError{Kind: ErrKindInvalidResponse, Err: fmt.Errorf("failed to parse OpenAI response: %w", err)}
+	}
+	conformed := make([]CourseSuggestion, 0, len(result.Suggestions))
+	for _, suggestion := range result.Suggestions {
+		if warning := conformSuggestion(request, &suggestion); warning != nil {
+			result.Warnings = append(result.Warnings, *warning)
+			continue
 		}
+		suggestion.ID = suggestionID(suggestion)
+		conformed = append(conformed, suggestion)
 	}
+	result.Suggestions = conformed
+	result.Metadata = &GenerationMetadata{PromptVersion: promptSet.Version}
+
+	return &result, nil
+}
 
-	// Kyoto (京都府)
-	if lat >= 34.8 && lat <= 35.8 && lng >= 135.4 && lng <= 136.0 {
-		return LocationInfo{
-			Area:        "京都",
-			Prefecture:  "京都府",
-			Description: "京都府内",
-			AreaType:    "府内",
+// suggestionID replaces the ID chosen by the model, which restarts at
+// "course-1" in every response, with one derived from the suggestion so that
+// courses stored by ID do not overwrite each other
+func suggestionID(suggestion CourseSuggestion) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%.5f,%.5f\x00%g",
+		suggestion.Title, suggestion.CourseType,
+		suggestion.StartPoint.Latitude, suggestion.StartPoint.Longitude, suggestion.Distance))
+	return "course-" + hex.EncodeToString(sum[:6])
+}
+
+// WarnEndPointTooFar reports a one way suggestion that does not end at the
+// requested end location
+const WarnEndPointTooFar = "end_point_too_far"
+
+// maxEndPointGapKm is how far a suggested end point may be from the requested
+// end location before the suggestion is dropped instead of moved
+const maxEndPointGapKm = 1.0
+
+// conformSuggestion applies the requested route shape and end location to a
+// suggestion, which the model only reads from the prompt. It returns a
+// warning when the suggestion ends too far from the requested end location.
+func conformSuggestion(request CourseRequest, suggestion *CourseSuggestion) *ValidationWarning {
+	if request.RouteShape != "" {
+		suggestion.RouteShape = request.RouteShape
+	}
+	if suggestion.RouteShape != RouteShapeOneWay {
+		if suggestion.RouteShape != "" {
+			start := suggestion.StartPoint
+			suggestion.EndPoint = &start
 		}
+		return nil
+	}
+	if request.EndLocation == nil {
+		return nil
 	}
 
-	// Default to general Japan area
-	return LocationInfo{
-		Area:        "日本",
-		Prefecture:  "日本",
-		Description: "指定された地域",
-		AreaType:    "地域内",
+	if suggestion.EndPoint != nil {
+		end, requested := *suggestion.EndPoint, *request.EndLocation
+		if gapKm := geo.HaversineKm(end.Latitude, end.Longitude, requested.Latitude, requested.Longitude); gapKm > maxEndPointGapKm {
+			return &ValidationWarning{
+				ID:      suggestion.ID,
+				Code:    WarnEndPointTooFar,
+				Message: fmt.Sprintf("コース「%s」のゴール地点が指定された地点から%.1fkm離れています", suggestion.Title, gapKm),
+			}
+		}
 	}
+	end := *request.EndLocation
+	suggestion.EndPoint = &end
+	return nil
 }
 
-// CourseSuggestionPrompt generates a prompt for course suggestions
-func (s *OpenAIService) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
-	prompt := s.buildSuggestionPrompt(request)
-	systemPrompt := s.buildSystemPrompt(request)
+// buildSuggestionsChatRequest creates the chat completion request for course suggestions
+func (s *OpenAIService) buildSuggestionsChatRequest(promptSet *prompts.Set, request CourseRequest) (openai.ChatCompletionRequest, error) {
+	data := suggestionsPromptData(s.prefectures, s.localities, request)
+	if request.Location != nil {
+		data.Landmarks = s.landmarks(request.Location.Latitude, request.Location.Longitude)
+	}
+	systemPrompt, err := promptSet.Render(prompts.SuggestionsSystem, data)
+	if err != nil {
+		return openai.ChatCompletionRequest{}, err
+	}
+	prompt, err := promptSet.Render(prompts.SuggestionsUser, data)
+	if err != nil {
+		return openai.ChatCompletionRequest{}, err
+	}
 	schema := s.getCourseSuggestionsSchema()
 
-	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: openai.GPT4o,
+	return openai.ChatCompletionRequest{
+		Model: s.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -107,33 +320,108 @@ func (s *OpenAIService) GenerateCourseSuggestions(ctx context.Context, request C
 		},
 		Temperature: 0.7,
 		MaxTokens:   2000,
-	})
+	}, nil
+}
 
+// GenerateCourseDetails generates detailed course information with waypoints
+func (s *OpenAIService) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	promptSet := s.prompts.Current()
+	data := detailsPromptData(s.prefectures, s.localities, suggestion)
+	data.Landmarks = s.landmarks(suggestion.StartPoint.Latitude, suggestion.StartPoint.Longitude)
+	systemPrompt, err := promptSet.Render(prompts.DetailsSystem, data)
 	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
+		return nil, err
+	}
+	prompt, err := promptSet.Render(prompts.DetailsUser, data)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+	content, err := s.createChatCompletion(ctx, s.detailsChatRequest(systemPrompt, prompt))
+	if err != nil {
+		return nil, err
 	}
 
-	var result CourseSuggestionsResponse
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
-		log.Printf("Failed to parse OpenAI response: %s", resp.Choices[0].Message.Content)
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	result, err := parseCourseDetails(content)
+	if err != nil {
+		return nil, err
 	}
 
-	return &result, nil
+	result.Course.ID = suggestion.ID
+	result.Course.RouteShape = suggestion.RouteShape
+	result.Metadata = &GenerationMetadata{PromptVersion: promptSet.Version}
+
+	return result, nil
 }
 
-// GenerateCourseDetails generates detailed course information with waypoints
-func (s *OpenAIService) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
-	prompt := s.buildDetailsPrompt(suggestion)
-	systemPrompt := s.buildDetailsSystemPrompt(suggestion)
+// AnnotateTrack describes an uploaded track with waypoints along it
+func (s *OpenAIService) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	promptSet := s.prompts.Current()
+	data := trackPromptData(s.prefectures, s.localities, track)
+	data.Landmarks = s.trackLandmarks(track)
+	systemPrompt, err := promptSet.Render(prompts.TrackSystem, data)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := promptSet.Render(prompts.TrackUser, data)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.createChatCompletion(ctx, s.detailsChatRequest(systemPrompt, prompt))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseCourseDetails(content)
+	if err != nil {
+		return nil, err
+	}
+
+	fitToTrack(&result.Course, track)
+	result.Metadata = &GenerationMetadata{PromptVersion: promptSet.Version}
+
+	return result, nil
+}
+
+// trackLandmarks returns the stored POIs within trackCorridorKm of a track,
+// nearest to it first, for the prompts
+func (s *OpenAIService) trackLandmarks(track TrackRequest) []prompts.Landmark {
+	bounds := track.Bounds
+	centerLat := (bounds.MinLatitude + bounds.MaxLatitude) / 2
+	centerLng := (bounds.MinLongitude + bounds.MaxLongitude) / 2
+	radiusKm := geo.HaversineKm(centerLat, centerLng, bounds.MaxLatitude, bounds.MaxLongitude) + trackCorridorKm
+
+	var landmarks []prompts.Landmark
+	for _, place := range s.pois.Nearby(centerLat, centerLng, radiusKm, 0) {
+		_, distanceKm, _, _ := polyline.Nearest(track.Geometry, shared.Position{Latitude: place.Latitude, Longitude: place.Longitude})
+		if distanceKm > trackCorridorKm {
+			continue
+		}
+		landmarks = append(landmarks, prompts.Landmark{
+			Name:       place.Name,
+			Reading:    place.Reading,
+			Category:   place.Category,
+			Latitude:   place.Latitude,
+			Longitude:  place.Longitude,
+			DistanceKm: distanceKm,
+		})
+	}
+	sort.SliceStable(landmarks, func(i, j int) bool {
+		return landmarks[i].DistanceKm < landmarks[j].DistanceKm
+	})
+	if s.poiLimit > 0 && len(landmarks) > s.poiLimit {
+		landmarks = landmarks[:s.poiLimit]
+	}
+	return landmarks
+}
+
+// detailsChatRequest creates the chat completion request for course details
+func (s *OpenAIService) detailsChatRequest(systemPrompt, prompt string) openai.ChatCompletionRequest {
 	schema := s.getCourseDetailsSchema()
 
-	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: openai.GPT4o,
+	return openai.ChatCompletionRequest{
+		Model: s.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -154,149 +442,147 @@ func (s *OpenAIService) GenerateCourseDetails(ctx context.Context, suggestion Co
 		},
 		Temperature: 0.5,
 		MaxTokens:   3000,
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
 	}
+}
 
+// parseCourseDetails decodes the content of a course details completion
+func parseCourseDetails(content string) (*CourseDetailsResponse, error) {
 	var result CourseDetailsResponse
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
-		log.Printf("Failed to parse OpenAI response: %s", resp.Choices[0].Message.Content)
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		log.Printf("Failed to parse OpenAI response: %s", content)
+		return nil, &GenerationError{Kind: ErrKindInvalidResponse, Err: fmt.Errorf("failed to parse OpenAI response: %w", err)}
 	}
-
 	return &result, nil
 }
 
-// buildSystemPrompt creates a dynamic system prompt based on user location
-func (s *OpenAIService) buildSystemPrompt(request CourseRequest) string {
-	var locationInfo LocationInfo
-	if request.Location != nil {
-		locationInfo = getLocationInfo(request.Location.Latitude, request.Location.Longitude)
-	} else {
-		// Default to Tokyo if no location provided
-		locationInfo = LocationInfo{
-			Area:        "東京",
-			Prefecture:  "東京都",
-			Description: "東京都内または近郊",
-			AreaType:    "都内",
+// createChatCompletion calls the chat completion API, retrying transient
+// failures, and returns the content of the first choice
+func (s *OpenAIService) createChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (string, error) {
+	return withRetry(ctx, s.retryPolicy, func(ctx context.Context) (string, error) {
+		sink := &retryAfterSink{}
+		resp, err := s.client.CreateChatCompletion(withRetryAfterSink(ctx, sink), request)
+		if err != nil {
+			return "", classifyOpenAIError(fmt.Errorf("OpenAI API error: %w", err), sink.value)
 		}
-	}
+		// Tokens are billed even if the content turns out to be unusable
+		s.recordUsage(ctx, resp.Usage)
 
-	return fmt.Sprintf("あなたは%sエリアの地域ガイド専門家です。ユーザーの希望に基づいて最適な散歩、サイクリング、ジョギングコースを提案してください。回答は必ず日本語で行い、提供されたJSONスキーマに厳密に従ってください。すべてのテキストフィールド（title, description, highlights, summary等）は日本語で記述してください。", locationInfo.Area)
-}
+		if len(resp.Choices) == 0 {
+			return "", &GenerationError{Kind: ErrKindInvalidResponse, Err: fmt.Errorf("no response from OpenAI")}
+		}
 
-// buildDetailsSystemPrompt creates a dynamic system prompt for course details
-func (s *OpenAIService) buildDetailsSystemPrompt(suggestion CourseSuggestion) string {
-	// Determine area from start point coordinates
-	locationInfo := getLocationInfo(suggestion.StartPoint.Latitude, suggestion.StartPoint.Longitude)
+		choice := resp.Choices[0]
+		if choice.Message.Refusal != "" || choice.FinishReason == openai.FinishReasonContentFilter {
+			return "", &GenerationError{Kind: ErrKindRefusal, Err: fmt.Errorf("OpenAI refused the request: %s", choice.Message.Refusal)}
+		}
 
-	return fmt.Sprintf("あなたは%sエリアのルート設計専門家です。具体的なウェイポイントとランドマークを含む詳細なコース情報を作成してください。回答は必ず日本語で行い、提供されたJSONスキーマに厳密に従ってください。すべてのテキストフィールド（title, description等）は日本語で記述してください。", locationInfo.Area)
+		return choice.Message.Content, nil
+	})
 }
 
-func (s *OpenAIService) buildSuggestionPrompt(request CourseRequest) string {
-	distanceText := map[string]string{
-		"short":  "1-3km",
-		"medium": "3-10km",
-		"long":   "10km以上",
+// suggestionsPromptData collects the values used by the suggestions prompts
+func suggestionsPromptData(index *geo.PrefectureIndex, localities geo.Localities, request CourseRequest) prompts.SuggestionsData {
+	// Default to Tokyo if no location provided
+	locationInfo := LocationInfo{
+		Area:        "東京",
+		Prefecture:  "東京都",
+		Description: "東京都内または近郊",
+		AreaType:    "都内",
+		Region:      "関東",
 	}
-
-	var locationInfo LocationInfo
 	if request.Location != nil {
-		locationInfo = getLocationInfo(request.Location.Latitude, request.Location.Longitude)
-	} else {
-		// Default to Tokyo if no location provided
-		locationInfo = LocationInfo{
-			Area:        "東京",
-			Prefecture:  "東京都",
-			Description: "東京都内または近郊",
-			AreaType:    "都内",
-		}
+		locationInfo = getLocationInfo(index, localities, request.Location.Latitude, request.Location.Longitude)
 	}
 
-	prompt := fmt.Sprintf(`%s周辺で%sの%sコースを3つ提案してください。
-
-要求詳細:
-- コースタイプ: %s
-- 希望距離: %s
-- 場所: %s`,
-		locationInfo.Area,
-		distanceText[request.Distance],
-		request.CourseType,
-		request.CourseType,
-		distanceText[request.Distance],
-		locationInfo.Description)
-
+	data := prompts.SuggestionsData{
+		Area:                locationInfo.Area,
+		Prefecture:          locationInfo.Prefecture,
+		LocationDescription: locationInfo.Description,
+		Locality:            locationInfo.Locality(),
+		CourseType:          request.CourseType,
+		Distance:            request.Distance,
+		TimeBudgetMinutes:   request.TimeBudgetMinutes,
+		RouteShape:          request.RouteShape,
+	}
+	if limits, ok := request.DistanceLimits(); ok {
+		data.DistanceMinKm = limits.MinKm
+		data.DistanceMaxKm = limits.MaxKm
+	}
 	if request.Location != nil {
-		prompt += fmt.Sprintf("\n- 現在地周辺: 緯度%f, 経度%f", request.Location.Latitude, request.Location.Longitude)
+		data.Location = &prompts.Coordinates{
+			Latitude:  request.Location.Latitude,
+			Longitude: request.Location.Longitude,
+		}
+	}
+	if request.EndLocation != nil {
+		data.EndLocation = &prompts.Coordinates{
+			Latitude:  request.EndLocation.Latitude,
+			Longitude: request.EndLocation.Longitude,
+		}
 	}
-
 	if request.Preferences != nil {
 		if request.Preferences.Scenery != nil {
-			sceneryText := map[string]string{
-				"nature": "自然豊か",
-				"urban":  "都市部",
-				"mixed":  "自然と都市の混合",
-			}
-			prompt += fmt.Sprintf("\n- 景観の希望: %s", sceneryText[*request.Preferences.Scenery])
+			data.Scenery = *request.Preferences.Scenery
 		}
 		if request.Preferences.Difficulty != nil {
-			difficultyText := map[string]string{
-				"easy":     "初心者向け（平坦）",
-				"moderate": "中級者向け（適度な起伏）",
-				"hard":     "上級者向け（坂道多め）",
-			}
-			prompt += fmt.Sprintf("\n- 難易度: %s", difficultyText[*request.Preferences.Difficulty])
-		}
-		if request.Preferences.AvoidHills != nil && *request.Preferences.AvoidHills {
-			prompt += "\n- 坂道を避ける"
+			data.Difficulty = *request.Preferences.Difficulty
 		}
+		data.AvoidHills = request.Preferences.AvoidHills != nil && *request.Preferences.AvoidHills
 	}
-
-	prompt += fmt.Sprintf(`
-
-各コースには以下を含めてください:
-- 魅力的なタイトル（日本語）
-- コースの特徴と見どころの説明（日本語）
-- 正確な距離（km）
-- 推定所要時間（分）
-- 難易度レベル
-- 出発地点の緯度経度
-- ハイライト（見どころ）リスト（日本語）
-- コースの概要（日本語）
-
-実在する%sの場所を基にして、具体的で実用的なコースを提案してください。
-**重要**: すべてのテキスト内容は必ず日本語で記述してください。英語は使用しないでください。`, locationInfo.Prefecture)
-
-	return prompt
+	return data
 }
 
-func (s *OpenAIService) buildDetailsPrompt(suggestion CourseSuggestion) string {
+// detailsPromptData collects the values used by the details prompts
+func detailsPromptData(index *geo.PrefectureIndex, localities geo.Localities, suggestion CourseSuggestion) prompts.DetailsData {
 	// Determine area from start point coordinates
-	locationInfo := getLocationInfo(suggestion.StartPoint.Latitude, suggestion.StartPoint.Longitude)
-
-	return fmt.Sprintf(`以下のコース「%s」について、詳細な情報を生成してください:
-
-%s
+	locationInfo := getLocationInfo(index, localities, suggestion.StartPoint.Latitude, suggestion.StartPoint.Longitude)
+
+	data := prompts.DetailsData{
+		Area:        locationInfo.Area,
+		Prefecture:  locationInfo.Prefecture,
+		Locality:    locationInfo.Locality(),
+		Title:       suggestion.Title,
+		Description: suggestion.Description,
+		RouteShape:  suggestion.RouteShape,
+	}
+	if suggestion.EndPoint != nil {
+		data.EndPoint = &prompts.Coordinates{
+			Latitude:  suggestion.EndPoint.Latitude,
+			Longitude: suggestion.EndPoint.Longitude,
+		}
+	}
+	return data
+}
 
-以下の詳細情報を含めてください:
-- 具体的なwaypoint（スタート地点、チェックポイント、ランドマーク、ゴール地点）
-- 各waypointの緯度経度座標
-- waypoint間の説明（日本語）
-- コース全体の詳細な説明（日本語）
-- 高低差情報（あれば）
+// trackPromptData collects the values used by the track prompts, with the
+// track sampled at trackPromptPoints evenly spaced points
+func trackPromptData(index *geo.PrefectureIndex, localities geo.Localities, track TrackRequest) prompts.TrackData {
+	start := track.Geometry[0]
+	locationInfo := getLocationInfo(index, localities, start.Latitude, start.Longitude)
+
+	interval := track.DistanceKm / (trackPromptPoints - 1)
+	samples := polyline.Resample(track.Geometry, interval)
+	points := make([]prompts.TrackPoint, len(samples))
+	for i, sample := range samples {
+		points[i] = prompts.TrackPoint{
+			Latitude:   sample.Latitude,
+			Longitude:  sample.Longitude,
+			DistanceKm: math.Min(float64(i)*interval, track.DistanceKm),
+		}
+	}
 
-実在する%sの場所を基にして、実際に歩ける/走れる/自転車で移動できるルートを設計してください。
-各waypointには分かりやすいタイトルと説明を付けてください。
-**重要**: すべてのテキスト内容（title, description等）は必ず日本語で記述してください。英語は使用しないでください。`,
-		suggestion.Title,
-		suggestion.Description,
-		locationInfo.Prefecture)
+	return prompts.TrackData{
+		Area:       locationInfo.Area,
+		Prefecture: locationInfo.Prefecture,
+		Locality:   locationInfo.Locality(),
+		Name:       track.Name,
+		CourseType: track.CourseType,
+		DistanceKm: track.DistanceKm,
+		RouteShape: track.RouteShape,
+		SouthWest:  prompts.Coordinates{Latitude: track.Bounds.MinLatitude, Longitude: track.Bounds.MinLongitude},
+		NorthEast:  prompts.Coordinates{Latitude: track.Bounds.MaxLatitude, Longitude: track.Bounds.MaxLongitude},
+		Points:     points,
+	}
 }
 
 func (s *OpenAIService) getCourseSuggestionsSchema() jsonschema.Definition {
@@ -355,8 +641,25 @@ func (s *OpenAIService) getCourseSuggestionsSchema() jsonschema.Definition {
 						"summary": {
 							Type: jsonschema.String,
 						},
+						"routeShape": {
+							Type: jsonschema.String,
+							Enum: []string{RouteShapeLoop, RouteShapeOneWay, RouteShapeOutAndBack},
+						},
+						"endPoint": {
+							Type:                 jsonschema.Object,
+							AdditionalProperties: false,
+							Properties: map[string]jsonschema.Definition{
+								"latitude": {
+									Type: jsonschema.Number,
+								},
+								"longitude": {
+									Type: jsonschema.Number,
+								},
+							},
+							Required: []string{"latitude", "longitude"},
+						},
 					},
-					Required: []string{"id", "title", "description", "distance", "estimatedTime", "difficulty", "courseType", "startPoint", "highlights", "summary"},
+					Required: []string{"id", "title", "description", "distance", "estimatedTime", "difficulty", "courseType", "startPoint", "highlights", "summary", "routeShape", "endPoint"},
 				},
 			},
 		},
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/geo"
+	"potarin-backend/poi"
+)
+
+func TestGetLocationInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		expected  LocationInfo
+	}{
+		{
+			name: "Saitama", latitude: 35.861, longitude: 139.645,
+			expected: LocationInfo{Area: "埼玉", Prefecture: "埼玉県", Description: "埼玉県内", AreaType: "県内", Region: "関東"},
+		},
+		{
+			name: "Fujisawa", latitude: 35.339, longitude: 139.490,
+			expected: LocationInfo{Area: "神奈川", Prefecture: "神奈川県", Description: "神奈川県内", AreaType: "県内", Region: "関東"},
+		},
+		{
+			name: "Sapporo", latitude: 43.062, longitude: 141.354,
+			expected: LocationInfo{Area: "北海道", Prefecture: "北海道", Description: "北海道内", AreaType: "道内", Region: "北海道"},
+		},
+		{
+			name: "Takatsuki", latitude: 34.846, longitude: 135.617,
+			expected: LocationInfo{Area: "大阪", Prefecture: "大阪府", Description: "大阪府内", AreaType: "府内", Region: "近畿"},
+		},
+		{
+			name: "Seoul", latitude: 37.5665, longitude: 126.978,
+			expected: outsideJapanLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getLocationInfo(nil, nil, tt.latitude, tt.longitude))
+		})
+	}
+}
+
+func TestSuggestionsPromptData_Location(t *testing.T) {
+	data := suggestionsPromptData(nil, nil, CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.607, Longitude: 140.106},
+	})
+	assert.Equal(t, "千葉", data.Area)
+	assert.Equal(t, "千葉県", data.Prefecture)
+	assert.Equal(t, "千葉県内", data.LocationDescription)
+
+	data = suggestionsPromptData(nil, nil, CourseRequest{CourseType: "walking", Distance: "short"})
+	assert.Equal(t, "東京都", data.Prefecture)
+}
+
+func TestPromptData_Locality(t *testing.T) {
+	square := func(lat, lng float64) []geo.Polygon {
+		return []geo.Polygon{{{{lng, lat}, {lng + 0.05, lat}, {lng + 0.05, lat + 0.05}, {lng, lat + 0.05}, {lng, lat}}}}
+	}
+	municipalities, err := geo.NewLocalityIndex([]*geo.Region{
+		{Polygons: square(35.68, 139.55), Properties: map[string]any{"N03_001": "東京都", "N03_004": "武蔵野市"}},
+		{Polygons: square(35.63, 139.30), Properties: map[string]any{"N03_001": "東京都", "N03_004": "八王子市"}},
+	}, geo.N03Fields)
+	require.NoError(t, err)
+	neighbourhoods, err := geo.NewLocalityIndex([]*geo.Region{
+		{Polygons: square(35.69, 139.56), Properties: map[string]any{"CITY_NAME": "武蔵野市", "S_NAME": "吉祥寺本町一丁目"}},
+	}, geo.EStatFields)
+	require.NoError(t, err)
+	localities := geo.Localities{municipalities, neighbourhoods}
+
+	kichijoji := suggestionsPromptData(nil, localities, CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.703, Longitude: 139.579},
+	})
+	assert.Equal(t, "東京都", kichijoji.Prefecture)
+	assert.Equal(t, "武蔵野市吉祥寺本町一丁目", kichijoji.Locality)
+
+	hachioji := suggestionsPromptData(nil, localities, CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.656, Longitude: 139.339},
+	})
+	assert.Equal(t, "東京都", hachioji.Prefecture)
+	assert.Equal(t, "八王子市", hachioji.Locality)
+
+	details := detailsPromptData(nil, localities, CourseSuggestion{
+		Title:      "吉祥寺散歩",
+		StartPoint: Position{Latitude: 35.703, Longitude: 139.579},
+	})
+	assert.Equal(t, "武蔵野市吉祥寺本町一丁目", details.Locality)
+
+	// Without locality data only the prefecture is known
+	data := suggestionsPromptData(nil, nil, CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.703, Longitude: 139.579},
+	})
+	assert.Empty(t, data.Locality)
+}
+
+func TestSuggestionsChatRequest_Landmarks(t *testing.T) {
+	store := poi.NewStore([]poi.POI{
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+		{Name: "高尾山", Category: "peak", Latitude: 35.6251, Longitude: 139.2437},
+	})
+	service := NewOpenAIService("test").WithPOIs(store, 3, 10)
+
+	chatRequest, err := service.buildSuggestionsChatRequest(service.prompts.Current(), CourseRequest{
+		CourseType: "walking",
+		Distance:   "short",
+		Location:   &Position{Latitude: 35.7031, Longitude: 139.5798},
+	})
+	require.NoError(t, err)
+	userPrompt := chatRequest.Messages[len(chatRequest.Messages)-1].Content
+	assert.Contains(t, userPrompt, "井の頭恩賜公園 [park]: 緯度35.699700, 経度139.573100")
+	assert.NotContains(t, userPrompt, "高尾山", "farther than the radius")
+
+	// Without a location there is nothing to search around
+	chatRequest, err = service.buildSuggestionsChatRequest(service.prompts.Current(), CourseRequest{CourseType: "walking", Distance: "short"})
+	require.NoError(t, err)
+	assert.NotContains(t, chatRequest.Messages[len(chatRequest.Messages)-1].Content, "周辺に実在するスポット")
+
+	details := detailsPromptData(nil, nil, CourseSuggestion{StartPoint: Position{Latitude: 35.6300, Longitude: 139.2600}})
+	assert.Empty(t, details.Landmarks, "landmarks are added by the service")
+	assert.Len(t, service.landmarks(35.6300, 139.2600), 1)
+}
+
+func TestSuggestionID(t *testing.T) {
+	suggestion := CourseSuggestion{ID: "course-1", Title: "皇居ラン", CourseType: "jogging", Distance: 5,
+		StartPoint: Position{Latitude: 35.6852, Longitude: 139.7528}}
+
+	id := suggestionID(suggestion)
+	assert.Regexp(t, `^course-[0-9a-f]{12}$`, id)
+	assert.Equal(t, id, suggestionID(suggestion), "the same course keeps its ID")
+
+	other := suggestion
+	other.Title = "隅田川ラン"
+	assert.NotEqual(t, id, suggestionID(other), "the model's course-1 of another response")
+}
+
+// routeShapeTestDocument answers a one way request ending at Tokyo Station
+// with a loop ending near it, a course ending in Asakusa and one without an
+// end point
+const routeShapeTestDocument = `{"suggestions":[` +
+	`{"id":"course-1","title":"丸の内","distance":3,"courseType":"walking","routeShape":"loop","startPoint":{"latitude":35.6852,"longitude":139.7528},"endPoint":{"latitude":35.6830,"longitude":139.7660}},` +
+	`{"id":"course-2","title":"浅草","distance":3,"courseType":"walking","routeShape":"one_way","startPoint":{"latitude":35.6852,"longitude":139.7528},"endPoint":{"latitude":35.7148,"longitude":139.7967}},` +
+	`{"id":"course-3","title":"日比谷","distance":2,"courseType":"walking","startPoint":{"latitude":35.6852,"longitude":139.7528}}` +
+	`]}`
+
+func TestOpenAIService_RouteShape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Stream bool `json:"stream"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if !request.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{
+					FinishReason: openai.FinishReasonStop,
+					Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: routeShapeTestDocument},
+				}},
+			}))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		data, err := json.Marshal(openai.ChatCompletionStreamResponse{
+			Choices: []openai.ChatCompletionStreamChoice{{
+				Delta: openai.ChatCompletionStreamChoiceDelta{Content: routeShapeTestDocument},
+			}},
+		})
+		require.NoError(t, err)
+		_, _ = fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", data)
+	}))
+	defer server.Close()
+
+	service := newRetryTestService(server.URL)
+	end := Position{Latitude: 35.6812, Longitude: 139.7671}
+	request := CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeOneWay, EndLocation: &end}
+
+	check := func(t *testing.T, suggestions []CourseSuggestion) {
+		require.Len(t, suggestions, 2, "the course ending in Asakusa is dropped")
+		for _, suggestion := range suggestions {
+			assert.Equal(t, RouteShapeOneWay, suggestion.RouteShape)
+			assert.Equal(t, &end, suggestion.EndPoint)
+		}
+		assert.Equal(t, []string{"丸の内", "日比谷"}, []string{suggestions[0].Title, suggestions[1].Title})
+	}
+
+	t.Run("generate", func(t *testing.T) {
+		result, err := service.GenerateCourseSuggestions(context.Background(), request)
+		require.NoError(t, err)
+		check(t, result.Suggestions)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, WarnEndPointTooFar, result.Warnings[0].Code)
+		assert.Equal(t, "course-2", result.Warnings[0].ID)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var received []CourseSuggestion
+		require.NoError(t, service.StreamCourseSuggestions(context.Background(), request, func(suggestion CourseSuggestion) error {
+			received = append(received, suggestion)
+			return nil
+		}))
+		check(t, received)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		result, err := service.GenerateCourseSuggestions(context.Background(), CourseRequest{CourseType: "walking", Distance: "short", RouteShape: RouteShapeLoop})
+		require.NoError(t, err)
+		require.Len(t, result.Suggestions, 3)
+		for _, suggestion := range result.Suggestions {
+			assert.Equal(t, RouteShapeLoop, suggestion.RouteShape)
+			assert.Equal(t, suggestion.StartPoint, *suggestion.EndPoint, "a loop ends where it starts")
+		}
+	})
+}
+
+func TestTrackPromptData(t *testing.T) {
+	track, err := NewTrackRequest("皇居ラン", "jogging", trackLine([2]float64{0, 0}, [2]float64{2, 0}, [2]float64{2, 2}))
+	require.NoError(t, err)
+
+	data := trackPromptData(nil, nil, track)
+	assert.Equal(t, "東京", data.Area)
+	assert.Equal(t, RouteShapeOneWay, data.RouteShape)
+	assert.Len(t, data.Points, trackPromptPoints)
+	assert.Equal(t, 0.0, data.Points[0].DistanceKm)
+	assert.InDelta(t, track.DistanceKm, data.Points[len(data.Points)-1].DistanceKm, 1e-9)
+	assert.Equal(t, track.Bounds.MinLatitude, data.SouthWest.Latitude)
+	assert.Equal(t, track.Bounds.MaxLongitude, data.NorthEast.Longitude)
+
+	along := offsetPosition(defaultStartPoint, 1, 0.1)
+	far := offsetPosition(defaultStartPoint, 1, 1)
+	store := poi.NewStore([]poi.POI{
+		{Name: "近くの公園", Latitude: along.Latitude, Longitude: along.Longitude},
+		{Name: "遠くの神社", Latitude: far.Latitude, Longitude: far.Longitude},
+	})
+	landmarks := NewOpenAIService("test").WithPOIs(store, 3, 10).trackLandmarks(track)
+	require.Len(t, landmarks, 1, "only places near the track, not near its center")
+	assert.Equal(t, "近くの公園", landmarks[0].Name)
+	assert.InDelta(t, 0.1, landmarks[0].DistanceKm, 0.01)
+}
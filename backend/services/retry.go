@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls retries of retryable generation failures
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy retries up to twice with jittered exponential backoff
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    8 * time.Second,
+}
+
+// backoff returns the delay before the given retry (1-based) using
+// "full jitter": a random duration between zero and the exponential cap
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.BaseDelay << (retry - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// withRetry runs fn until it succeeds, fails with a non-retryable error,
+// the attempts are exhausted or ctx is done. An upstream Retry-After hint
+// takes precedence over the computed backoff as long as it fits in MaxDelay.
+func withRetry[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var result T
+	var err error
+	for attempt := 1; ; attempt++ {
+		result, err = fn(ctx)
+		if err == nil {
+			return result, nil
+		}
+
+		genErr := AsGenerationError(err)
+		if !genErr.Retryable() || attempt >= attempts || ctx.Err() != nil {
+			return result, err
+		}
+
+		delay := policy.backoff(attempt)
+		if genErr.RetryAfter > 0 && genErr.RetryAfter <= policy.MaxDelay {
+			delay = genErr.RetryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+const retryTestSuccessBody = `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"suggestions\":[]}"}}]}`
+
+// newFlakyOpenAIServer fails the first failures calls with the given status and body
+func newFlakyOpenAIServer(t *testing.T, failures int32, status int, header http.Header, body string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if n <= failures {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		_, _ = w.Write([]byte(retryTestSuccessBody))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newRetryTestService(baseURL string) *OpenAIService {
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = baseURL + "/v1"
+	return NewOpenAIServiceWithConfig(clientConfig, openai.GPT4o).WithRetryPolicy(fastRetryPolicy)
+}
+
+func TestOpenAIService_RetriesTransientErrors(t *testing.T) {
+	server, calls := newFlakyOpenAIServer(t, 2, http.StatusTooManyRequests, nil,
+		`{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`)
+
+	service := newRetryTestService(server.URL)
+	result, err := service.GenerateCourseSuggestions(context.Background(), CourseRequest{CourseType: "walking", Distance: "short"})
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	require.NotNil(t, result.Metadata)
+	assert.NotEmpty(t, result.Metadata.PromptVersion)
+}
+
+func TestOpenAIService_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		body          string
+		expectedKind  GenerationErrorKind
+		expectedCalls int32
+		retryAfter    time.Duration
+	}{
+		{
+			name:          "invalid API key is not retried",
+			status:        http.StatusUnauthorized,
+			body:          `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			expectedKind:  ErrKindInvalidAPIKey,
+			expectedCalls: 1,
+		},
+		{
+			name:          "quota exhausted is not retried",
+			status:        http.StatusTooManyRequests,
+			body:          `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			expectedKind:  ErrKindQuotaExceeded,
+			expectedCalls: 1,
+		},
+		{
+			name:          "context length",
+			status:        http.StatusBadRequest,
+			body:          `{"error":{"message":"This model's maximum context length is 128000 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			expectedKind:  ErrKindContextLength,
+			expectedCalls: 1,
+		},
+		{
+			name:          "server errors exhaust retries and keep Retry-After",
+			status:        http.StatusServiceUnavailable,
+			header:        http.Header{"Retry-After": []string{"7"}},
+			body:          `{"error":{"message":"The server is overloaded","type":"server_error"}}`,
+			expectedKind:  ErrKindUpstream,
+			expectedCalls: 3,
+			retryAfter:    7 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newFlakyOpenAIServer(t, 100, tt.status, tt.header, tt.body)
+
+			service := newRetryTestService(server.URL)
+			_, err := service.GenerateCourseSuggestions(context.Background(), CourseRequest{CourseType: "walking", Distance: "short"})
+			require.Error(t, err)
+
+			genErr := AsGenerationError(err)
+			assert.Equal(t, tt.expectedKind, genErr.Kind)
+			assert.Equal(t, tt.retryAfter, genErr.RetryAfter)
+			assert.Equal(t, tt.expectedCalls, atomic.LoadInt32(calls))
+		})
+	}
+}
+
+func TestOpenAIService_Refusal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"","refusal":"I can't help with that."}}]}`))
+	}))
+	defer server.Close()
+
+	service := newRetryTestService(server.URL)
+	_, err := service.GenerateCourseSuggestions(context.Background(), CourseRequest{CourseType: "walking", Distance: "short"})
+
+	require.Error(t, err)
+	assert.Equal(t, ErrKindRefusal, AsGenerationError(err).Kind)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for retry := 1; retry <= 10; retry++ {
+		delay := policy.backoff(retry)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, policy.MaxDelay)
+	}
+}
+
+func TestWithRetry_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	calls := 0
+	_, err := withRetry(ctx, policy, func(ctx context.Context) (string, error) {
+		calls++
+		cancel()
+		return "", &GenerationError{Kind: ErrKindUpstream, Err: context.Canceled}
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"potarin-backend/geo"
+	"potarin-backend/polyline"
+	"potarin-backend/routing"
+)
+
+// maxRoadSnapKm is how far a waypoint may be moved onto the road the route
+// passes through. A waypoint farther from any road is more likely misplaced
+// than off-road, so it is left where it is.
+const maxRoadSnapKm = 0.3
+
+// RoutedGenerator routes generated course details through their waypoints
+// and stores the route geometry and distance on the course. Waypoints not
+// verified against a POI are moved onto the road when the engine reports
+// where it joined them. Courses that cannot be routed are returned
+// unchanged, without a polyline.
+type RoutedGenerator struct {
+	next   CourseGenerator
+	router routing.Router
+}
+
+// NewRoutedGenerator wraps next with route computation for course details
+func NewRoutedGenerator(next CourseGenerator, router routing.Router) *RoutedGenerator {
+	return &RoutedGenerator{next: next, router: router}
+}
+
+// GenerateCourseSuggestions is passed through unchanged
+func (g *RoutedGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	return g.next.GenerateCourseSuggestions(ctx, request)
+}
+
+// GenerateCourseDetails generates the details and routes through the waypoints
+func (g *RoutedGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	result, err := g.next.GenerateCourseDetails(ctx, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.route(ctx, &result.Course); err != nil {
+		log.Printf("Routing course %s failed, keeping straight lines: %v", result.Course.ID, err)
+	}
+	return result, nil
+}
+
+// AnnotateTrack is passed through unchanged: the track is the route
+func (g *RoutedGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	return annotateTrack(ctx, g.next, track)
+}
+
+// StreamCourseSuggestions is passed through unchanged
+func (g *RoutedGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	return streamSuggestions(ctx, g.next, request, emit)
+}
+
+// route sets the polyline and routed distance of course
+func (g *RoutedGenerator) route(ctx context.Context, course *CourseDetails) error {
+	if len(course.Waypoints) < 2 {
+		return errors.New("fewer than 2 waypoints")
+	}
+
+	points := make([]routing.Point, len(course.Waypoints))
+	for i, waypoint := range course.Waypoints {
+		points[i] = routing.Point{Latitude: waypoint.Position.Latitude, Longitude: waypoint.Position.Longitude}
+	}
+
+	route, err := g.router.Route(ctx, routing.ProfileFor(course.CourseType), points)
+	if err != nil {
+		return err
+	}
+	geometry, err := polyline.Decode(route.Polyline, polyline.DefaultPrecision)
+	if err != nil {
+		return fmt.Errorf("invalid route geometry: %w", err)
+	}
+	if len(geometry) < 2 {
+		return errors.New("route geometry has fewer than 2 points")
+	}
+
+	course.Distance = math.Round(route.DistanceKm*10) / 10
+	course.Polyline = &route.Polyline
+	snapToRoads(course.Waypoints, route.Snapped)
+	return nil
+}
+
+// snapToRoads moves unverified waypoints to where the route joined them.
+// Verified waypoints keep their POI position, e.g. a shrine off the street.
+func snapToRoads(waypoints []Waypoint, snapped []routing.Point) {
+	if len(snapped) != len(waypoints) {
+		return
+	}
+	for i := range waypoints {
+		position := &waypoints[i].Position
+		if waypoints[i].Verified ||
+			geo.HaversineKm(position.Latitude, position.Longitude, snapped[i].Latitude, snapped[i].Longitude) > maxRoadSnapKm {
+			continue
+		}
+		*position = Position{Latitude: snapped[i].Latitude, Longitude: snapped[i].Longitude}
+	}
+}
+
+var (
+	_ CourseGenerator    = (*RoutedGenerator)(nil)
+	_ SuggestionStreamer = (*RoutedGenerator)(nil)
+	_ TrackAnnotator     = (*RoutedGenerator)(nil)
+)
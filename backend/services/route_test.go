@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/polyline"
+	"potarin-backend/routing"
+	shared "potarin-shared"
+)
+
+// stubRouter returns a fixed route or error and records the last request
+type stubRouter struct {
+	route   *routing.Route
+	err     error
+	profile routing.Profile
+	points  []routing.Point
+}
+
+func (r *stubRouter) Route(ctx context.Context, profile routing.Profile, points []routing.Point) (*routing.Route, error) {
+	r.profile, r.points = profile, points
+	return r.route, r.err
+}
+
+func TestRoutedGenerator_GenerateCourseDetails(t *testing.T) {
+	suggestion := CourseSuggestion{ID: "course-1", Title: "皇居ラン", CourseType: "cycling", Distance: 5, EstimatedTime: 30,
+		StartPoint: Position{Latitude: 35.681236, Longitude: 139.767125}}
+	geometry, err := polyline.Encode([]shared.Position{{Latitude: 35.681236, Longitude: 139.767125}, {Latitude: 35.6736, Longitude: 139.756}}, polyline.Precision6)
+	require.NoError(t, err)
+	router := &stubRouter{route: &routing.Route{Polyline: geometry, DistanceKm: 6.04}}
+
+	unrouted, err := NewFakeGenerator().GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+	result, err := NewRoutedGenerator(NewFakeGenerator(), router).GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+
+	assert.Equal(t, routing.Bicycle, router.profile)
+	require.Len(t, router.points, len(result.Course.Waypoints))
+	assert.Equal(t, result.Course.Waypoints[0].Position.Latitude, router.points[0].Latitude)
+
+	require.NotNil(t, result.Course.Polyline)
+	assert.Equal(t, geometry, *result.Course.Polyline)
+	assert.Equal(t, 6.0, result.Course.Distance)
+	assert.Equal(t, unrouted.Course.EstimatedTime, result.Course.EstimatedTime)
+}
+
+func TestRoutedGenerator_SnapsToRoads(t *testing.T) {
+	suggestion := CourseSuggestion{ID: "course-1", Title: "皇居ラン", CourseType: "walking", Distance: 5, EstimatedTime: 60,
+		StartPoint: Position{Latitude: 35.681236, Longitude: 139.767125}}
+	unrouted, err := NewFakeGenerator().GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+	waypoints := unrouted.Course.Waypoints
+	require.GreaterOrEqual(t, len(waypoints), 3)
+
+	// The engine joins the first waypoint 50m away, the last one 2km away
+	snapped := make([]routing.Point, len(waypoints))
+	for i, waypoint := range waypoints {
+		snapped[i] = routing.Point{Latitude: waypoint.Position.Latitude, Longitude: waypoint.Position.Longitude}
+	}
+	snapped[0].Latitude += 0.00045
+	snapped[len(snapped)-1].Latitude += 0.018
+	geometry, err := polyline.Encode([]shared.Position{{Latitude: 35.681236, Longitude: 139.767125}, {Latitude: 35.6736, Longitude: 139.756}}, polyline.Precision6)
+	require.NoError(t, err)
+	router := &stubRouter{route: &routing.Route{Polyline: geometry, DistanceKm: 6.04, Snapped: snapped}}
+
+	result, err := NewRoutedGenerator(NewFakeGenerator(), router).GenerateCourseDetails(context.Background(), suggestion)
+	require.NoError(t, err)
+	routed := result.Course.Waypoints
+	assert.Equal(t, snapped[0].Latitude, routed[0].Position.Latitude)
+	assert.Equal(t, waypoints[len(waypoints)-1].Position, routed[len(routed)-1].Position, "too far from the road")
+
+	// Verified waypoints keep their POI position
+	verified := append([]Waypoint(nil), waypoints...)
+	verified[0].Verified = true
+	snapToRoads(verified, snapped)
+	assert.Equal(t, waypoints[0].Position, verified[0].Position)
+
+	// Positions are only matched up when the engine reports all of them
+	partial := append([]Waypoint(nil), waypoints...)
+	snapToRoads(partial, snapped[:1])
+	assert.Equal(t, waypoints, partial)
+}
+
+func TestRoutedGenerator_RoutingFailure(t *testing.T) {
+	suggestion := CourseSuggestion{ID: "course-1", Title: "皇居ラン", CourseType: "walking", Distance: 5, EstimatedTime: 60,
+		StartPoint: Position{Latitude: 35.681236, Longitude: 139.767125}}
+
+	tests := []struct {
+		name   string
+		router *stubRouter
+	}{
+		{"engine error", &stubRouter{err: errors.New("connection refused")}},
+		{"invalid geometry", &stubRouter{route: &routing.Route{Polyline: "_p~iF~ps|", DistanceKm: 6}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewRoutedGenerator(NewFakeGenerator(), tt.router).GenerateCourseDetails(context.Background(), suggestion)
+			require.NoError(t, err)
+
+			expected, err := NewFakeGenerator().GenerateCourseDetails(context.Background(), suggestion)
+			require.NoError(t, err)
+			assert.Equal(t, routing.Foot, tt.router.profile)
+			assert.Nil(t, result.Course.Polyline)
+			assert.Equal(t, expected.Course, result.Course)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"potarin-backend/poi"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// Defaults of SnapOptions
+const (
+	DefaultSnapMaxDistanceKm = 1.0
+	DefaultSnapMinConfidence = 0.6
+)
+
+// SnapOptions tune how waypoints are matched to POIs
+type SnapOptions struct {
+	// MaxDistanceKm is how far from its generated position a waypoint's
+	// POI may be
+	MaxDistanceKm float64
+	// MinConfidence is the match confidence (see poi.Store.Match) needed to
+	// replace the generated position
+	MinConfidence float64
+}
+
+// SnappingGenerator matches the waypoints of generated course details to
+// known POIs by name and distance. Matched waypoints get the POI's position
+// and are marked verified; the others keep the generated position. Every
+// waypoint gets the confidence of its best candidate.
+type SnappingGenerator struct {
+	next    CourseGenerator
+	store   *poi.Store
+	options SnapOptions
+}
+
+// NewSnappingGenerator wraps next with waypoint snapping to the POIs of
+// store. Zero options use the defaults.
+func NewSnappingGenerator(next CourseGenerator, store *poi.Store, options SnapOptions) *SnappingGenerator {
+	if options.MaxDistanceKm <= 0 {
+		options.MaxDistanceKm = DefaultSnapMaxDistanceKm
+	}
+	if options.MinConfidence <= 0 {
+		options.MinConfidence = DefaultSnapMinConfidence
+	}
+	return &SnappingGenerator{next: next, store: store, options: options}
+}
+
+// GenerateCourseSuggestions is passed through unchanged
+func (g *SnappingGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	return g.next.GenerateCourseSuggestions(ctx, request)
+}
+
+// GenerateCourseDetails generates the details and snaps their waypoints
+func (g *SnappingGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	result, err := g.next.GenerateCourseDetails(ctx, suggestion)
+	if err != nil {
+		return nil, err
+	}
+	verified := g.snap(result.Course.Waypoints, nil)
+	log.Printf("Matched %d of %d waypoints of course %s to POIs", verified, len(result.Course.Waypoints), result.Course.ID)
+	return result, nil
+}
+
+// AnnotateTrack annotates the track and snaps its landmarks. The start, end
+// and checkpoints lie on the uploaded track and keep their position, only
+// getting the confidence of their best POI. Landmarks move to their POI when
+// it is within trackCorridorKm of the track.
+func (g *SnappingGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	result, err := annotateTrack(ctx, g.next, track)
+	if err != nil {
+		return nil, err
+	}
+	verified := g.snap(result.Course.Waypoints, func(waypoint Waypoint, match poi.Match) bool {
+		if waypoint.Type != "landmark" {
+			return false
+		}
+		_, distanceKm, _, _ := polyline.Nearest(track.Geometry, shared.Position{Latitude: match.Latitude, Longitude: match.Longitude})
+		return distanceKm <= trackCorridorKm
+	})
+	log.Printf("Matched %d of %d waypoints of course %s to POIs", verified, len(result.Course.Waypoints), result.Course.ID)
+	return result, nil
+}
+
+// StreamCourseSuggestions is passed through unchanged
+func (g *SnappingGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	return streamSuggestions(ctx, g.next, request, emit)
+}
+
+// snap matches every waypoint and returns how many were verified. When
+// movable is set, waypoints only move to matches it accepts; the others keep
+// their position and stay unverified.
+func (g *SnappingGenerator) snap(waypoints []Waypoint, movable func(Waypoint, poi.Match) bool) int {
+	verified := 0
+	for i := range waypoints {
+		waypoint := &waypoints[i]
+		match, found := g.store.Match(waypoint.Title, waypoint.Position.Latitude, waypoint.Position.Longitude, g.options.MaxDistanceKm)
+		confidence := math.Round(match.Confidence*100) / 100
+		waypoint.MatchConfidence = &confidence
+		waypoint.Verified = found && match.Confidence >= g.options.MinConfidence &&
+			(movable == nil || movable(*waypoint, match))
+		if waypoint.Verified {
+			waypoint.Position = Position{Latitude: match.Latitude, Longitude: match.Longitude}
+			verified++
+		}
+	}
+	return verified
+}
+
+var (
+	_ CourseGenerator    = (*SnappingGenerator)(nil)
+	_ SuggestionStreamer = (*SnappingGenerator)(nil)
+	_ TrackAnnotator     = (*SnappingGenerator)(nil)
+)
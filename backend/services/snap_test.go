@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/poi"
+)
+
+// stubDetailsGenerator returns fixed course details
+type stubDetailsGenerator struct {
+	CourseGenerator
+	course CourseDetails
+}
+
+func (g *stubDetailsGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	course := g.course
+	course.Waypoints = append([]Waypoint(nil), g.course.Waypoints...)
+	return &CourseDetailsResponse{Course: course}, nil
+}
+
+// AnnotateTrack returns the fixed course fitted to the track
+func (g *stubDetailsGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	result, err := g.GenerateCourseDetails(ctx, CourseSuggestion{})
+	if err != nil {
+		return nil, err
+	}
+	fitToTrack(&result.Course, track)
+	return result, nil
+}
+
+func TestSnappingGenerator_GenerateCourseDetails(t *testing.T) {
+	store := poi.NewStore([]poi.POI{
+		{Name: "吉祥寺駅", Category: "station", Latitude: 35.7031, Longitude: 139.5798},
+		{Name: "井の頭恩賜公園", Category: "park", Latitude: 35.6997, Longitude: 139.5731},
+	})
+	next := &stubDetailsGenerator{course: CourseDetails{ID: "course-1", Waypoints: []Waypoint{
+		{ID: "wp-1", Title: "吉祥寺駅", Type: "start", Position: Position{Latitude: 35.7050, Longitude: 139.5810}},
+		{ID: "wp-2", Title: "井の頭公園", Type: "landmark", Position: Position{Latitude: 35.6990, Longitude: 139.5740}},
+		{ID: "wp-3", Title: "謎の展望台", Type: "checkpoint", Position: Position{Latitude: 35.6980, Longitude: 139.5700}, Verified: true},
+		{ID: "wp-4", Title: "吉祥寺駅", Type: "end", Position: Position{Latitude: 35.7200, Longitude: 139.6000}},
+	}}}
+
+	result, err := NewSnappingGenerator(next, store, SnapOptions{}).GenerateCourseDetails(context.Background(), CourseSuggestion{ID: "course-1"})
+	require.NoError(t, err)
+	waypoints := result.Course.Waypoints
+	require.Len(t, waypoints, 4)
+
+	assert.True(t, waypoints[0].Verified)
+	assert.Equal(t, Position{Latitude: 35.7031, Longitude: 139.5798}, waypoints[0].Position)
+	require.NotNil(t, waypoints[0].MatchConfidence)
+	assert.Greater(t, *waypoints[0].MatchConfidence, 0.8)
+
+	assert.True(t, waypoints[1].Verified)
+	assert.Equal(t, Position{Latitude: 35.6997, Longitude: 139.5731}, waypoints[1].Position)
+
+	// Unknown places keep their position and are flagged, whatever the model claimed
+	assert.False(t, waypoints[2].Verified)
+	assert.Equal(t, Position{Latitude: 35.6980, Longitude: 139.5700}, waypoints[2].Position)
+	require.NotNil(t, waypoints[2].MatchConfidence)
+	assert.Zero(t, *waypoints[2].MatchConfidence)
+
+	// Known names too far away are not trusted
+	assert.False(t, waypoints[3].Verified)
+	assert.Equal(t, Position{Latitude: 35.7200, Longitude: 139.6000}, waypoints[3].Position)
+
+	// A stricter threshold keeps the abbreviated park name unverified
+	result, err = NewSnappingGenerator(next, store, SnapOptions{MinConfidence: 0.85}).GenerateCourseDetails(context.Background(), CourseSuggestion{ID: "course-1"})
+	require.NoError(t, err)
+	assert.True(t, result.Course.Waypoints[0].Verified)
+	assert.False(t, result.Course.Waypoints[1].Verified)
+}
+
+func TestSnappingGenerator_AnnotateTrack(t *testing.T) {
+	track, err := NewTrackRequest("", "walking", trackLine([2]float64{0, 0}, [2]float64{2, 0}, [2]float64{2, 2}))
+	require.NoError(t, err)
+
+	at := func(northKm, eastKm float64) Position {
+		return offsetPosition(defaultStartPoint, northKm, eastKm)
+	}
+	atPOI := func(name string, northKm, eastKm float64) poi.POI {
+		position := at(northKm, eastKm)
+		return poi.POI{Name: name, Latitude: position.Latitude, Longitude: position.Longitude}
+	}
+	store := poi.NewStore([]poi.POI{
+		atPOI("東京駅", 0.1, -0.1),
+		atPOI("皇居外苑", 1, -0.2),
+		atPOI("日比谷公園", 2.15, 1),
+		atPOI("丸の内ビルディング", 1, 0.8),
+	})
+	next := &stubDetailsGenerator{course: CourseDetails{ID: "course-1", Waypoints: []Waypoint{
+		{ID: "wp-1", Title: "東京駅", Type: "start", Position: at(0.1, -0.1)},
+		{ID: "wp-2", Title: "皇居外苑", Type: "checkpoint", Position: at(1, -0.1)},
+		{ID: "wp-3", Title: "丸の内ビルディング", Type: "landmark", Position: at(1, 0.25)},
+		{ID: "wp-4", Title: "日比谷公園", Type: "landmark", Position: at(2.1, 1)},
+	}}}
+
+	result, err := NewSnappingGenerator(next, store, SnapOptions{}).AnnotateTrack(context.Background(), track)
+	require.NoError(t, err)
+	waypoints := result.Course.Waypoints
+	require.Len(t, waypoints, 4)
+
+	// The start and checkpoints stay on the track, though their POIs match
+	start := track.Geometry[0]
+	assert.Equal(t, Position{Latitude: start.Latitude, Longitude: start.Longitude}, waypoints[0].Position)
+	assert.False(t, waypoints[0].Verified)
+	require.NotNil(t, waypoints[0].MatchConfidence)
+	assert.Greater(t, *waypoints[0].MatchConfidence, 0.8)
+	assert.Equal(t, at(1, 0).Longitude, waypoints[1].Position.Longitude)
+	assert.False(t, waypoints[1].Verified)
+
+	// Landmarks move to POIs near the track only
+	assert.Equal(t, "wp-3", waypoints[2].ID)
+	assert.False(t, waypoints[2].Verified)
+	assert.Equal(t, at(1, 0.25), waypoints[2].Position)
+	assert.True(t, waypoints[3].Verified)
+	assert.Equal(t, at(2.15, 1), waypoints[3].Position)
+}
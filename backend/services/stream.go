@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// SuggestionStreamer is implemented by generators that can emit course
+// suggestions one at a time as soon as each one is complete
+type SuggestionStreamer interface {
+	StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error
+}
+
+// streamSuggestions streams the suggestions of next, or emits those of its
+// complete result when next cannot stream
+func streamSuggestions(ctx context.Context, next CourseGenerator, request CourseRequest, emit func(CourseSuggestion) error) error {
+	if streamer, ok := next.(SuggestionStreamer); ok {
+		return streamer.StreamCourseSuggestions(ctx, request, emit)
+	}
+
+	result, err := next.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return err
+	}
+	return emitSuggestions(result.Suggestions, emit)
+}
+
+// emitSuggestions emits suggestions in order until emit fails
+func emitSuggestions(suggestions []CourseSuggestion, emit func(CourseSuggestion) error) error {
+	for _, suggestion := range suggestions {
+		if err := emit(suggestion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamCourseSuggestions streams suggestions from the chat completion API,
+// parsing the "suggestions" array incrementally. Only opening the stream is
+// retried; failures after the first chunk are returned as is.
+func (s *OpenAIService) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	chatRequest, err := s.buildSuggestionsChatRequest(s.prompts.Current(), request)
+	if err != nil {
+		return err
+	}
+	chatRequest.Stream = true
+	if s.usage != nil {
+		// Usage is only reported in the final chunk when requested
+		chatRequest.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+
+	stream, err := withRetry(ctx, s.retryPolicy, func(ctx context.Context) (*openai.ChatCompletionStream, error) {
+		sink := &retryAfterSink{}
+		stream, err := s.client.CreateChatCompletionStream(withRetryAfterSink(ctx, sink), chatRequest)
+		if err != nil {
+			return nil, classifyOpenAIError(fmt.Errorf("OpenAI API error: %w", err), sink.value)
+		}
+		return stream, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+
+	parser := &suggestionStreamParser{}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return classifyOpenAIError(fmt.Errorf("OpenAI stream error: %w", err), 0)
+		}
+		if chunk.Usage != nil {
+			s.recordUsage(ctx, *chunk.Usage)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.Delta.Refusal != "" || choice.FinishReason == openai.FinishReasonContentFilter {
+			return &GenerationError{Kind: ErrKindRefusal, Err: fmt.Errorf("OpenAI refused the request: %s", choice.Delta.Refusal)}
+		}
+
+		suggestions, err := parser.Write(choice.Delta.Content)
+		if err != nil {
+			return &GenerationError{Kind: ErrKindInvalidResponse, Err: err}
+		}
+		for _, suggestion := range suggestions {
+			if warning := conformSuggestion(request, &suggestion); warning != nil {
+				log.Printf("Dropped streamed suggestion %s: %s", suggestion.ID, warning.Code)
+				continue
+			}
+			suggestion.ID = suggestionID(suggestion)
+			if err := emit(suggestion); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !parser.Done() {
+		return &GenerationError{Kind: ErrKindInvalidResponse, Err: fmt.Errorf("OpenAI stream ended before the suggestions were complete")}
+	}
+	return nil
+}
+
+// StreamCourseSuggestions emits the fake suggestions one at a time
+func (g *FakeGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	result, err := g.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return err
+	}
+	for _, suggestion := range result.Suggestions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := emit(suggestion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suggestionStreamParser incrementally scans a JSON document of the form
+// {"suggestions": [{...}, {...}]} and returns each array element as soon as
+// its closing brace has been received
+type suggestionStreamParser struct {
+	data     []byte
+	pos      int
+	depth    int
+	inString bool
+	escaped  bool
+
+	// keyStart is the offset of the last string opened at depth 1
+	keyStart   int
+	lastKey    string
+	arrayDepth int
+	itemStart  int
+	arrayDone  bool
+}
+
+// Write feeds the next chunk of model output and returns completed suggestions
+func (p *suggestionStreamParser) Write(chunk string) ([]CourseSuggestion, error) {
+	p.data = append(p.data, chunk...)
+
+	var completed []CourseSuggestion
+	for ; p.pos < len(p.data); p.pos++ {
+		ch := p.data[p.pos]
+
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case ch == '\\':
+				p.escaped = true
+			case ch == '"':
+				p.inString = false
+				if p.depth == 1 {
+					p.lastKey = string(p.data[p.keyStart:p.pos])
+				}
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			p.inString = true
+			p.keyStart = p.pos + 1
+		case '{', '[':
+			if ch == '[' && p.depth == 1 && p.lastKey == "suggestions" && p.arrayDepth == 0 && !p.arrayDone {
+				p.arrayDepth = p.depth + 1
+			}
+			if ch == '{' && p.arrayDepth > 0 && p.depth == p.arrayDepth {
+				p.itemStart = p.pos
+			}
+			p.depth++
+		case '}', ']':
+			p.depth--
+			if p.depth < 0 {
+				return completed, fmt.Errorf("unbalanced JSON in model output")
+			}
+			if ch == '}' && p.arrayDepth > 0 && p.depth == p.arrayDepth {
+				var suggestion CourseSuggestion
+				if err := json.Unmarshal(p.data[p.itemStart:p.pos+1], &suggestion); err != nil {
+					return completed, fmt.Errorf("failed to parse streamed suggestion: %w", err)
+				}
+				completed = append(completed, suggestion)
+			}
+			if ch == ']' && p.arrayDepth > 0 && p.depth == p.arrayDepth-1 {
+				p.arrayDone = true
+				p.arrayDepth = 0
+			}
+		}
+	}
+
+	return completed, nil
+}
+
+// Done reports whether the suggestions array has been closed
+func (p *suggestionStreamParser) Done() bool {
+	return p.arrayDone
+}
+
+var (
+	_ SuggestionStreamer = (*OpenAIService)(nil)
+	_ SuggestionStreamer = (*FakeGenerator)(nil)
+)
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamTestDocument = `{"suggestions":[` +
+	`{"id":"c1","title":"皇居ラン {外周}","description":"\"定番\"コース","distance":5,"estimatedTime":30,"difficulty":"easy","courseType":"jogging","startPoint":{"latitude":35.68,"longitude":139.75},"highlights":["桜田門"],"summary":"a"},` +
+	`{"id":"c2","title":"隅田川","description":"川沿い [夜景]","distance":6.5,"estimatedTime":40,"difficulty":"moderate","courseType":"jogging","startPoint":{"latitude":35.71,"longitude":139.8},"highlights":[],"summary":"b"}` +
+	`]}`
+
+func TestSuggestionStreamParser_ByteByByte(t *testing.T) {
+	parser := &suggestionStreamParser{}
+
+	var ids []string
+	emittedAt := make([]int, 0)
+	for i, ch := range []byte(streamTestDocument) {
+		suggestions, err := parser.Write(string(ch))
+		require.NoError(t, err)
+		for _, suggestion := range suggestions {
+			ids = append(ids, suggestion.ID)
+			emittedAt = append(emittedAt, i)
+		}
+	}
+
+	assert.Equal(t, []string{"c1", "c2"}, ids)
+	assert.True(t, parser.Done())
+	// The first suggestion is emitted as soon as its closing brace arrives
+	assert.Equal(t, strings.Index(streamTestDocument, `,{"id":"c2"`)-1, emittedAt[0])
+}
+
+func TestSuggestionStreamParser_Incomplete(t *testing.T) {
+	parser := &suggestionStreamParser{}
+
+	suggestions, err := parser.Write(streamTestDocument[:strings.Index(streamTestDocument, `"c2"`)])
+	require.NoError(t, err)
+	assert.Len(t, suggestions, 1)
+	assert.False(t, parser.Done())
+}
+
+func TestOpenAIService_StreamCourseSuggestions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		// Chunk on rune boundaries like the real API does
+		runes := []rune(streamTestDocument)
+		for i := 0; i < len(runes); i += 7 {
+			end := min(i+7, len(runes))
+			chunk := openai.ChatCompletionStreamResponse{
+				Choices: []openai.ChatCompletionStreamChoice{{
+					Delta: openai.ChatCompletionStreamChoiceDelta{Content: string(runes[i:end])},
+				}},
+			}
+			data, err := json.Marshal(chunk)
+			require.NoError(t, err)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	service := newRetryTestService(server.URL)
+
+	var received []CourseSuggestion
+	err := service.StreamCourseSuggestions(context.Background(), CourseRequest{CourseType: "jogging", Distance: "medium"}, func(suggestion CourseSuggestion) error {
+		received = append(received, suggestion)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	assert.Equal(t, "皇居ラン {外周}", received[0].Title)
+	assert.Equal(t, 6.5, received[1].Distance)
+}
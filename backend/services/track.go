@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"sort"
+
+	"potarin-backend/geo"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+const (
+	// trackToleranceKm is how far simplifying an uploaded track may move it
+	trackToleranceKm = 0.005
+	// trackCorridorKm is how far from the track its waypoints and the
+	// landmarks offered to the model may lie
+	trackCorridorKm = 0.3
+	// trackPromptPoints is how many points of the track the model is given
+	trackPromptPoints = 40
+)
+
+// ErrTrackAnnotationUnsupported is returned by generators that cannot
+// annotate tracks
+var ErrTrackAnnotationUnsupported = errors.New("course generator cannot annotate tracks")
+
+// TrackRequest is an uploaded track to be described as a course. The
+// geometry is kept as uploaded; only waypoints and texts are generated.
+type TrackRequest struct {
+	// Name of the track in the uploaded file; may be empty
+	Name       string
+	CourseType string
+	// Geometry is the simplified track and Polyline its encoding (precision 6)
+	Geometry   []shared.Position
+	Polyline   string
+	DistanceKm float64
+	Bounds     polyline.Bounds
+	// RouteShape is derived from the geometry, see trackRouteShape
+	RouteShape string
+}
+
+// NewTrackRequest simplifies a track of at least two points and measures it
+func NewTrackRequest(name, courseType string, points []shared.Position) (TrackRequest, error) {
+	geometry := polyline.Simplify(points, trackToleranceKm)
+	bounds, ok := polyline.BoundsOf(geometry)
+	if !ok || len(geometry) < 2 {
+		return TrackRequest{}, errors.New("track has fewer than 2 points")
+	}
+	encoded, err := polyline.Encode(geometry, polyline.DefaultPrecision)
+	if err != nil {
+		return TrackRequest{}, err
+	}
+
+	track := TrackRequest{
+		Name:       name,
+		CourseType: courseType,
+		Geometry:   geometry,
+		Polyline:   encoded,
+		DistanceKm: polyline.LengthKm(geometry),
+		Bounds:     bounds,
+	}
+	track.RouteShape = trackRouteShape(geometry, track.DistanceKm)
+	return track, nil
+}
+
+// ID identifies the course of a track by its geometry and course type, so
+// uploading the same file again hits the response cache
+func (t TrackRequest) ID() string {
+	sum := sha256.Sum256([]byte(t.CourseType + "\x00" + t.Polyline))
+	return "track-" + hex.EncodeToString(sum[:6])
+}
+
+// trackRouteShape tells loops, whose ends meet, from out and back tracks,
+// which also pass the points of their first half on the way back, and one
+// way tracks
+func trackRouteShape(geometry []shared.Position, lengthKm float64) string {
+	first, last := geometry[0], geometry[len(geometry)-1]
+	if geo.HaversineKm(first.Latitude, first.Longitude, last.Latitude, last.Longitude) > maxLoopGapKm {
+		return RouteShapeOneWay
+	}
+	for _, fraction := range []float64{0.1, 0.2, 0.3, 0.4} {
+		out, _ := polyline.PointAt(geometry, lengthKm*fraction)
+		back, _ := polyline.PointAt(geometry, lengthKm*(1-fraction))
+		if geo.HaversineKm(out.Latitude, out.Longitude, back.Latitude, back.Longitude) > trackCorridorKm {
+			return RouteShapeLoop
+		}
+	}
+	return RouteShapeOutAndBack
+}
+
+// TrackAnnotator is implemented by generators that can describe an uploaded
+// track with waypoints, landmarks and texts
+type TrackAnnotator interface {
+	AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error)
+}
+
+// annotateTrack passes a track on to next, which fails with
+// ErrTrackAnnotationUnsupported when it cannot annotate tracks
+func annotateTrack(ctx context.Context, next CourseGenerator, track TrackRequest) (*CourseDetailsResponse, error) {
+	annotator, ok := next.(TrackAnnotator)
+	if !ok {
+		return nil, ErrTrackAnnotationUnsupported
+	}
+	return annotator.AnnotateTrack(ctx, track)
+}
+
+// fitToTrack makes generated course details describe the track: the course
+// takes the track's ID, geometry, distance and shape, the start and end
+// waypoints move to the ends of the track and checkpoints onto it. Other
+// waypoints farther than trackCorridorKm from the track are dropped, and the
+// rest are ordered along the track.
+func fitToTrack(course *CourseDetails, track TrackRequest) {
+	course.ID = track.ID()
+	course.CourseType = track.CourseType
+	course.Distance = math.Round(track.DistanceKm*10) / 10
+	course.RouteShape = track.RouteShape
+	encoded := track.Polyline
+	course.Polyline = &encoded
+
+	type placed struct {
+		waypoint Waypoint
+		alongKm  float64
+	}
+	start, end := track.Geometry[0], track.Geometry[len(track.Geometry)-1]
+	var waypoints []placed
+	for _, waypoint := range course.Waypoints {
+		switch waypoint.Type {
+		case "start":
+			waypoint.Position = Position{Latitude: start.Latitude, Longitude: start.Longitude}
+			waypoints = append(waypoints, placed{waypoint, 0})
+			continue
+		case "end":
+			waypoint.Position = Position{Latitude: end.Latitude, Longitude: end.Longitude}
+			waypoints = append(waypoints, placed{waypoint, track.DistanceKm})
+			continue
+		}
+
+		nearest, distanceKm, alongKm, _ := polyline.Nearest(track.Geometry, shared.Position{
+			Latitude:  waypoint.Position.Latitude,
+			Longitude: waypoint.Position.Longitude,
+		})
+		if distanceKm > trackCorridorKm {
+			continue
+		}
+		if waypoint.Type == "checkpoint" {
+			waypoint.Position = Position{
+				Latitude:  math.Round(nearest.Latitude*1e6) / 1e6,
+				Longitude: math.Round(nearest.Longitude*1e6) / 1e6,
+			}
+		}
+		waypoints = append(waypoints, placed{waypoint, alongKm})
+	}
+
+	sort.SliceStable(waypoints, func(i, j int) bool {
+		return waypoints[i].alongKm < waypoints[j].alongKm
+	})
+	course.Waypoints = make([]Waypoint, len(waypoints))
+	for i, placed := range waypoints {
+		course.Waypoints[i] = placed.waypoint
+	}
+}
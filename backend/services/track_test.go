@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/cache"
+	"potarin-backend/polyline"
+	shared "potarin-shared"
+)
+
+// trackLine returns the points reached by walking the north and east
+// offsets in km from Tokyo Station
+func trackLine(offsets ...[2]float64) []shared.Position {
+	points := make([]shared.Position, len(offsets))
+	for i, offset := range offsets {
+		position := offsetPosition(defaultStartPoint, offset[0], offset[1])
+		points[i] = shared.Position{Latitude: position.Latitude, Longitude: position.Longitude}
+	}
+	return points
+}
+
+func TestNewTrackRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   []shared.Position
+		shape    string
+		distance float64
+	}{
+		{"loop", trackLine([2]float64{0, 0}, [2]float64{1, 0}, [2]float64{1, 1}, [2]float64{0, 1}, [2]float64{0, 0}), RouteShapeLoop, 4},
+		{"out and back", trackLine([2]float64{0, 0}, [2]float64{1, 0}, [2]float64{1, 1}, [2]float64{1, 0}, [2]float64{0, 0}), RouteShapeOutAndBack, 4},
+		{"one way", trackLine([2]float64{0, 0}, [2]float64{1, 0}, [2]float64{1, 1}), RouteShapeOneWay, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track, err := NewTrackRequest("", "walking", tt.points)
+			require.NoError(t, err)
+			assert.Equal(t, tt.shape, track.RouteShape)
+			assert.InDelta(t, tt.distance, track.DistanceKm, 0.02)
+		})
+	}
+
+	// Points on a straight line are dropped, the bounds cover the rest
+	track, err := NewTrackRequest("皇居ラン", "jogging", trackLine([2]float64{0, 0}, [2]float64{0.5, 0}, [2]float64{1, 0}))
+	require.NoError(t, err)
+	assert.Len(t, track.Geometry, 2)
+	decoded, err := polyline.Decode(track.Polyline, polyline.DefaultPrecision)
+	require.NoError(t, err)
+	assert.Equal(t, track.Geometry, decoded)
+	assert.Equal(t, track.Geometry[0].Latitude, track.Bounds.MinLatitude)
+	assert.Equal(t, track.Geometry[1].Latitude, track.Bounds.MaxLatitude)
+
+	same, err := NewTrackRequest("別名", "jogging", trackLine([2]float64{0, 0}, [2]float64{1, 0}))
+	require.NoError(t, err)
+	assert.Equal(t, track.ID(), same.ID(), "the ID depends on the geometry, not the name")
+	other, err := NewTrackRequest("", "cycling", trackLine([2]float64{0, 0}, [2]float64{1, 0}))
+	require.NoError(t, err)
+	assert.NotEqual(t, track.ID(), other.ID())
+
+	_, err = NewTrackRequest("", "walking", trackLine([2]float64{0, 0}))
+	assert.Error(t, err)
+}
+
+func TestFitToTrack(t *testing.T) {
+	track, err := NewTrackRequest("", "walking", trackLine([2]float64{0, 0}, [2]float64{2, 0}, [2]float64{2, 2}))
+	require.NoError(t, err)
+
+	course := CourseDetails{
+		ID:         "course-1",
+		CourseType: "cycling",
+		Distance:   10,
+		Waypoints: []Waypoint{
+			{ID: "wp-1", Type: "start", Position: offsetPosition(defaultStartPoint, 0.2, 0.2)},
+			{ID: "wp-2", Type: "landmark", Position: offsetPosition(defaultStartPoint, 2.1, 1)},
+			{ID: "wp-3", Type: "checkpoint", Position: offsetPosition(defaultStartPoint, 1, 0.1)},
+			{ID: "wp-4", Type: "landmark", Position: offsetPosition(defaultStartPoint, 1, 1)},
+			{ID: "wp-5", Type: "end", Position: defaultStartPoint},
+		},
+	}
+	fitToTrack(&course, track)
+
+	assert.Equal(t, track.ID(), course.ID)
+	assert.Equal(t, "walking", course.CourseType)
+	assert.Equal(t, 4.0, course.Distance)
+	assert.Equal(t, RouteShapeOneWay, course.RouteShape)
+	require.NotNil(t, course.Polyline)
+	assert.Equal(t, track.Polyline, *course.Polyline)
+
+	ids := make([]string, len(course.Waypoints))
+	for i, waypoint := range course.Waypoints {
+		ids[i] = waypoint.ID
+	}
+	assert.Equal(t, []string{"wp-1", "wp-3", "wp-2", "wp-5"}, ids, "ordered along the track, wp-4 is 1km off")
+
+	start, end := track.Geometry[0], track.Geometry[len(track.Geometry)-1]
+	assert.Equal(t, Position{Latitude: start.Latitude, Longitude: start.Longitude}, course.Waypoints[0].Position)
+	assert.Equal(t, Position{Latitude: end.Latitude, Longitude: end.Longitude}, course.Waypoints[3].Position)
+	assert.Equal(t, offsetPosition(defaultStartPoint, 1, 0).Longitude, course.Waypoints[1].Position.Longitude, "checkpoints move onto the track")
+	assert.Equal(t, offsetPosition(defaultStartPoint, 2.1, 1), course.Waypoints[2].Position, "landmarks keep their position")
+}
+
+// plainGenerator hides the TrackAnnotator implementation of its generator
+type plainGenerator struct {
+	CourseGenerator
+}
+
+func TestAnnotateTrack_Decorators(t *testing.T) {
+	track, err := NewTrackRequest("朝ラン", "jogging", trackLine([2]float64{0, 0}, [2]float64{1, 0}, [2]float64{1, 1}, [2]float64{0, 1}, [2]float64{0, 0}))
+	require.NoError(t, err)
+
+	var generator CourseGenerator = NewFakeGenerator()
+	generator = NewRoutedGenerator(generator, nil)
+	generator = NewCachedGenerator(NewCoalescingGenerator(generator, 0), cache.NewMemoryBackend(cache.Limits{}), CacheOptions{})
+	annotator := generator.(TrackAnnotator)
+
+	result, err := annotator.AnnotateTrack(context.Background(), track)
+	require.NoError(t, err)
+	assert.Equal(t, "朝ラン", result.Course.Title)
+	assert.Equal(t, track.ID(), result.Course.ID)
+	assert.Equal(t, RouteShapeLoop, result.Course.RouteShape)
+	require.Len(t, result.Course.Waypoints, 5)
+	assert.Equal(t, "start", result.Course.Waypoints[0].Type)
+	assert.Equal(t, "end", result.Course.Waypoints[4].Type)
+
+	cached, err := annotator.AnnotateTrack(context.Background(), track)
+	require.NoError(t, err)
+	require.NotNil(t, cached.Metadata)
+	assert.True(t, cached.Metadata.CacheHit)
+	assert.Equal(t, result.Course.Waypoints, cached.Course.Waypoints)
+
+	_, err = NewRoutedGenerator(plainGenerator{NewFakeGenerator()}, nil).AnnotateTrack(context.Background(), track)
+	assert.ErrorIs(t, err, ErrTrackAnnotationUnsupported)
+}
@@ -8,8 +8,22 @@ type CourseRequest struct {
 	Distance    string             `json:"distance"`
 	Location    *Position          `json:"location,omitempty"`
 	Preferences *CoursePreferences `json:"preferences,omitempty"`
+	// RouteShape is one of the RouteShape constants; any shape when empty
+	RouteShape  string    `json:"routeShape,omitempty"`
+	EndLocation *Position `json:"endLocation,omitempty"`
+	// DistanceRange overrides the range of the Distance bucket, see DistanceLimits
+	DistanceRange *DistanceRange `json:"distanceRange,omitempty"`
+	// TimeBudgetMinutes is the most time the course may take; zero for none
+	TimeBudgetMinutes int `json:"timeBudgetMinutes,omitempty"`
 }
 
+// Route shapes of a course
+const (
+	RouteShapeLoop       = "loop"
+	RouteShapeOneWay     = "one_way"
+	RouteShapeOutAndBack = "out_and_back"
+)
+
 type Position struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
@@ -23,7 +37,9 @@ type CoursePreferences struct {
 
 // Response types from OpenAI
 type CourseSuggestionsResponse struct {
-	Suggestions []CourseSuggestion `json:"suggestions"`
+	Suggestions []CourseSuggestion  `json:"suggestions"`
+	Warnings    []ValidationWarning `json:"warnings,omitempty"`
+	Metadata    *GenerationMetadata `json:"metadata,omitempty"`
 }
 
 type CourseSuggestion struct {
@@ -37,10 +53,23 @@ type CourseSuggestion struct {
 	StartPoint    Position `json:"startPoint"`
 	Highlights    []string `json:"highlights"`
 	Summary       string   `json:"summary"`
+	RouteShape    string   `json:"routeShape,omitempty"`
+	// EndPoint is where the course ends, the start point unless one way
+	EndPoint *Position `json:"endPoint,omitempty"`
 }
 
 type CourseDetailsResponse struct {
-	Course CourseDetails `json:"course"`
+	Course   CourseDetails       `json:"course"`
+	Metadata *GenerationMetadata `json:"metadata,omitempty"`
+}
+
+// GenerationMetadata describes how a response was produced. It is never part
+// of the model output.
+type GenerationMetadata struct {
+	CacheHit bool       `json:"cacheHit"`
+	CachedAt *time.Time `json:"cachedAt,omitempty"`
+	// PromptVersion identifies the prompt templates used to generate the response
+	PromptVersion string `json:"promptVersion,omitempty"`
 }
 
 type CourseDetails struct {
@@ -52,6 +81,24 @@ type CourseDetails struct {
 	Difficulty    string     `json:"difficulty"`
 	CourseType    string     `json:"courseType"`
 	Waypoints     []Waypoint `json:"waypoints"`
+	// RouteShape is copied from the suggestion; never part of the model output
+	RouteShape string `json:"routeShape,omitempty"`
+	// Polyline is the routed geometry (encoded, precision 6); never part of the model output
+	Polyline *string `json:"polyline,omitempty"`
+	// Elevation is sampled from DEM data; never part of the model output
+	Elevation *Elevation `json:"elevation,omitempty"`
+}
+
+// Elevation is the elevation gain and profile of a course
+type Elevation struct {
+	Gain    float64            `json:"gain"`
+	Profile []ElevationProfile `json:"profile"`
+}
+
+// ElevationProfile is the elevation in meters at a distance in km along a course
+type ElevationProfile struct {
+	Distance  float64 `json:"distance"`
+	Elevation float64 `json:"elevation"`
 }
 
 type Waypoint struct {
@@ -60,6 +107,10 @@ type Waypoint struct {
 	Description string   `json:"description"`
 	Position    Position `json:"position"`
 	Type        string   `json:"type"`
+	// Verified is set when the position was replaced by a known POI's; never part of the model output
+	Verified bool `json:"verified"`
+	// MatchConfidence of the best POI candidate from 0 to 1, nil when the waypoint was not matched
+	MatchConfidence *float64 `json:"matchConfidence,omitempty"`
 }
 
 // Utility functions
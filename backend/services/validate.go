@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"potarin-backend/geo"
+)
+
+// Warning codes reported for suggestions removed by StartPointPolicy
+const (
+	WarnStartPointTooFar    = "start_point_too_far"
+	WarnStartPointNotOnLand = "start_point_not_on_land"
+)
+
+// ValidationWarning reports a problem found in a generated item
+type ValidationWarning struct {
+	// ID of the suggestion or waypoint concerned
+	ID      string `json:"id,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// StartPointPolicy decides which suggested start points are plausible
+type StartPointPolicy struct {
+	// MaxRadiusKm is the maximum distance from the requested location; zero disables the check
+	MaxRadiusKm float64
+	// Land is the area start points must lie in; nil disables the check
+	Land *geo.Region
+	// LandToleranceKm allows start points this far outside Land, e.g. for coarse outlines
+	LandToleranceKm float64
+	// Reprompt asks the generator once more to replace removed suggestions
+	Reprompt bool
+}
+
+// check returns a warning when the suggestion's start point violates the policy
+func (p StartPointPolicy) check(request CourseRequest, suggestion CourseSuggestion) *ValidationWarning {
+	start := suggestion.StartPoint
+
+	if p.Land != nil && p.Land.DistanceKm(start.Latitude, start.Longitude) > p.LandToleranceKm {
+		return &ValidationWarning{
+			ID:      suggestion.ID,
+			Code:    WarnStartPointNotOnLand,
+			Message: fmt.Sprintf("「%s」の出発地点が陸地上にないため除外しました", suggestion.Title),
+		}
+	}
+
+	if p.MaxRadiusKm > 0 && request.Location != nil {
+		distance := geo.HaversineKm(request.Location.Latitude, request.Location.Longitude, start.Latitude, start.Longitude)
+		if distance > p.MaxRadiusKm {
+			return &ValidationWarning{
+				ID:      suggestion.ID,
+				Code:    WarnStartPointTooFar,
+				Message: fmt.Sprintf("「%s」の出発地点が指定地点から%.1fkm離れているため除外しました", suggestion.Title, distance),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidatingGenerator removes suggestions whose start point is implausible
+// and reports each removal as a warning on the response
+type ValidatingGenerator struct {
+	next   CourseGenerator
+	policy StartPointPolicy
+}
+
+// NewValidatingGenerator wraps next with start point validation
+func NewValidatingGenerator(next CourseGenerator, policy StartPointPolicy) *ValidatingGenerator {
+	return &ValidatingGenerator{next: next, policy: policy}
+}
+
+// GenerateCourseSuggestions validates the generated suggestions. With
+// Reprompt, removed suggestions are replaced by valid ones from one more call.
+func (g *ValidatingGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	result, err := g.next.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, warnings := g.filter(request, result.Suggestions)
+	if len(warnings) > 0 && g.policy.Reprompt {
+		valid = g.replace(ctx, request, valid, len(warnings))
+	}
+
+	validated := *result
+	validated.Suggestions = valid
+	validated.Warnings = append(append([]ValidationWarning(nil), result.Warnings...), warnings...)
+	return &validated, nil
+}
+
+// GenerateCourseDetails is passed through unchanged
+func (g *ValidatingGenerator) GenerateCourseDetails(ctx context.Context, suggestion CourseSuggestion) (*CourseDetailsResponse, error) {
+	return g.next.GenerateCourseDetails(ctx, suggestion)
+}
+
+// AnnotateTrack is passed through unchanged
+func (g *ValidatingGenerator) AnnotateTrack(ctx context.Context, track TrackRequest) (*CourseDetailsResponse, error) {
+	return annotateTrack(ctx, g.next, track)
+}
+
+// StreamCourseSuggestions drops invalid suggestions from the stream. A stream
+// has no place for warnings, so they are only logged.
+func (g *ValidatingGenerator) StreamCourseSuggestions(ctx context.Context, request CourseRequest, emit func(CourseSuggestion) error) error {
+	streamer, ok := g.next.(SuggestionStreamer)
+	if !ok {
+		result, err := g.GenerateCourseSuggestions(ctx, request)
+		if err != nil {
+			return err
+		}
+		return emitSuggestions(result.Suggestions, emit)
+	}
+
+	return streamer.StreamCourseSuggestions(ctx, request, func(suggestion CourseSuggestion) error {
+		if warning := g.policy.check(request, suggestion); warning != nil {
+			log.Printf("Dropped streamed suggestion %s: %s", suggestion.ID, warning.Code)
+			return nil
+		}
+		return emit(suggestion)
+	})
+}
+
+// filter splits suggestions into valid ones and warnings for the rest
+func (g *ValidatingGenerator) filter(request CourseRequest, suggestions []CourseSuggestion) ([]CourseSuggestion, []ValidationWarning) {
+	return filterSuggestions(request, suggestions, g.policy.check)
+}
+
+// filterSuggestions splits suggestions into the ones check accepts and
+// warnings for the rest
+func filterSuggestions(request CourseRequest, suggestions []CourseSuggestion, check func(CourseRequest, CourseSuggestion) *ValidationWarning) ([]CourseSuggestion, []ValidationWarning) {
+	valid := make([]CourseSuggestion, 0, len(suggestions))
+	var warnings []ValidationWarning
+	for _, suggestion := range suggestions {
+		if warning := check(request, suggestion); warning != nil {
+			warnings = append(warnings, *warning)
+			continue
+		}
+		valid = append(valid, suggestion)
+	}
+	return valid, warnings
+}
+
+// replace generates once more and appends up to missing valid suggestions.
+// A failed attempt keeps the suggestions that were already valid.
+func (g *ValidatingGenerator) replace(ctx context.Context, request CourseRequest, valid []CourseSuggestion, missing int) []CourseSuggestion {
+	retry, err := g.next.GenerateCourseSuggestions(ctx, request)
+	if err != nil {
+		log.Printf("Failed to regenerate invalid suggestions: %v", err)
+		return valid
+	}
+
+	replacements, _ := g.filter(request, retry.Suggestions)
+	ids := make(map[string]bool, len(valid))
+	for _, suggestion := range valid {
+		ids[suggestion.ID] = true
+	}
+	for _, replacement := range replacements {
+		if missing == 0 {
+			break
+		}
+		// Model generated IDs restart at "course-1" on every call
+		for ids[replacement.ID] {
+			replacement.ID += "-r"
+		}
+		ids[replacement.ID] = true
+		valid = append(valid, replacement)
+		missing--
+	}
+	return valid
+}
+
+var (
+	_ CourseGenerator    = (*ValidatingGenerator)(nil)
+	_ SuggestionStreamer = (*ValidatingGenerator)(nil)
+	_ TrackAnnotator     = (*ValidatingGenerator)(nil)
+)
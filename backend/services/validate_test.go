@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"potarin-backend/geo"
+)
+
+// scriptedGenerator returns the next scripted suggestions on every call
+type scriptedGenerator struct {
+	FakeGenerator
+	responses [][]CourseSuggestion
+	calls     int
+}
+
+func (g *scriptedGenerator) GenerateCourseSuggestions(ctx context.Context, request CourseRequest) (*CourseSuggestionsResponse, error) {
+	suggestions := g.responses[min(g.calls, len(g.responses)-1)]
+	g.calls++
+	return &CourseSuggestionsResponse{Suggestions: suggestions}, nil
+}
+
+func suggestionAt(id string, latitude, longitude float64) CourseSuggestion {
+	return CourseSuggestion{ID: id, Title: id, Distance: 2, StartPoint: Position{Latitude: latitude, Longitude: longitude}}
+}
+
+var validationTestRequest = CourseRequest{
+	CourseType: "walking",
+	Distance:   "short",
+	Location:   &Position{Latitude: 35.681236, Longitude: 139.767125},
+}
+
+var validationTestPolicy = StartPointPolicy{
+	MaxRadiusKm:     5,
+	Land:            geo.JapanLand(),
+	LandToleranceKm: 3,
+}
+
+func TestValidatingGenerator_Drop(t *testing.T) {
+	upstream := &scriptedGenerator{responses: [][]CourseSuggestion{{
+		suggestionAt("course-1", 35.6852, 139.7528), // Imperial Palace
+		suggestionAt("course-2", 35.4437, 139.6380), // Yokohama, ~30km away
+		suggestionAt("course-3", 35.0, 141.5),       // Pacific Ocean
+	}}}
+
+	// Without a requested location only the land check applies
+	for name, request := range map[string]CourseRequest{
+		"with location":    validationTestRequest,
+		"without location": {CourseType: "walking", Distance: "short"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			generator := NewValidatingGenerator(upstream, validationTestPolicy)
+			result, err := generator.GenerateCourseSuggestions(context.Background(), request)
+			require.NoError(t, err)
+
+			var ids, codes []string
+			for _, suggestion := range result.Suggestions {
+				ids = append(ids, suggestion.ID)
+			}
+			for _, warning := range result.Warnings {
+				codes = append(codes, warning.ID+":"+warning.Code)
+			}
+
+			if request.Location != nil {
+				assert.Equal(t, []string{"course-1"}, ids)
+				assert.Equal(t, []string{"course-2:" + WarnStartPointTooFar, "course-3:" + WarnStartPointNotOnLand}, codes)
+			} else {
+				assert.Equal(t, []string{"course-1", "course-2"}, ids)
+				assert.Equal(t, []string{"course-3:" + WarnStartPointNotOnLand}, codes)
+			}
+		})
+	}
+}
+
+func TestValidatingGenerator_Reprompt(t *testing.T) {
+	upstream := &scriptedGenerator{responses: [][]CourseSuggestion{
+		{
+			suggestionAt("course-1", 35.6852, 139.7528),
+			suggestionAt("course-2", 35.0, 141.5),
+		},
+		{
+			suggestionAt("course-1", 35.6736, 139.7560), // Hibiya Park
+			suggestionAt("course-2", 36.0, 142.0),
+		},
+	}}
+	policy := validationTestPolicy
+	policy.Reprompt = true
+
+	result, err := NewValidatingGenerator(upstream, policy).GenerateCourseSuggestions(context.Background(), validationTestRequest)
+	require.NoError(t, err)
+
+	require.Len(t, result.Suggestions, 2)
+	assert.Equal(t, "course-1", result.Suggestions[0].ID)
+	assert.Equal(t, "course-1-r", result.Suggestions[1].ID)
+	assert.Equal(t, 35.6736, result.Suggestions[1].StartPoint.Latitude)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, 2, upstream.calls)
+}
+
+func TestValidatingGenerator_Stream(t *testing.T) {
+	// The fake generator starts suggestions 300m from the requested location
+	tests := []struct {
+		radiusKm float64
+		expected int
+	}{
+		{radiusKm: 1, expected: 3},
+		{radiusKm: 0.1, expected: 0},
+	}
+
+	for _, tt := range tests {
+		generator := NewValidatingGenerator(NewFakeGenerator(), StartPointPolicy{MaxRadiusKm: tt.radiusKm})
+
+		emitted := 0
+		err := generator.StreamCourseSuggestions(context.Background(), validationTestRequest, func(CourseSuggestion) error {
+			emitted++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, emitted)
+	}
+}
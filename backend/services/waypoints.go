@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"potarin-backend/geo"
+)
+
+// Warning codes reported by ReviewCourseDetails
+const (
+	WarnTooFewWaypoints      = "too_few_waypoints"
+	WarnWaypointTypeFixed    = "waypoint_type_corrected"
+	WarnDistanceCorrected    = "distance_corrected"
+	WarnDistanceOutsideRange = "distance_outside_requested_range"
+	WarnLegTooLong           = "leg_too_long"
+	WarnLoopNotClosed        = "loop_not_closed"
+)
+
+// routeDetourFactor converts the straight-line length through the waypoints
+// into an estimate of the distance along streets
+const routeDetourFactor = 1.25
+
+// Claimed distances between these multiples of the straight-line length are
+// accepted as they are
+const (
+	minClaimedRatio = 0.9
+	maxClaimedRatio = 2.0
+)
+
+// maxLoopGapKm is how far from the start a course returning to it may end
+const maxLoopGapKm = 0.5
+
+// maxLegKm is the longest plausible straight line between consecutive waypoints
+var maxLegKm = map[string]float64{
+	"walking": 2.5,
+	"jogging": 4,
+	"cycling": 10,
+}
+
+// ReviewCourseDetails checks generated course details for consistency and
+// fixes what can be fixed: the first and last waypoints become "start" and
+// "end", and a claimed distance that does not fit the waypoints is replaced
+// by an estimate. Long jumps between waypoints, loop and out and back courses
+// ending away from their start and distances outside the requested range (a
+// zero range is not checked) are only reported. The distance of a routed
+// course (with a polyline) is measured and never replaced. Every finding is
+// returned as a warning.
+func ReviewCourseDetails(course *CourseDetails, requested DistanceRange) []ValidationWarning {
+	var warnings []ValidationWarning
+	waypoints := course.Waypoints
+
+	if len(waypoints) < 2 {
+		return append(warnings, ValidationWarning{
+			ID:      course.ID,
+			Code:    WarnTooFewWaypoints,
+			Message: "ウェイポイントが不足しているため、コースを検証できませんでした",
+		})
+	}
+
+	last := len(waypoints) - 1
+	for i := range waypoints {
+		expected := "checkpoint"
+		switch i {
+		case 0:
+			expected = "start"
+		case last:
+			expected = "end"
+		default:
+			// Intermediate landmarks and checkpoints are both fine
+			if waypoints[i].Type != "start" && waypoints[i].Type != "end" {
+				continue
+			}
+		}
+		if waypoints[i].Type != expected {
+			warnings = append(warnings, ValidationWarning{
+				ID:      waypoints[i].ID,
+				Code:    WarnWaypointTypeFixed,
+				Message: fmt.Sprintf("ウェイポイント「%s」の種類を%sから%sに修正しました", waypoints[i].Title, waypoints[i].Type, expected),
+			})
+			waypoints[i].Type = expected
+		}
+	}
+
+	if course.RouteShape == RouteShapeLoop || course.RouteShape == RouteShapeOutAndBack {
+		start, end := waypoints[0].Position, waypoints[last].Position
+		if gapKm := geo.HaversineKm(start.Latitude, start.Longitude, end.Latitude, end.Longitude); gapKm > maxLoopGapKm {
+			warnings = append(warnings, ValidationWarning{
+				ID:      waypoints[last].ID,
+				Code:    WarnLoopNotClosed,
+				Message: fmt.Sprintf("出発地点に戻るコースですが、ゴール地点「%s」が出発地点から%.1fkm離れています", waypoints[last].Title, gapKm),
+			})
+		}
+	}
+
+	pathKm := 0.0
+	legLimit, hasLegLimit := maxLegKm[course.CourseType]
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1].Position, waypoints[i].Position
+		legKm := geo.HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		pathKm += legKm
+
+		if hasLegLimit && legKm > legLimit {
+			warnings = append(warnings, ValidationWarning{
+				ID:      waypoints[i].ID,
+				Code:    WarnLegTooLong,
+				Message: fmt.Sprintf("「%s」から「%s」まで直線で%.1fkmあり、ウェイポイントが不足している可能性があります", waypoints[i-1].Title, waypoints[i].Title, legKm),
+			})
+		}
+	}
+
+	if course.Polyline == nil && pathKm > 0 && (course.Distance < pathKm*minClaimedRatio || course.Distance > pathKm*maxClaimedRatio) {
+		estimated := math.Round(pathKm*routeDetourFactor*10) / 10
+		warnings = append(warnings, ValidationWarning{
+			ID:      course.ID,
+			Code:    WarnDistanceCorrected,
+			Message: fmt.Sprintf("距離%.1fkmがウェイポイントと一致しないため、%.1fkmに修正しました", course.Distance, estimated),
+		})
+		course.Distance = estimated
+	}
+
+	if !requested.Contains(course.Distance, 0) {
+		warnings = append(warnings, ValidationWarning{
+			ID:      course.ID,
+			Code:    WarnDistanceOutsideRange,
+			Message: fmt.Sprintf("コースの距離%.1fkmが希望の距離の範囲外です", course.Distance),
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// squareCourse builds a loop around Tokyo Station with sides of sideKm
+func squareCourse(courseType string, sideKm, claimedKm float64) CourseDetails {
+	start := Position{Latitude: 35.681236, Longitude: 139.767125}
+	return CourseDetails{
+		ID:            "course-1",
+		Distance:      claimedKm,
+		EstimatedTime: 60,
+		CourseType:    courseType,
+		Waypoints: []Waypoint{
+			{ID: "wp-1", Title: "A", Position: start, Type: "start"},
+			{ID: "wp-2", Title: "B", Position: offsetPosition(start, sideKm, 0), Type: "checkpoint"},
+			{ID: "wp-3", Title: "C", Position: offsetPosition(start, sideKm, sideKm), Type: "landmark"},
+			{ID: "wp-4", Title: "D", Position: offsetPosition(start, 0, sideKm), Type: "checkpoint"},
+			{ID: "wp-5", Title: "E", Position: start, Type: "end"},
+		},
+	}
+}
+
+func warningCodes(warnings []ValidationWarning) []string {
+	codes := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		codes = append(codes, warning.ID+":"+warning.Code)
+	}
+	return codes
+}
+
+func TestReviewCourseDetails(t *testing.T) {
+	walkingShort, _ := BucketRange("walking", "short")
+
+	t.Run("consistent course", func(t *testing.T) {
+		course := squareCourse("walking", 0.5, 2.4)
+		warnings := ReviewCourseDetails(&course, walkingShort)
+
+		assert.Empty(t, warnings)
+		assert.Equal(t, 2.4, course.Distance)
+		assert.Equal(t, 60, course.EstimatedTime)
+	})
+
+	t.Run("waypoint types are corrected", func(t *testing.T) {
+		course := squareCourse("walking", 0.5, 2.4)
+		course.Waypoints[0].Type = "landmark"
+		course.Waypoints[2].Type = "end"
+		course.Waypoints[4].Type = "checkpoint"
+
+		warnings := ReviewCourseDetails(&course, DistanceRange{})
+
+		assert.Equal(t, []string{
+			"wp-1:" + WarnWaypointTypeFixed,
+			"wp-3:" + WarnWaypointTypeFixed,
+			"wp-5:" + WarnWaypointTypeFixed,
+		}, warningCodes(warnings))
+		assert.Equal(t, "start", course.Waypoints[0].Type)
+		assert.Equal(t, "checkpoint", course.Waypoints[2].Type)
+		assert.Equal(t, "end", course.Waypoints[4].Type)
+	})
+
+	t.Run("claimed distance is corrected", func(t *testing.T) {
+		// 2km of waypoints claimed as 8km
+		course := squareCourse("walking", 0.5, 8)
+		warnings := ReviewCourseDetails(&course, walkingShort)
+
+		assert.Equal(t, []string{"course-1:" + WarnDistanceCorrected}, warningCodes(warnings))
+		assert.Equal(t, 2.5, course.Distance)
+	})
+
+	t.Run("routed distance is kept", func(t *testing.T) {
+		course := squareCourse("walking", 0.5, 8)
+		polyline := "encoded"
+		course.Polyline = &polyline
+
+		warnings := ReviewCourseDetails(&course, DistanceRange{})
+
+		assert.Empty(t, warnings)
+		assert.Equal(t, 8.0, course.Distance)
+	})
+
+	t.Run("distance outside the requested range", func(t *testing.T) {
+		course := squareCourse("cycling", 1.5, 7)
+		warnings := ReviewCourseDetails(&course, DistanceRange{MinKm: 10, MaxKm: 20})
+
+		assert.Equal(t, []string{"course-1:" + WarnDistanceOutsideRange}, warningCodes(warnings))
+	})
+
+	t.Run("long legs are flagged", func(t *testing.T) {
+		course := squareCourse("walking", 0.5, 6)
+		course.Waypoints[2].Position = offsetPosition(course.Waypoints[0].Position, 3, 3)
+
+		warnings := ReviewCourseDetails(&course, DistanceRange{})
+
+		assert.Equal(t, []string{"wp-3:" + WarnLegTooLong, "wp-4:" + WarnLegTooLong, "course-1:" + WarnDistanceCorrected}, warningCodes(warnings))
+	})
+
+	t.Run("loop ending away from the start", func(t *testing.T) {
+		course := squareCourse("walking", 0.5, 2.4)
+		course.RouteShape = RouteShapeLoop
+		assert.Empty(t, ReviewCourseDetails(&course, DistanceRange{}))
+
+		course.Waypoints[4].Position = course.Waypoints[2].Position
+		warnings := ReviewCourseDetails(&course, DistanceRange{})
+		assert.Equal(t, []string{"wp-5:" + WarnLoopNotClosed}, warningCodes(warnings))
+
+		course.RouteShape = RouteShapeOneWay
+		assert.Empty(t, ReviewCourseDetails(&course, DistanceRange{}), "one way courses end elsewhere")
+	})
+
+	t.Run("too few waypoints", func(t *testing.T) {
+		course := CourseDetails{ID: "course-1", Waypoints: []Waypoint{{ID: "wp-1", Type: "start"}}}
+		warnings := ReviewCourseDetails(&course, walkingShort)
+
+		assert.Equal(t, []string{"course-1:" + WarnTooFewWaypoints}, warningCodes(warnings))
+	})
+}
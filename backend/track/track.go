@@ -0,0 +1,208 @@
+// Package track reads recorded or planned routes from GPX and TCX files.
+package track
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	shared "potarin-shared"
+)
+
+// Errors returned by Parse
+var (
+	ErrUnsupportedFormat = errors.New("track: not a GPX or TCX document")
+	ErrNoPoints          = errors.New("track: no track points")
+)
+
+// Track is the line of a GPX or TCX file
+type Track struct {
+	// Name of the track, route or course; may be empty
+	Name string
+	// CourseType is derived from the activity or type of the track when
+	// it names one, otherwise empty
+	CourseType string
+	Points     []shared.Position
+}
+
+// Parse reads a GPX 1.0/1.1 or TCX document. GPX track segments are joined
+// in order; files without tracks use their first route. TCX courses and
+// activities are read the same way.
+func Parse(data []byte) (*Track, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var track *Track
+	switch root {
+	case "gpx":
+		track, err = parseGPX(data)
+	case "TrainingCenterDatabase":
+		track, err = parseTCX(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(track.Points) < 2 {
+		return nil, ErrNoPoints
+	}
+	return track, nil
+}
+
+// rootElement returns the local name of the document element
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return "", ErrUnsupportedFormat
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+type gpxPoint struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+}
+
+type gpxFile struct {
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	// GPX 1.0 has the name on the root element
+	Name   string `xml:"name"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Name   string     `xml:"name"`
+		Type   string     `xml:"type"`
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+func parseGPX(data []byte) (*Track, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("track: invalid GPX: %w", err)
+	}
+
+	track := &Track{Name: firstNonEmpty(file.Metadata.Name, file.Name)}
+	var points []gpxPoint
+	for _, trk := range file.Tracks {
+		for _, segment := range trk.Segments {
+			points = append(points, segment.Points...)
+		}
+		track.Name = firstNonEmpty(track.Name, trk.Name)
+		track.CourseType = firstNonEmpty(track.CourseType, courseTypeOf(trk.Type))
+	}
+	if len(points) == 0 && len(file.Routes) > 0 {
+		route := file.Routes[0]
+		points = route.Points
+		track.Name = firstNonEmpty(track.Name, route.Name)
+		track.CourseType = courseTypeOf(route.Type)
+	}
+
+	for _, point := range points {
+		if position, ok := validPosition(point.Latitude, point.Longitude); ok {
+			track.Points = append(track.Points, position)
+		}
+	}
+	return track, nil
+}
+
+type tcxTrackpoint struct {
+	// Pointers tell points without a position, e.g. indoors, from 0,0
+	Latitude  *float64 `xml:"Position>LatitudeDegrees"`
+	Longitude *float64 `xml:"Position>LongitudeDegrees"`
+}
+
+type tcxFile struct {
+	Courses []struct {
+		Name        string          `xml:"Name"`
+		Trackpoints []tcxTrackpoint `xml:"Track>Trackpoint"`
+	} `xml:"Courses>Course"`
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			Trackpoints []tcxTrackpoint `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+func parseTCX(data []byte) (*Track, error) {
+	var file tcxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("track: invalid TCX: %w", err)
+	}
+
+	track := &Track{}
+	var trackpoints []tcxTrackpoint
+	if len(file.Courses) > 0 {
+		track.Name = file.Courses[0].Name
+		trackpoints = file.Courses[0].Trackpoints
+	} else if len(file.Activities) > 0 {
+		activity := file.Activities[0]
+		track.CourseType = courseTypeOf(activity.Sport)
+		for _, lap := range activity.Laps {
+			trackpoints = append(trackpoints, lap.Trackpoints...)
+		}
+	}
+
+	for _, point := range trackpoints {
+		if point.Latitude == nil || point.Longitude == nil {
+			continue
+		}
+		if position, ok := validPosition(*point.Latitude, *point.Longitude); ok {
+			track.Points = append(track.Points, position)
+		}
+	}
+	return track, nil
+}
+
+// courseTypeOf maps GPX track types and TCX sports to course types
+func courseTypeOf(activity string) string {
+	switch strings.ToLower(strings.TrimSpace(activity)) {
+	case "biking", "cycling", "bike", "road_biking", "mountain_biking", "gravel_cycling":
+		return "cycling"
+	case "running", "run", "jogging", "trail_running":
+		return "jogging"
+	case "walking", "walk", "hiking", "hike":
+		return "walking"
+	}
+	return ""
+}
+
+func validPosition(latitude, longitude float64) (shared.Position, bool) {
+	if math.IsNaN(latitude) || math.IsNaN(longitude) ||
+		latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return shared.Position{}, false
+	}
+	return shared.Position{Latitude: latitude, Longitude: longitude}, true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
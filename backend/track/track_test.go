@@ -0,0 +1,101 @@
+package track
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "potarin-shared"
+)
+
+func TestParse_GPX(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <metadata><name>多摩川サイクリング</name></metadata>
+  <trk>
+    <name>朝ライド</name>
+    <type>cycling</type>
+    <trkseg>
+      <trkpt lat="35.6000" lon="139.6000"><ele>10</ele></trkpt>
+      <trkpt lat="35.6010" lon="139.6010"></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="35.6020" lon="139.6020"></trkpt>
+      <trkpt lat="135.6" lon="139.6020"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`)
+
+	track, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "多摩川サイクリング", track.Name)
+	assert.Equal(t, "cycling", track.CourseType)
+	assert.Equal(t, []shared.Position{
+		{Latitude: 35.6, Longitude: 139.6},
+		{Latitude: 35.601, Longitude: 139.601},
+		{Latitude: 35.602, Longitude: 139.602},
+	}, track.Points, "segments are joined and invalid points dropped")
+}
+
+func TestParse_GPXRoute(t *testing.T) {
+	data := []byte(`<gpx version="1.0"><name>皇居ラン</name>
+  <rte><name>route</name><rtept lat="35.68" lon="139.75"/><rtept lat="35.69" lon="139.76"/></rte>
+</gpx>`)
+
+	track, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "皇居ラン", track.Name)
+	assert.Empty(t, track.CourseType)
+	assert.Len(t, track.Points, 2)
+}
+
+func TestParse_TCX(t *testing.T) {
+	course := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Courses><Course><Name>Sunday Loop</Name><Track>
+    <Trackpoint><Position><LatitudeDegrees>35.1</LatitudeDegrees><LongitudeDegrees>139.1</LongitudeDegrees></Position></Trackpoint>
+    <Trackpoint><Position><LatitudeDegrees>35.2</LatitudeDegrees><LongitudeDegrees>139.2</LongitudeDegrees></Position></Trackpoint>
+  </Track></Course></Courses>
+</TrainingCenterDatabase>`)
+
+	track, err := Parse(course)
+	require.NoError(t, err)
+	assert.Equal(t, "Sunday Loop", track.Name)
+	assert.Len(t, track.Points, 2)
+
+	activity := []byte(`<TrainingCenterDatabase><Activities><Activity Sport="Running">
+  <Lap><Track>
+    <Trackpoint><Time>2025-01-01T00:00:00Z</Time></Trackpoint>
+    <Trackpoint><Position><LatitudeDegrees>35.1</LatitudeDegrees><LongitudeDegrees>139.1</LongitudeDegrees></Position></Trackpoint>
+  </Track></Lap>
+  <Lap><Track>
+    <Trackpoint><Position><LatitudeDegrees>35.2</LatitudeDegrees><LongitudeDegrees>139.2</LongitudeDegrees></Position></Trackpoint>
+  </Track></Lap>
+</Activity></Activities></TrainingCenterDatabase>`)
+
+	track, err = Parse(activity)
+	require.NoError(t, err)
+	assert.Equal(t, "jogging", track.CourseType)
+	assert.Equal(t, []shared.Position{{Latitude: 35.1, Longitude: 139.1}, {Latitude: 35.2, Longitude: 139.2}}, track.Points,
+		"points without a position are skipped")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"not XML", `{"type":"FeatureCollection"}`, ErrUnsupportedFormat},
+		{"KML", `<kml><Document/></kml>`, ErrUnsupportedFormat},
+		{"empty", ``, ErrUnsupportedFormat},
+		{"single point", `<gpx><trk><trkseg><trkpt lat="35" lon="139"/></trkseg></trk></gpx>`, ErrNoPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package usage accounts token usage and cost of LLM calls and enforces a
+// daily budget.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Day boundaries follow Japan time, where the service is used
+var dayLocation = time.FixedZone("JST", 9*60*60)
+
+// DayFormat is the layout of day keys in reports
+const DayFormat = "2006-01-02"
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// PriceTable maps model names to prices. Dated model snapshots such as
+// "gpt-4o-2024-08-06" fall back to the longest matching prefix.
+type PriceTable map[string]Price
+
+// DefaultPrices are the public list prices of commonly used OpenAI models
+var DefaultPrices = PriceTable{
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+}
+
+// ParsePriceTable parses "model=input/output" pairs separated by commas,
+// e.g. "gpt-4o=2.5/10,gpt-4o-mini=0.15/0.6", on top of DefaultPrices
+func ParsePriceTable(spec string) (PriceTable, error) {
+	table := PriceTable{}
+	for model, price := range DefaultPrices {
+		table[model] = price
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		model, prices, ok := strings.Cut(pair, "=")
+		input, output, ok2 := strings.Cut(prices, "/")
+		if !ok || !ok2 || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("invalid price %q (expected model=input/output)", pair)
+		}
+		inputPrice, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input price in %q: %w", pair, err)
+		}
+		outputPrice, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output price in %q: %w", pair, err)
+		}
+		table[strings.TrimSpace(model)] = Price{Input: inputPrice, Output: outputPrice}
+	}
+	return table, nil
+}
+
+// Lookup returns the price of model, matching the longest known prefix
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for known := range t {
+		if strings.HasPrefix(model, known+"-") && len(known) > len(best) {
+			best = known
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Cost returns the USD cost of the given token counts
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1_000_000
+}
+
+// Labels attribute usage to an endpoint and API client
+type Labels struct {
+	Endpoint string
+	Client   string
+}
+
+type labelsKey struct{}
+
+// WithLabels attaches usage labels to ctx
+func WithLabels(ctx context.Context, labels Labels) context.Context {
+	return context.WithValue(ctx, labelsKey{}, labels)
+}
+
+// LabelsFrom returns the labels attached to ctx, with "unknown" for missing values
+func LabelsFrom(ctx context.Context) Labels {
+	labels, _ := ctx.Value(labelsKey{}).(Labels)
+	if labels.Endpoint == "" {
+		labels.Endpoint = "unknown"
+	}
+	if labels.Client == "" {
+		labels.Client = "unknown"
+	}
+	return labels
+}
+
+// Entry is the aggregated usage of one day, endpoint, client and model
+type Entry struct {
+	Day              string
+	Endpoint         string
+	Client           string
+	Model            string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+type entryKey struct {
+	day, endpoint, client, model string
+}
+
+// Tracker aggregates usage in memory. Totals are lost on restart.
+type Tracker struct {
+	prices PriceTable
+	budget float64
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[entryKey]*Entry
+	daily   map[string]float64
+}
+
+// NewTracker creates a tracker with a daily budget in USD (zero for no budget)
+func NewTracker(prices PriceTable, dailyBudget float64) *Tracker {
+	return &Tracker{
+		prices:  prices,
+		budget:  dailyBudget,
+		now:     time.Now,
+		entries: make(map[entryKey]*Entry),
+		daily:   make(map[string]float64),
+	}
+}
+
+// Record adds the tokens of one completion, attributed to the labels in ctx
+func (t *Tracker) Record(ctx context.Context, model string, promptTokens, completionTokens int) {
+	labels := LabelsFrom(ctx)
+	day := t.Today()
+
+	var cost float64
+	if price, ok := t.prices.Lookup(model); ok {
+		cost = price.Cost(promptTokens, completionTokens)
+	}
+
+	key := entryKey{day: day, endpoint: labels.Endpoint, client: labels.Client, model: model}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.entries[key]
+	if !ok {
+		entry = &Entry{Day: day, Endpoint: labels.Endpoint, Client: labels.Client, Model: model}
+		t.entries[key] = entry
+	}
+	entry.Requests++
+	entry.PromptTokens += promptTokens
+	entry.CompletionTokens += completionTokens
+	entry.Cost += cost
+	t.daily[day] += cost
+}
+
+// Today returns the key of the current day
+func (t *Tracker) Today() string {
+	return t.now().In(dayLocation).Format(DayFormat)
+}
+
+// Budget returns the daily budget in USD, zero when unlimited
+func (t *Tracker) Budget() float64 {
+	return t.budget
+}
+
+// DailyCost returns the total cost of day
+func (t *Tracker) DailyCost(day string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.daily[day]
+}
+
+// BudgetExceeded reports whether today's cost has reached the daily budget
+func (t *Tracker) BudgetExceeded() bool {
+	return t.budget > 0 && t.DailyCost(t.Today()) >= t.budget
+}
+
+// UntilNextDay returns the time until the budget resets
+func (t *Tracker) UntilNextDay() time.Duration {
+	now := t.now().In(dayLocation)
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, dayLocation)
+	return midnight.Sub(now)
+}
+
+// Entries returns the usage of day sorted by endpoint, client and model
+func (t *Tracker) Entries(day string) []Entry {
+	t.mu.Lock()
+	entries := make([]Entry, 0)
+	for _, entry := range t.entries {
+		if entry.Day == day {
+			entries = append(entries, *entry)
+		}
+	}
+	t.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		return a.Model < b.Model
+	})
+	return entries
+}
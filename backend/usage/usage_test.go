@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceTable_Lookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		expected Price
+		found    bool
+	}{
+		{name: "exact", model: "gpt-4o-mini", expected: DefaultPrices["gpt-4o-mini"], found: true},
+		{name: "dated snapshot", model: "gpt-4o-2024-08-06", expected: DefaultPrices["gpt-4o"], found: true},
+		{name: "longest prefix wins", model: "gpt-4o-mini-2024-07-18", expected: DefaultPrices["gpt-4o-mini"], found: true},
+		{name: "unknown", model: "llama3.1", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, found := DefaultPrices.Lookup(tt.model)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, price)
+		})
+	}
+}
+
+func TestParsePriceTable(t *testing.T) {
+	table, err := ParsePriceTable("llama3.1=0/0, gpt-4o=5/15")
+	require.NoError(t, err)
+	assert.Equal(t, Price{Input: 5, Output: 15}, table["gpt-4o"])
+	assert.Equal(t, Price{}, table["llama3.1"])
+	assert.Equal(t, DefaultPrices["gpt-4o-mini"], table["gpt-4o-mini"])
+
+	for _, invalid := range []string{"gpt-4o", "gpt-4o=5", "=1/2", "gpt-4o=a/2", "gpt-4o=1/b"} {
+		_, err := ParsePriceTable(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTracker_RecordAndBudget(t *testing.T) {
+	tracker := NewTracker(PriceTable{"gpt-4o": {Input: 2.5, Output: 10}}, 0.02)
+	// 23:30 JST on January 1st
+	now := time.Date(2025, 1, 1, 14, 30, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	web := WithLabels(context.Background(), Labels{Endpoint: "suggestions", Client: "web"})
+	tracker.Record(web, "gpt-4o", 1000, 500)
+	tracker.Record(web, "gpt-4o", 1000, 500)
+	tracker.Record(context.Background(), "llama3.1", 100, 100)
+
+	assert.Equal(t, "2025-01-01", tracker.Today())
+	entries := tracker.Entries("2025-01-01")
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{
+		Day:              "2025-01-01",
+		Endpoint:         "suggestions",
+		Client:           "web",
+		Model:            "gpt-4o",
+		Requests:         2,
+		PromptTokens:     2000,
+		CompletionTokens: 1000,
+		Cost:             0.015,
+	}, entries[0])
+	assert.Equal(t, "unknown", entries[1].Endpoint)
+	assert.Zero(t, entries[1].Cost)
+
+	assert.False(t, tracker.BudgetExceeded())
+	tracker.Record(web, "gpt-4o", 1000, 500)
+	assert.True(t, tracker.BudgetExceeded())
+	assert.Equal(t, 30*time.Minute, tracker.UntilNextDay())
+
+	// The budget resets at midnight Japan time
+	now = now.Add(time.Hour)
+	assert.Equal(t, "2025-01-02", tracker.Today())
+	assert.False(t, tracker.BudgetExceeded())
+	assert.Empty(t, tracker.Entries("2025-01-02"))
+}
+
+func TestTracker_NoBudget(t *testing.T) {
+	tracker := NewTracker(DefaultPrices, 0)
+	tracker.Record(context.Background(), "gpt-4o", 10_000_000, 10_000_000)
+	assert.False(t, tracker.BudgetExceeded())
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -14,12 +15,22 @@ const (
 	InvalidInput    ErrorCode = "invalid_input"
 	MissingField    ErrorCode = "missing_field"
 	InvalidFormat   ErrorCode = "invalid_format"
+	NotFound        ErrorCode = "not_found"
 
 	// Business logic errors
 	ServiceUnavailable ErrorCode = "service_unavailable"
 	ExternalAPIError   ErrorCode = "external_api_error"
 	ProcessingError    ErrorCode = "processing_error"
 
+	// Upstream AI provider errors
+	RateLimited           ErrorCode = "rate_limited"
+	QuotaExceeded         ErrorCode = "quota_exceeded"
+	ContextLengthExceeded ErrorCode = "context_length_exceeded"
+	ContentRefused        ErrorCode = "content_refused"
+	UpstreamAuthError     ErrorCode = "upstream_auth_error"
+	UpstreamTimeout       ErrorCode = "upstream_timeout"
+	BudgetExceeded        ErrorCode = "budget_exceeded"
+
 	// System errors
 	InternalError ErrorCode = "internal_error"
 	DatabaseError ErrorCode = "database_error"
@@ -46,6 +57,8 @@ type AppError struct {
 	Details   []ErrorDetail `json:"details,omitempty"`
 	Timestamp time.Time     `json:"timestamp"`
 	RequestID string        `json:"request_id,omitempty"`
+	// RetryAfter is the number of seconds the client should wait before retrying
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
 // Error implements the error interface
@@ -82,6 +95,14 @@ func (e *AppError) WithRequestID(requestID string) *AppError {
 	return e
 }
 
+// WithRetryAfter sets the delay before the client should retry, rounded up to whole seconds
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	if d > 0 {
+		e.RetryAfter = int(math.Ceil(d.Seconds()))
+	}
+	return e
+}
+
 // Common error constructors with Japanese messages
 func NewValidationError(message string) *AppError {
 	if message == "" {
@@ -124,21 +145,38 @@ func NewProcessingError(message string) *AppError {
 	return NewAppError(ProcessingError, message)
 }
 
+// NewUpstreamError creates an error for a classified AI provider failure
+func NewUpstreamError(code ErrorCode, err error) *AppError {
+	appErr := NewAppError(code, GetErrorMessage(code))
+	if err != nil {
+		appErr = appErr.WithDetail("external_error", string(code), err.Error(), nil)
+	}
+	return appErr
+}
+
 // Error messages in Japanese
 var ErrorMessages = map[ErrorCode]string{
-	ValidationError:    "入力データが無効です",
-	InvalidInput:       "無効な入力です",
-	MissingField:       "必須フィールドが不足しています",
-	InvalidFormat:      "データ形式が正しくありません",
-	ServiceUnavailable: "サービスが一時的に利用できません",
-	ExternalAPIError:   "外部サービスでエラーが発生しました",
-	ProcessingError:    "処理中にエラーが発生しました",
-	InternalError:      "内部エラーが発生しました",
-	DatabaseError:      "データベースエラーが発生しました",
-	NetworkError:       "ネットワークエラーが発生しました",
-	Unauthorized:       "認証が必要です",
-	Forbidden:          "アクセスが拒否されました",
-	InvalidCredentials: "認証情報が無効です",
+	ValidationError:       "入力データが無効です",
+	InvalidInput:          "無効な入力です",
+	MissingField:          "必須フィールドが不足しています",
+	InvalidFormat:         "データ形式が正しくありません",
+	NotFound:              "指定されたデータが見つかりません",
+	ServiceUnavailable:    "サービスが一時的に利用できません",
+	ExternalAPIError:      "外部サービスでエラーが発生しました",
+	ProcessingError:       "処理中にエラーが発生しました",
+	RateLimited:           "リクエストが集中しています。しばらく時間をおいてから再度お試しください",
+	QuotaExceeded:         "AIサービスの利用上限に達しました。時間をおいてから再度お試しください",
+	ContextLengthExceeded: "リクエスト内容が長すぎるため処理できませんでした",
+	ContentRefused:        "この内容ではコースを生成できませんでした。条件を変えてお試しください",
+	UpstreamAuthError:     "AIサービスの認証設定に問題があります",
+	UpstreamTimeout:       "AIサービスの応答がタイムアウトしました",
+	BudgetExceeded:        "本日のAI利用予算の上限に達したため、新しいコースの提案を停止しています。明日以降に再度お試しください",
+	InternalError:         "内部エラーが発生しました",
+	DatabaseError:         "データベースエラーが発生しました",
+	NetworkError:          "ネットワークエラーが発生しました",
+	Unauthorized:          "認証が必要です",
+	Forbidden:             "アクセスが拒否されました",
+	InvalidCredentials:    "認証情報が無効です",
 }
 
 // GetErrorMessage returns a Japanese error message for the given code
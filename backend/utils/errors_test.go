@@ -312,9 +312,17 @@ func TestErrorMessages(t *testing.T) {
 		InvalidInput,
 		MissingField,
 		InvalidFormat,
+		NotFound,
 		ServiceUnavailable,
 		ExternalAPIError,
 		ProcessingError,
+		RateLimited,
+		QuotaExceeded,
+		ContextLengthExceeded,
+		ContentRefused,
+		UpstreamAuthError,
+		UpstreamTimeout,
+		BudgetExceeded,
 		InternalError,
 		DatabaseError,
 		NetworkError,
@@ -348,3 +356,22 @@ func TestAppError_Chaining(t *testing.T) {
 	assert.Equal(t, "field1", err.Details[0].Field)
 	assert.Equal(t, "field2", err.Details[1].Field)
 }
+
+func TestAppError_WithRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		expected int
+	}{
+		{name: "whole seconds", delay: 3 * time.Second, expected: 3},
+		{name: "rounds up", delay: 1200 * time.Millisecond, expected: 2},
+		{name: "zero is ignored", delay: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewUpstreamError(RateLimited, nil).WithRetryAfter(tt.delay)
+			assert.Equal(t, tt.expected, err.RetryAfter)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -67,6 +68,10 @@ func SendError(c *fiber.Ctx, err *AppError) error {
 	// Determine HTTP status code based on error type
 	statusCode := getHTTPStatusCode(err.Code)
 
+	if err.RetryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(err.RetryAfter))
+	}
+
 	return c.Status(statusCode).JSON(response)
 }
 
@@ -99,10 +104,18 @@ func getHTTPStatusCode(code ErrorCode) int {
 		return fiber.StatusUnauthorized
 	case Forbidden:
 		return fiber.StatusForbidden
-	case ServiceUnavailable:
+	case NotFound:
+		return fiber.StatusNotFound
+	case RateLimited:
+		return fiber.StatusTooManyRequests
+	case ContextLengthExceeded, ContentRefused:
+		return fiber.StatusUnprocessableEntity
+	case ServiceUnavailable, QuotaExceeded, BudgetExceeded:
 		return fiber.StatusServiceUnavailable
-	case ExternalAPIError, NetworkError:
+	case ExternalAPIError, NetworkError, UpstreamAuthError:
 		return fiber.StatusBadGateway
+	case UpstreamTimeout:
+		return fiber.StatusGatewayTimeout
 	case DatabaseError, InternalError, ProcessingError:
 		return fiber.StatusInternalServerError
 	default:
@@ -164,6 +164,46 @@ func TestGetHTTPStatusCode(t *testing.T) {
 			code:         NetworkError,
 			expectedCode: fiber.StatusBadGateway,
 		},
+		{
+			name:         "rate limited",
+			code:         RateLimited,
+			expectedCode: fiber.StatusTooManyRequests,
+		},
+		{
+			name:         "quota exceeded",
+			code:         QuotaExceeded,
+			expectedCode: fiber.StatusServiceUnavailable,
+		},
+		{
+			name:         "budget exceeded",
+			code:         BudgetExceeded,
+			expectedCode: fiber.StatusServiceUnavailable,
+		},
+		{
+			name:         "context length exceeded",
+			code:         ContextLengthExceeded,
+			expectedCode: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:         "content refused",
+			code:         ContentRefused,
+			expectedCode: fiber.StatusUnprocessableEntity,
+		},
+		{
+			name:         "upstream auth error",
+			code:         UpstreamAuthError,
+			expectedCode: fiber.StatusBadGateway,
+		},
+		{
+			name:         "not found",
+			code:         NotFound,
+			expectedCode: fiber.StatusNotFound,
+		},
+		{
+			name:         "upstream timeout",
+			code:         UpstreamTimeout,
+			expectedCode: fiber.StatusGatewayTimeout,
+		},
 		{
 			name:         "database error",
 			code:         DatabaseError,
@@ -357,6 +397,11 @@ func TestIntegrationAPIResponse(t *testing.T) {
 		return SendServiceUnavailableError(c, "OpenAI")
 	})
 
+	// Rate limited endpoint
+	app.Get("/rate-limited", func(c *fiber.Ctx) error {
+		return SendError(c, NewUpstreamError(RateLimited, nil).WithRetryAfter(1500*time.Millisecond))
+	})
+
 	t.Run("success endpoint", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/success", nil)
 		resp, err := app.Test(req)
@@ -461,6 +506,27 @@ func TestIntegrationAPIResponse(t *testing.T) {
 		assert.NotNil(t, response.Error)
 		assert.Equal(t, ServiceUnavailable, response.Error.Code)
 		assert.Contains(t, response.Error.Message, "OpenAI")
+		assert.Empty(t, resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("rate limited endpoint", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/rate-limited", nil)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var response APIResponse
+		err = json.Unmarshal(body, &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, RateLimited, response.Error.Code)
+		assert.Equal(t, 2, response.Error.RetryAfter)
 	})
 }
 
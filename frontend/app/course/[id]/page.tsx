@@ -194,7 +194,7 @@ export default function CourseDetailPage({ params, searchParams }: CourseDetailP
               <div className="mb-8">
                 <h3 className="text-xl font-semibold text-gray-800 mb-4">ルートマップ</h3>
                 <div className="h-96 w-full rounded-lg overflow-hidden shadow-lg">
-                  <CourseMap waypoints={course.waypoints} className="h-full w-full" />
+                  <CourseMap waypoints={course.waypoints} polyline={course.polyline} className="h-full w-full" />
                 </div>
               </div>
 
@@ -5,9 +5,11 @@ import dynamic from 'next/dynamic';
 import { Waypoint } from '../../shared/types';
 import ComponentErrorBoundary from './ComponentErrorBoundary';
 import { useErrorHandler } from './ErrorBoundary';
+import { decodePolyline } from '../lib/utils';
 
 interface CourseMapProps {
   waypoints: Waypoint[];
+  polyline?: string;
   className?: string;
 }
 
@@ -54,7 +56,7 @@ const getTypeLabel = (type: string): string => {
   }
 };
 
-function MapComponent({ waypoints, className = '' }: CourseMapProps) {
+function MapComponent({ waypoints, polyline, className = '' }: CourseMapProps) {
   const mapRef = useRef<HTMLDivElement>(null);
   const [isLoaded, setIsLoaded] = useState(false);
   const mapInstanceRef = useRef<any>(null);
@@ -207,9 +209,10 @@ function MapComponent({ waypoints, className = '' }: CourseMapProps) {
           markers.push(marker);
         });
 
-        // Add route polyline
-        if (pathCoords.length > 1) {
-          L.polyline(pathCoords, {
+        // Add route polyline, following the routed geometry when available
+        const routeCoords = polyline ? decodePolyline(polyline) : pathCoords;
+        if (routeCoords.length > 1) {
+          L.polyline(routeCoords, {
             color: '#3b82f6',
             weight: 4,
             opacity: 0.7
@@ -261,7 +264,7 @@ function MapComponent({ waypoints, className = '' }: CourseMapProps) {
       
       setIsLoaded(false);
     };
-  }, [waypoints, reportError]);
+  }, [waypoints, polyline, reportError]);
 
   if (waypoints.length === 0) {
     return (
@@ -300,13 +303,13 @@ function MapComponent({ waypoints, className = '' }: CourseMapProps) {
   );
 }
 
-export default function CourseMap({ waypoints, className = '' }: CourseMapProps) {
+export default function CourseMap({ waypoints, polyline, className = '' }: CourseMapProps) {
   return (
     <ComponentErrorBoundary 
       componentName="地図コンポーネント"
       level="section"
     >
-      <DynamicMap waypoints={waypoints} className={className} />
+      <DynamicMap waypoints={waypoints} polyline={polyline} className={className} />
     </ComponentErrorBoundary>
   );
 }
\ No newline at end of file
@@ -1,4 +1,4 @@
-import { useState, useCallback } from 'react';
+import { useState, useCallback, useRef } from 'react';
 import { api, ApiError } from '../lib/api';
 import type {
   CourseRequest,
@@ -39,6 +39,10 @@ export function useCourses(): UseCourses {
     lastRequestId: null,
   });
 
+  // Distance bucket of the last suggestions request, sent with details
+  // requests so the backend can check the course length
+  const lastDistance = useRef<CourseRequest['distance'] | undefined>(undefined);
+
   // Get course suggestions from API
   const getSuggestions = useCallback(async (request: CourseRequest) => {
     setState(prev => ({
@@ -47,6 +51,7 @@ export function useCourses(): UseCourses {
       error: null,
       suggestions: [],
     }));
+    lastDistance.current = request.distance;
 
     try {
       const response: SuggestionsResponse = await api.getSuggestions({ request });
@@ -84,6 +89,7 @@ export function useCourses(): UseCourses {
       const response: DetailsResponse = await api.getDetails({
         courseId: suggestion.id,
         suggestion,
+        requestedDistance: lastDistance.current,
       });
       
       setState(prev => ({
@@ -1,6 +1,8 @@
 import type {
+  CourseSuggestion,
   SuggestionsRequest,
   SuggestionsResponse,
+  SuggestionsStreamComplete,
   DetailsRequest,
   DetailsResponse,
   HealthResponse,
@@ -20,6 +22,9 @@ const RETRY_CONFIG = {
   '/api/v1/details': { retries: 3, delay: 2000 },
 } as const;
 
+// Error codes the backend returns for failures that retrying cannot fix
+const NON_RETRYABLE_ERROR_CODES = ['quota_exceeded', 'upstream_auth_error', 'budget_exceeded'];
+
 export class ApiError extends Error {
   constructor(
     message: string,
@@ -27,7 +32,8 @@ export class ApiError extends Error {
     public errorCode?: string,
     public details?: any,
     public isRetryable: boolean = false,
-    public requestId?: string
+    public requestId?: string,
+    public retryAfter?: number // seconds, from the Retry-After header
   ) {
     super(message);
     this.name = 'ApiError';
@@ -77,6 +83,22 @@ function delay(ms: number): Promise<void> {
   return new Promise(resolve => setTimeout(resolve, ms));
 }
 
+// Parse a Retry-After header given in seconds or as an HTTP date
+function parseRetryAfter(value: string | null): number | undefined {
+  if (!value) {
+    return undefined;
+  }
+  const seconds = Number(value);
+  if (!Number.isNaN(seconds)) {
+    return Math.max(0, seconds);
+  }
+  const date = Date.parse(value);
+  if (!Number.isNaN(date)) {
+    return Math.max(0, (date - Date.now()) / 1000);
+  }
+  return undefined;
+}
+
 // Enhanced API request function with retry logic and timeout handling
 async function apiRequest<TResponse>(
   endpoint: string,
@@ -127,39 +149,33 @@ async function apiRequest<TResponse>(
       const response = await Promise.race([fetchPromise, timeoutPromise]);
       
       if (!response.ok) {
-        let errorData: ApiErrorType | undefined;
+        let errorData: (ApiErrorType & { request_id?: string }) | undefined;
         let responseText = '';
         
         try {
           responseText = await response.text();
-          errorData = JSON.parse(responseText);
+          const parsed = JSON.parse(responseText);
+          // The backend wraps errors as { success: false, error: {...} }
+          errorData = parsed?.error && typeof parsed.error === 'object' ? parsed.error : parsed;
         } catch {
           // If parsing fails, use the raw text or create a generic error
         }
 
-        const isRetryable = response.status >= 500 || 
+        const isRetryable = (response.status >= 500 || 
                            response.status === 429 || 
-                           response.status === 408;
+                           response.status === 408) &&
+                           !NON_RETRYABLE_ERROR_CODES.includes(errorData?.error ?? '');
 
-        const apiError = new ApiError(
+        // Throw so the catch block below decides whether and when to retry
+        throw new ApiError(
           errorData?.message || `HTTP ${response.status}: ${response.statusText}`,
           response.status,
           errorData?.error,
           errorData?.details,
           isRetryable,
-          errorData?.code
+          errorData?.request_id,
+          parseRetryAfter(response.headers.get('Retry-After'))
         );
-
-        // Don't retry client errors (4xx except 408 and 429)
-        if (!isRetryable && attempt < retries) {
-          throw apiError;
-        }
-
-        lastError = apiError;
-        
-        if (attempt === retries) {
-          throw apiError;
-        }
       } else {
         // Success - parse and return response
         const responseText = await response.text();
@@ -207,8 +223,11 @@ async function apiRequest<TResponse>(
         );
       }
 
-      // Wait before retrying (exponential backoff)
-      const delayMs = retryDelay * Math.pow(2, attempt);
+      // Wait before retrying: honour the server's Retry-After, otherwise back off exponentially
+      const retryAfter = lastError instanceof ApiError ? lastError.retryAfter : undefined;
+      const delayMs = retryAfter !== undefined
+        ? retryAfter * 1000
+        : retryDelay * Math.pow(2, attempt);
       console.log(`[API] ${method} ${endpoint} - Retrying in ${delayMs}ms...`);
       await delay(delayMs);
     }
@@ -311,6 +330,85 @@ export class PotarinApiClient {
     );
   }
 
+  // Stream course suggestions as they are generated (Server-Sent Events).
+  // onSuggestion is called for each suggestion; resolves with the completion event.
+  static async streamSuggestions(
+    request: SuggestionsRequest,
+    onSuggestion: (suggestion: CourseSuggestion) => void,
+    options?: { signal?: AbortSignal }
+  ): Promise<SuggestionsStreamComplete> {
+    const response = await fetch(`${API_BASE_URL}/api/v1/suggestions/stream`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        'Accept': 'text/event-stream',
+        'X-Client-Version': '2.0.0',
+      },
+      body: JSON.stringify(request),
+      signal: options?.signal,
+    });
+
+    if (!response.ok || !response.body) {
+      let message = `HTTP ${response.status}: ${response.statusText}`;
+      let errorCode: string | undefined;
+      try {
+        const parsed = await response.json();
+        message = parsed?.error?.message ?? message;
+        errorCode = parsed?.error?.error;
+      } catch {
+        // Keep the generic message
+      }
+      throw new ApiError(message, response.status, errorCode);
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+
+    while (true) {
+      const { done, value } = await reader.read();
+      if (done) {
+        break;
+      }
+      buffer += decoder.decode(value, { stream: true });
+
+      let separator: number;
+      while ((separator = buffer.indexOf('\n\n')) !== -1) {
+        const block = buffer.slice(0, separator);
+        buffer = buffer.slice(separator + 2);
+
+        let event = 'message';
+        let data = '';
+        for (const line of block.split('\n')) {
+          if (line.startsWith('event: ')) {
+            event = line.slice('event: '.length);
+          } else if (line.startsWith('data: ')) {
+            data += line.slice('data: '.length);
+          }
+        }
+
+        if (event === 'suggestion') {
+          onSuggestion(JSON.parse(data) as CourseSuggestion);
+        } else if (event === 'done') {
+          return JSON.parse(data) as SuggestionsStreamComplete;
+        } else if (event === 'error') {
+          const error = JSON.parse(data);
+          throw new ApiError(
+            error?.message ?? 'コース提案の生成中にエラーが発生しました',
+            502,
+            error?.error,
+            error?.details,
+            false,
+            error?.request_id,
+            error?.retry_after
+          );
+        }
+      }
+    }
+
+    throw new NetworkError('ストリームが途中で終了しました');
+  }
+
   // Utility method to check API connectivity
   static async isHealthy(): Promise<boolean> {
     try {
@@ -335,6 +433,7 @@ export class PotarinApiClient {
 export const api = {
   health: PotarinApiClient.health,
   getSuggestions: PotarinApiClient.getSuggestions,
+  streamSuggestions: PotarinApiClient.streamSuggestions,
   getDetails: PotarinApiClient.getDetails,
   isHealthy: PotarinApiClient.isHealthy,
   getCircuitBreakerStatus: PotarinApiClient.getCircuitBreakerStatus,
@@ -156,4 +156,34 @@ export function formatTime(date: Date | string): string {
  */
 export function clamp(value: number, min: number, max: number): number {
   return Math.min(Math.max(value, min), max);
-}
\ No newline at end of file
+}
+/**
+ * Decode a Google encoded polyline into [latitude, longitude] pairs.
+ * Course routes from the backend use precision 6.
+ */
+export function decodePolyline(encoded: string, precision = 6): [number, number][] {
+  const factor = Math.pow(10, precision);
+  const coordinates: [number, number][] = [];
+  let index = 0;
+  let lat = 0;
+  let lng = 0;
+
+  const nextValue = (): number => {
+    let result = 0;
+    let shift = 0;
+    let byte: number;
+    do {
+      byte = encoded.charCodeAt(index++) - 63;
+      result |= (byte & 0x1f) << shift;
+      shift += 5;
+    } while (byte >= 0x20 && index < encoded.length);
+    return result & 1 ? ~(result >> 1) : result >> 1;
+  };
+
+  while (index < encoded.length) {
+    lat += nextValue();
+    lng += nextValue();
+    coordinates.push([lat / factor, lng / factor]);
+  }
+  return coordinates;
+}
@@ -5,7 +5,9 @@ export const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost
 export const API_ENDPOINTS = {
   HEALTH: '/api/v1/health',
   SUGGESTIONS: '/api/v1/suggestions',
+  SUGGESTIONS_STREAM: '/api/v1/suggestions/stream',
   DETAILS: '/api/v1/details',
+  ADMIN_USAGE: '/api/v1/admin/usage',
 } as const;
 
 export const COURSE_TYPES = {
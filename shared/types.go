@@ -11,9 +11,25 @@ type Position struct {
 // CourseRequest represents the user's course request
 type CourseRequest struct {
 	CourseType  string            `json:"courseType" validate:"required,oneof=walking cycling jogging"`
-	Distance    string            `json:"distance" validate:"required,oneof=short medium long"`
+	Distance    string            `json:"distance,omitempty" validate:"omitempty,oneof=short medium long"`
 	Location    *Position         `json:"location,omitempty"`
 	Preferences *CoursePreferences `json:"preferences,omitempty"`
+	// PaceMinPerKm is the user's own pace on flat ground, overriding the pace model
+	PaceMinPerKm *float64 `json:"paceMinPerKm,omitempty" validate:"omitempty,gt=0,lte=60"`
+	// RouteShape is loop, one_way or out_and_back; any shape when empty
+	RouteShape string `json:"routeShape,omitempty" validate:"omitempty,oneof=loop one_way out_and_back"`
+	// EndLocation is where a one_way course should end
+	EndLocation *Position `json:"endLocation,omitempty"`
+	// DistanceKm bounds the course length, overriding the range of Distance
+	DistanceKm *DistanceRange `json:"distanceKm,omitempty"`
+	// TimeBudgetMinutes caps the distance at how far the user gets in that time
+	TimeBudgetMinutes *int `json:"timeBudgetMinutes,omitempty" validate:"omitempty,gt=0,lte=1440"`
+}
+
+// DistanceRange bounds a course length in km; an omitted bound is open
+type DistanceRange struct {
+	Min float64 `json:"min,omitempty" validate:"gte=0"`
+	Max float64 `json:"max,omitempty" validate:"gte=0"`
 }
 
 // CoursePreferences represents optional user preferences
@@ -30,6 +46,11 @@ type Waypoint struct {
 	Description string   `json:"description" validate:"required"`
 	Position    Position `json:"position" validate:"required"`
 	Type        string   `json:"type" validate:"required,oneof=start checkpoint landmark end"`
+	// Verified is set when the position is the one of a known POI
+	Verified bool `json:"verified"`
+	// MatchConfidence of the best POI candidate from 0 to 1, absent when
+	// waypoints are not matched against POIs
+	MatchConfidence *float64 `json:"matchConfidence,omitempty"`
 }
 
 // CourseSuggestion represents a suggested course
@@ -44,6 +65,11 @@ type CourseSuggestion struct {
 	StartPoint    Position `json:"startPoint" validate:"required"`
 	Highlights    []string `json:"highlights" validate:"required"`
 	Summary       string   `json:"summary" validate:"required"`
+	RouteShape    string   `json:"routeShape,omitempty" validate:"omitempty,oneof=loop one_way out_and_back"`
+	// EndPoint is where the course ends, the start point unless one_way
+	EndPoint *Position `json:"endPoint,omitempty"`
+	// ModelEstimatedTime is the model's own estimate, kept for comparison
+	ModelEstimatedTime *int `json:"modelEstimatedTime,omitempty"`
 }
 
 // ElevationProfile represents elevation data for a course
@@ -68,8 +94,11 @@ type CourseDetails struct {
 	Difficulty    string      `json:"difficulty" validate:"required,oneof=easy moderate hard"`
 	CourseType    string      `json:"courseType" validate:"required,oneof=walking cycling jogging"`
 	Waypoints     []Waypoint  `json:"waypoints" validate:"required"`
+	RouteShape    string      `json:"routeShape,omitempty"`
 	Polyline      *string     `json:"polyline,omitempty"`
 	Elevation     *Elevation  `json:"elevation,omitempty"`
+	// ModelEstimatedTime is the model's own estimate, kept for comparison
+	ModelEstimatedTime *int `json:"modelEstimatedTime,omitempty"`
 }
 
 // API Request/Response types
@@ -84,19 +113,51 @@ type SuggestionsResponse struct {
 	Suggestions []CourseSuggestion `json:"suggestions" validate:"required"`
 	RequestID   string             `json:"requestId" validate:"required"`
 	GeneratedAt time.Time          `json:"generatedAt" validate:"required"`
+	Warnings    []Warning          `json:"warnings,omitempty"`
+	Metadata    *ResponseMetadata  `json:"metadata,omitempty"`
+}
+
+// Warning reports a generated item that was removed or corrected
+type Warning struct {
+	ID      string `json:"id,omitempty"`
+	Code    string `json:"code" validate:"required"`
+	Message string `json:"message" validate:"required"`
+}
+
+// ResponseMetadata describes how a generated response was produced
+type ResponseMetadata struct {
+	CacheHit      bool       `json:"cacheHit"`
+	CachedAt      *time.Time `json:"cachedAt,omitempty"`
+	PromptVersion *string    `json:"promptVersion,omitempty"`
+}
+
+// SuggestionsStreamComplete is the final event of a streamed suggestions response
+type SuggestionsStreamComplete struct {
+	RequestID   string    `json:"requestId" validate:"required"`
+	GeneratedAt time.Time `json:"generatedAt" validate:"required"`
+	Count       int       `json:"count"`
 }
 
 // DetailsRequest represents a request for course details
 type DetailsRequest struct {
 	CourseID   string           `json:"courseId" validate:"required"`
 	Suggestion CourseSuggestion `json:"suggestion" validate:"required"`
+	// RequestedDistance is the distance bucket of the original request, if known
+	RequestedDistance *string `json:"requestedDistance,omitempty" validate:"omitempty,oneof=short medium long"`
+	// DistanceKm and TimeBudgetMinutes of the original request, if any
+	DistanceKm        *DistanceRange `json:"distanceKm,omitempty"`
+	TimeBudgetMinutes *int           `json:"timeBudgetMinutes,omitempty" validate:"omitempty,gt=0,lte=1440"`
+	// PaceMinPerKm is the user's own pace on flat ground, overriding the pace model
+	PaceMinPerKm *float64 `json:"paceMinPerKm,omitempty" validate:"omitempty,gt=0,lte=60"`
 }
 
 // DetailsResponse represents the response with course details
 type DetailsResponse struct {
-	Course      CourseDetails `json:"course" validate:"required"`
-	RequestID   string        `json:"requestId" validate:"required"`
-	GeneratedAt time.Time     `json:"generatedAt" validate:"required"`
+	Course      CourseDetails     `json:"course" validate:"required"`
+	RequestID   string            `json:"requestId" validate:"required"`
+	GeneratedAt time.Time         `json:"generatedAt" validate:"required"`
+	Warnings    []Warning         `json:"warnings,omitempty"`
+	Metadata    *ResponseMetadata `json:"metadata,omitempty"`
 }
 
 // ApiError represents an API error response
@@ -107,6 +168,26 @@ type ApiError struct {
 	Details interface{} `json:"details,omitempty"`
 }
 
+// UsageReport represents the token usage and cost of one day
+type UsageReport struct {
+	Day            string       `json:"day" validate:"required"`
+	TotalCostUSD   float64      `json:"totalCostUsd"`
+	DailyBudgetUSD *float64     `json:"dailyBudgetUsd,omitempty"`
+	BudgetExceeded bool         `json:"budgetExceeded"`
+	Entries        []UsageEntry `json:"entries" validate:"required"`
+}
+
+// UsageEntry is the usage of one endpoint, API client and model
+type UsageEntry struct {
+	Endpoint         string  `json:"endpoint" validate:"required"`
+	Client           string  `json:"client" validate:"required"`
+	Model            string  `json:"model" validate:"required"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	CostUSD          float64 `json:"costUsd"`
+}
+
 // HealthResponse represents a health check response
 type HealthResponse struct {
 	Status    string     `json:"status" validate:"required,oneof=ok error"`
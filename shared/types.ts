@@ -2,7 +2,9 @@
 
 export interface CourseRequest {
   courseType: 'walking' | 'cycling' | 'jogging';
-  distance: 'short' | 'medium' | 'long'; // 1-3km, 3-10km, 10km+
+  // Range depends on the course type, e.g. 1-3km walking or 5-15km cycling for short;
+  // required unless distanceKm or timeBudgetMinutes is given
+  distance?: 'short' | 'medium' | 'long';
   location?: {
     latitude: number;
     longitude: number;
@@ -12,8 +14,22 @@ export interface CourseRequest {
     difficulty?: 'easy' | 'moderate' | 'hard';
     avoidHills?: boolean;
   };
+  paceMinPerKm?: number; // user's own pace on flat ground, overrides the pace model
+  routeShape?: RouteShape; // any shape when omitted
+  endLocation?: Position; // only for one_way courses
+  distanceKm?: DistanceRange; // overrides the range of distance
+  timeBudgetMinutes?: number; // caps the distance at how far the user gets in that time
 }
 
+// Course length in km; an omitted bound is open
+export interface DistanceRange {
+  min?: number;
+  max?: number;
+}
+
+// loop returns to the start by another way, out_and_back by the same way
+export type RouteShape = 'loop' | 'one_way' | 'out_and_back';
+
 export interface Position {
   latitude: number;
   longitude: number;
@@ -54,6 +70,8 @@ export interface Waypoint {
   description: string;
   position: Position;
   type: 'start' | 'checkpoint' | 'landmark' | 'end';
+  verified: boolean; // position is the one of a known POI
+  matchConfidence?: number; // 0-1, absent when waypoints are not matched against POIs
 }
 
 export interface CourseSuggestion {
@@ -67,6 +85,9 @@ export interface CourseSuggestion {
   startPoint: Position;
   highlights: string[];
   summary: string;
+  routeShape?: RouteShape;
+  endPoint?: Position;
+  modelEstimatedTime?: number; // the model's own estimate in minutes, for comparison
 }
 
 export interface CourseDetails {
@@ -78,11 +99,13 @@ export interface CourseDetails {
   difficulty: 'easy' | 'moderate' | 'hard';
   courseType: 'walking' | 'cycling' | 'jogging';
   waypoints: Waypoint[];
+  routeShape?: RouteShape;
   polyline?: string; // encoded polyline for route visualization
   elevation?: {
     gain: number; // total elevation gain in meters
     profile: Array<{ distance: number; elevation: number }>;
   };
+  modelEstimatedTime?: number; // the model's own estimate in minutes, for comparison
 }
 
 // API Request/Response types
@@ -94,17 +117,50 @@ export interface SuggestionsResponse {
   suggestions: CourseSuggestion[];
   requestId: string;
   generatedAt: string;
+  warnings?: Warning[];
+  metadata?: ResponseMetadata;
+}
+
+// A generated item that was removed or corrected
+export interface Warning {
+  id?: string;
+  code: string;
+  message: string;
+}
+
+// How a generated response was produced
+export interface ResponseMetadata {
+  cacheHit: boolean;
+  cachedAt?: string;
+  // Version of the prompt templates that generated the response
+  promptVersion?: string;
+}
+
+// Events of GET/POST /api/v1/suggestions/stream (Server-Sent Events):
+// "suggestion" carries a CourseSuggestion, "done" the completion below and
+// "error" an error payload
+export interface SuggestionsStreamComplete {
+  requestId: string;
+  generatedAt: string;
+  count: number;
 }
 
 export interface DetailsRequest {
   courseId: string;
   suggestion: CourseSuggestion;
+  // Distance bucket of the original request, used to check the course length
+  requestedDistance?: 'short' | 'medium' | 'long';
+  distanceKm?: DistanceRange;
+  timeBudgetMinutes?: number;
+  paceMinPerKm?: number;
 }
 
 export interface DetailsResponse {
   course: CourseDetails;
   requestId: string;
   generatedAt: string;
+  warnings?: Warning[];
+  metadata?: ResponseMetadata;
 }
 
 // Error types
@@ -115,6 +171,25 @@ export interface ApiError {
   details?: any;
 }
 
+// Admin usage report (GET /api/v1/admin/usage)
+export interface UsageReport {
+  day: string;
+  totalCostUsd: number;
+  dailyBudgetUsd?: number;
+  budgetExceeded: boolean;
+  entries: UsageEntry[];
+}
+
+export interface UsageEntry {
+  endpoint: string;
+  client: string;
+  model: string;
+  requests: number;
+  promptTokens: number;
+  completionTokens: number;
+  costUsd: number;
+}
+
 // Health check response
 export interface HealthResponse {
   status: 'ok' | 'error';